@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"sort"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
@@ -12,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // handlers represents the handlers command which lists handlers
@@ -19,6 +22,10 @@ import (
 // usage
 // axon handlers list
 // axon handlers history <handler-name>
+// axon handlers pause <handler-name>
+// axon handlers resume <handler-name>
+// axon handlers trigger <handler-name>
+// axon handlers cancel <invocation-id>
 
 var handlersRootCmd = &cobra.Command{
 	Use:   "handlers",
@@ -44,6 +51,26 @@ var handlersList = &cobra.Command{
 				fmt.Printf("  Dispatch ID: %s\n", handler.DispatchId)
 				fmt.Printf("  Options: %v\n", handler.Options)
 				fmt.Printf("  Active: %v\n", handler.IsActive)
+				if handler.IsPaused {
+					fmt.Printf("  Paused: %v\n", handler.IsPaused)
+				}
+				if handler.IntervalMs > 0 {
+					fmt.Printf("  Interval: %v\n", time.Duration(handler.IntervalMs)*time.Millisecond)
+				}
+				if handler.NextRunTimestamp != nil {
+					fmt.Printf("  Next Run: %s\n", util.TimeToString(handler.NextRunTimestamp.AsTime()))
+				}
+				switch handler.LastResult {
+				case pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS:
+					fmt.Printf("  Last Result: success (%dms)\n", handler.LastDurationMs)
+				case pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR:
+					fmt.Printf("  Last Result: error (%dms): %s\n", handler.LastDurationMs, handler.LastError.GetMessage())
+					atLeast := ""
+					if handler.ConsecutiveFailuresAtLeast {
+						atLeast = "≥"
+					}
+					fmt.Printf("  Consecutive Failures: %s%d\n", atLeast, handler.ConsecutiveFailures)
+				}
 				if handler.LastInvokedClientTimestamp != nil {
 					fmt.Printf("  Last Invoked: %s\n\n", util.TimeToString(handler.LastInvokedClientTimestamp.AsTime()))
 
@@ -71,17 +98,50 @@ var handlersHistoryCmd = &cobra.Command{
 			logs = true
 		}
 
+		showArgs, _ := cmd.Flags().GetBool("args")
 		tail, _ := cmd.Flags().GetInt("tail")
 
 		if tail < 0 {
 			log.Fatalf("tail must be a positive integer")
 		}
 
-		history, err := client.GetHandlerHistory(cmd.Context(), &pb.GetHandlerHistoryRequest{
+		req := &pb.GetHandlerHistoryRequest{
 			HandlerName: handlerName,
 			IncludeLogs: logs,
 			Tail:        int32(tail),
-		})
+		}
+		req.Cursor, _ = cmd.Flags().GetString("cursor")
+		req.DispatchId, _ = cmd.Flags().GetString("dispatch-id")
+		req.InvocationId, _ = cmd.Flags().GetString("invocation-id")
+
+		now := time.Now()
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			t, err := util.TimeOrAgoFromString(since, now)
+			if err != nil {
+				log.Fatalf("invalid since: %v", err)
+			}
+			req.StartTime = timestamppb.New(t)
+		}
+		if until, _ := cmd.Flags().GetString("until"); until != "" {
+			t, err := util.TimeOrAgoFromString(until, now)
+			if err != nil {
+				log.Fatalf("invalid until: %v", err)
+			}
+			req.EndTime = timestamppb.New(t)
+		}
+
+		errorsOnly, _ := cmd.Flags().GetBool("errors")
+		successOnly, _ := cmd.Flags().GetBool("success")
+		switch {
+		case errorsOnly && successOnly:
+			log.Fatalf("only one of errors and success can be set")
+		case errorsOnly:
+			req.Result = pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR
+		case successOnly:
+			req.Result = pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS
+		}
+
+		history, err := client.GetHandlerHistory(cmd.Context(), req)
 		if err != nil {
 			panic(err)
 		}
@@ -90,17 +150,34 @@ var handlersHistoryCmd = &cobra.Command{
 			fmt.Printf("Handler: %s\n", execution.HandlerName)
 			fmt.Printf("  Dispatch ID: %s\n", execution.DispatchId)
 			fmt.Printf("  Invocation ID: %s\n", execution.InvocationId)
+			fmt.Printf("  Reason: %s\n", execution.Reason)
 			fmt.Printf("  Timestamp: %s\n", execution.StartClientTimestamp.AsTime().Format(time.RFC3339))
 			fmt.Printf("  Duration: %dms\n", execution.DurationMs)
 			if execution.Error != nil {
 				fmt.Printf("  Error: %+v\n\n", execution.Error)
 			}
+			if execution.Result != "" {
+				fmt.Printf("  Result: %s\n", execution.Result)
+			}
+			if showArgs {
+				names := make([]string, 0, len(execution.Args))
+				for name := range execution.Args {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					fmt.Printf("  Arg %s: %s\n", name, execution.Args[name])
+				}
+			}
 			if logs {
 				for _, logLine := range execution.Logs {
 					fmt.Printf("  %s\t%s\t%s\n", logLine.Timestamp.AsTime().Format(time.RFC3339), logLine.Level, logLine.Message)
 				}
 			}
 		}
+		if history.NextCursor != "" {
+			fmt.Printf("\nEarlier executions: --cursor %s\n", history.NextCursor)
+		}
 
 	},
 }
@@ -118,11 +195,26 @@ var handlersLogsCmd = &cobra.Command{
 		client := buildClient(config.DefaultGrpcPort)
 
 		tail, _ := cmd.Flags().GetInt("tail")
+		follow, _ := cmd.Flags().GetBool("follow")
 
 		if tail < 0 {
 			log.Fatalf("tail must be a positive integer")
 		}
 
+		// start watching before reading history so no logs are missed between
+		// the two
+		var events pb.AxonAgent_WatchHandlerExecutionsClient
+		if follow {
+			var err error
+			events, err = client.WatchHandlerExecutions(cmd.Context(), &pb.WatchHandlerExecutionsRequest{
+				HandlerName: handlerName,
+				IncludeLogs: true,
+			})
+			if err != nil {
+				log.Fatalf("failed to watch handler: %v", err)
+			}
+		}
+
 		history, err := client.GetHandlerHistory(cmd.Context(), &pb.GetHandlerHistoryRequest{
 			HandlerName: handlerName,
 			IncludeLogs: true,
@@ -131,23 +223,135 @@ var handlersLogsCmd = &cobra.Command{
 		if err != nil {
 			panic(err)
 		}
+		printed := map[string]bool{}
 		for _, execution := range history.History {
+			printed[execution.InvocationId] = true
 			for _, logLine := range execution.Logs {
-				fmt.Printf("%s\t%s\t%s\n", logLine.Timestamp.AsTime().Format(time.RFC3339), logLine.Level, logLine.Message)
+				printLogLine(logLine)
+			}
+		}
+
+		if !follow {
+			return
+		}
+		for {
+			event, err := events.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.Fatalf("failed to watch handler: %v", err)
+			}
+			if event.Type == pb.HandlerExecutionEventType_EXECUTION_EVENT_LOG && !printed[event.InvocationId] {
+				printLogLine(event.Log)
 			}
 		}
 	},
 }
 
+func printLogLine(logLine *pb.Log) {
+	fmt.Printf("%s\t%s\t%s\n", logLine.Timestamp.AsTime().Format(time.RFC3339), logLine.Level, logLine.Message)
+}
+
+var handlersPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "pause a scheduled handler",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) == 0 || args[0] == "" {
+			log.Fatalf("handler name is required")
+		}
+		handlerName := args[0]
+
+		client := buildClient(config.DefaultGrpcPort)
+		_, err := client.PauseHandler(cmd.Context(), &pb.PauseHandlerRequest{HandlerName: handlerName})
+		if err != nil {
+			log.Fatalf("failed to pause handler: %v", err)
+		}
+		fmt.Printf("Paused handler %s\n", handlerName)
+	},
+}
+
+var handlersResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "resume a paused scheduled handler",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) == 0 || args[0] == "" {
+			log.Fatalf("handler name is required")
+		}
+		handlerName := args[0]
+
+		client := buildClient(config.DefaultGrpcPort)
+		_, err := client.ResumeHandler(cmd.Context(), &pb.ResumeHandlerRequest{HandlerName: handlerName})
+		if err != nil {
+			log.Fatalf("failed to resume handler: %v", err)
+		}
+		fmt.Printf("Resumed handler %s\n", handlerName)
+	},
+}
+
+var handlersTriggerCmd = &cobra.Command{
+	Use:   "trigger",
+	Short: "run a scheduled handler now",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) == 0 || args[0] == "" {
+			log.Fatalf("handler name is required")
+		}
+		handlerName := args[0]
+
+		client := buildClient(config.DefaultGrpcPort)
+		resp, err := client.TriggerHandler(cmd.Context(), &pb.TriggerHandlerRequest{HandlerName: handlerName})
+		if err != nil {
+			log.Fatalf("failed to trigger handler: %v", err)
+		}
+		fmt.Printf("Triggered handler %s, invocation ID: %s\n", handlerName, resp.InvocationId)
+	},
+}
+
+var handlersCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "cancel a queued or running invocation",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) == 0 || args[0] == "" {
+			log.Fatalf("invocation ID is required")
+		}
+		invocationId := args[0]
+
+		client := buildClient(config.DefaultGrpcPort)
+		_, err := client.CancelInvocation(cmd.Context(), &pb.CancelInvocationRequest{InvocationId: invocationId})
+		if err != nil {
+			log.Fatalf("failed to cancel invocation: %v", err)
+		}
+		fmt.Printf("Cancelled invocation %s\n", invocationId)
+	},
+}
+
 func init() {
 	handlersRootCmd.AddCommand(handlersList)
 
 	handlersRootCmd.AddCommand(handlersHistoryCmd)
 	handlersHistoryCmd.Flags().IntP("tail", "t", 0, "Show last N executions")
 	handlersHistoryCmd.Flags().BoolP("logs", "l", false, "Include logs")
+	handlersHistoryCmd.Flags().Bool("args", false, "Show invocation args")
+	handlersHistoryCmd.Flags().String("since", "", "Show executions started at or after a time, or a duration like 1h ago")
+	handlersHistoryCmd.Flags().String("until", "", "Show executions started before a time, or a duration like 1h ago")
+	handlersHistoryCmd.Flags().Bool("errors", false, "Show only failed executions")
+	handlersHistoryCmd.Flags().Bool("success", false, "Show only successful executions")
+	handlersHistoryCmd.Flags().String("dispatch-id", "", "Show only executions sent to a client")
+	handlersHistoryCmd.Flags().String("invocation-id", "", "Show only an invocation's executions")
+	handlersHistoryCmd.Flags().String("cursor", "", "Show the executions before a previous page")
 
 	handlersRootCmd.AddCommand(handlersLogsCmd)
 	handlersLogsCmd.Flags().IntP("tail", "t", 0, "Show last N executions")
+	handlersLogsCmd.Flags().BoolP("follow", "f", false, "Keep streaming logs as handlers run")
+
+	handlersRootCmd.AddCommand(handlersPauseCmd)
+	handlersRootCmd.AddCommand(handlersResumeCmd)
+	handlersRootCmd.AddCommand(handlersTriggerCmd)
+	handlersRootCmd.AddCommand(handlersCancelCmd)
 
 }
 
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/cortexapps/axon/config"
+	cortexHttp "github.com/cortexapps/axon/server/http"
+	"github.com/cortexapps/axon/util"
+
+	"github.com/spf13/cobra"
+)
+
+// deadletter commands talk to the agent's /__axon/deadletter endpoints
+//
+// usage
+// axon handlers deadletter list
+// axon handlers deadletter replay <id>
+// axon handlers deadletter purge [id]
+
+var handlersDeadLetterCmd = &cobra.Command{
+	Use:   "deadletter",
+	Short: "Manage failed webhook deliveries",
+}
+
+var handlersDeadLetterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list failed webhook deliveries",
+	Run: func(cmd *cobra.Command, args []string) {
+		var letters []*cortexHttp.DeadLetter
+		if err := axonHttpRequest(http.MethodGet, "/deadletter", &letters); err != nil {
+			log.Fatalf("failed to list dead letters: %v", err)
+		}
+
+		showBody, _ := cmd.Flags().GetBool("body")
+		for _, letter := range letters {
+			fmt.Printf("ID: %s\n", letter.Id)
+			fmt.Printf("  Webhook: %s\n", letter.WebhookId)
+			fmt.Printf("  Handler: %s\n", letter.HandlerName)
+			fmt.Printf("  Failed At: %s\n", util.TimeToString(letter.FailedAt))
+			fmt.Printf("  Error: %s: %s\n", letter.ErrorCode, letter.Error)
+			fmt.Printf("  Replays: %d\n", letter.Replays)
+			if showBody {
+				fmt.Printf("  Content Type: %s\n", letter.ContentType)
+				fmt.Printf("  Body: %s\n", letter.Body)
+			}
+			fmt.Println()
+		}
+	},
+}
+
+var handlersDeadLetterReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "replay a failed webhook delivery",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 || args[0] == "" {
+			log.Fatalf("dead letter id is required")
+		}
+
+		result := map[string]string{}
+		if err := axonHttpRequest(http.MethodPost, "/deadletter/"+args[0]+"/replay", &result); err != nil {
+			log.Fatalf("failed to replay dead letter: %v", err)
+		}
+		fmt.Printf("Replayed %s as invocation %s\n", args[0], result["invocation_id"])
+	},
+}
+
+var handlersDeadLetterPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "delete one or all failed webhook deliveries",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "/deadletter"
+		if len(args) > 0 && args[0] != "" {
+			path += "/" + args[0]
+		}
+
+		result := map[string]int{}
+		if err := axonHttpRequest(http.MethodDelete, path, &result); err != nil {
+			log.Fatalf("failed to purge dead letters: %v", err)
+		}
+		fmt.Printf("Purged %d dead letter(s)\n", result["purged"])
+	},
+}
+
+func init() {
+	handlersRootCmd.AddCommand(handlersDeadLetterCmd)
+	handlersDeadLetterCmd.AddCommand(handlersDeadLetterListCmd)
+	handlersDeadLetterListCmd.Flags().BoolP("body", "b", false, "Include request bodies")
+	handlersDeadLetterCmd.AddCommand(handlersDeadLetterReplayCmd)
+	handlersDeadLetterCmd.AddCommand(handlersDeadLetterPurgeCmd)
+}
+
+// axonHttpRequest calls an /__axon endpoint of the local agent and decodes
+// the JSON response into result.
+func axonHttpRequest(method string, path string, result any) error {
+	url := fmt.Sprintf("http://localhost:%d%s%s", config.DefaultHttpPort, cortexHttp.AxonPathRoot, path)
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		errBody := map[string]string{}
+		if json.Unmarshal(body, &errBody) == nil && errBody["error"] != "" {
+			return fmt.Errorf("%s", errBody["error"])
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, result)
+}
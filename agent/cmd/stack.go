@@ -9,7 +9,9 @@ import (
 	"github.com/cortexapps/axon/common"
 	"github.com/cortexapps/axon/config"
 	"github.com/cortexapps/axon/server"
+	"github.com/cortexapps/axon/server/handler"
 	cortexHttp "github.com/cortexapps/axon/server/http"
+	"github.com/cortexapps/axon/server/leader"
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -20,6 +22,11 @@ import (
 func startAgent(opts fx.Option) {
 	app := fx.New(
 		opts,
+		// appended last so it is the first hook to run on stop, draining
+		// invocations while the webhook and HTTP servers still answer
+		fx.Invoke(func(lifecycle fx.Lifecycle, agent *server.AxonAgent) {
+			lifecycle.Append(fx.Hook{OnStop: agent.Stop})
+		}),
 		fx.WithLogger(func(logger *zap.Logger) fxevent.Logger {
 			return &fxevent.ZapLogger{Logger: logger}
 		}),
@@ -62,15 +69,22 @@ var AgentModule = fx.Module("agent",
 	fx.Provide(createHttpClient),
 	fx.Provide(cortexHttp.NewAxonHandler),
 	fx.Provide(server.NewMainHttpServer),
+	fx.Provide(handler.NewHistoryManager),
+	fx.Provide(leader.NewElector),
 
 	fx.Invoke(func(config config.AgentConfig, logger *zap.Logger) {
 		if config.CortexApiToken == "" && !config.DryRun {
 			logger.Fatal("Cannot start agent: either CORTEX_API_TOKEN or DRYRUN is required")
 		}
 	}),
-	fx.Invoke(server.NewAxonAgent),
+	fx.Provide(server.NewAxonAgent),
+	fx.Invoke(func(*server.AxonAgent) {}),
 )
 
+// stopTimeoutMargin is how long the agent has to stop, beyond its shutdown
+// drain timeout.
+const stopTimeoutMargin = 15 * time.Second
+
 func initStack(cmd *cobra.Command, cfg config.AgentConfig, integrationInfo common.IntegrationInfo) fx.Option {
 	// This is a placeholder for the actual stack building logic
 	// It should be replaced with the actual implementation
@@ -78,5 +92,7 @@ func initStack(cmd *cobra.Command, cfg config.AgentConfig, integrationInfo commo
 		fx.Supply(integrationInfo),
 		fx.Supply(cmd),
 		fx.Supply(cfg),
+		// leave time for the agent to drain on stop
+		fx.StopTimeout(cfg.ShutdownDrainTimeout+stopTimeoutMargin),
 	)
 }
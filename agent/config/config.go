@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,19 @@ const DefaultGrpcPort = 50051
 const DefaultHttpPort = 80
 const WebhookServerPort = 8081
 
+// AxonPathRoot is the path under which the agent serves its own HTTP API.
+const AxonPathRoot = "/__axon"
+
+// DefaultWebhookRedactHeaders are the request headers whose values are hidden
+// from webhook handlers unless WEBHOOK_REDACT_HEADERS says otherwise.
+var DefaultWebhookRedactHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-Gitlab-Token"}
+
+// History backends for HANDLER_HISTORY_BACKEND.
+const (
+	HistoryBackendFile     = "file"
+	HistoryBackendEmbedded = "embedded"
+)
+
 type RelayReflectorMode int
 
 // RelayReflectorMode controls how the reflector proxy routes traffic.
@@ -81,6 +95,40 @@ type AgentConfig struct {
 	HandlerHistoryPath         string
 	HandlerHistoryMaxAge       time.Duration
 	HandlerHistoryMaxSizeBytes int64
+	HandlerHistoryBackend      string
+	HandlerHistoryDbPath       string
+	// HandlerHistoryMaxPayloadBytes caps the result and each arg recorded in
+	// history, and HandlerHistoryRedactArgs names the args whose values are
+	// left out
+	HandlerHistoryMaxPayloadBytes int
+	HandlerHistoryRedactArgs      []string
+	// HandlerHistoryMinCount is how many of each handler's most recent
+	// executions cleanup keeps, and HandlerHistoryRetention holds the limits
+	// of handlers that have their own
+	HandlerHistoryMinCount  int
+	HandlerHistoryRetention map[string]HistoryRetention
+	// HandlerHistoryCompressAfter is how old history files are before they
+	// are compressed, with 0 to leave them uncompressed. It is always 0 for
+	// the embedded backend.
+	HandlerHistoryCompressAfter time.Duration
+
+	HandlerQueueDurable    bool
+	HandlerQueuePath       string
+	HandlerQueueMaxBacklog int
+
+	DispatchQueueCapacity int
+	DispatchQueueOverflow string
+
+	WebhookDeadLetterPath string
+	WebhookDedupPath      string
+	WebhookRedactHeaders  []string
+
+	LeaderElection         string
+	LeaderElectionLockPath string
+	LeaderElectionUrl      string
+	LeaderElectionTTL      time.Duration
+
+	ShutdownDrainTimeout time.Duration
 
 	HttpDisableTLS            bool
 	HttpCaCertFilePath        string
@@ -220,6 +268,172 @@ func NewAgentEnvConfig() AgentConfig {
 		handlerHistoryMaxSizeBytes = hma
 	}
 
+	// HANDLER_HISTORY_BACKEND is "file" to keep a JSON file per execution in
+	// HANDLER_HISTORY_PATH, or "embedded" to keep history in a single
+	// append-only file of JSON lines at HANDLER_HISTORY_DB_PATH. The embedded
+	// backend isn't a database such as bbolt or SQLite: its index is held in
+	// memory and rebuilt by reading the whole file on start, and cleanup
+	// rewrites the file
+	historyBackend := HistoryBackendFile
+	if historyBackendEnv := os.Getenv("HANDLER_HISTORY_BACKEND"); historyBackendEnv != "" {
+		historyBackend = historyBackendEnv
+	}
+	if historyBackend != HistoryBackendFile && historyBackend != HistoryBackendEmbedded {
+		panic(fmt.Errorf("invalid HANDLER_HISTORY_BACKEND %q, must be %q or %q", historyBackend, HistoryBackendFile, HistoryBackendEmbedded))
+	}
+
+	historyDbPath := filepath.Join(filepath.Dir(historyPath), "history.jsonl")
+	if historyDbPathEnv := os.Getenv("HANDLER_HISTORY_DB_PATH"); historyDbPathEnv != "" {
+		historyDbPath = historyDbPathEnv
+	}
+
+	// HANDLER_HISTORY_MAX_PAYLOAD_BYTES caps the result and each arg recorded
+	// in history, and 0 leaves them out
+	historyMaxPayloadBytes := 4096
+	if historyMaxPayloadBytesEnv := os.Getenv("HANDLER_HISTORY_MAX_PAYLOAD_BYTES"); historyMaxPayloadBytesEnv != "" {
+		mpb, err := strconv.Atoi(historyMaxPayloadBytesEnv)
+		if err != nil {
+			panic(err)
+		}
+		if mpb < 0 {
+			panic(fmt.Errorf("invalid HANDLER_HISTORY_MAX_PAYLOAD_BYTES %d, must not be negative", mpb))
+		}
+		historyMaxPayloadBytes = mpb
+	}
+
+	// HANDLER_HISTORY_REDACT_ARGS names the args, like a webhook's "body",
+	// whose values are hidden in history
+	historyRedactArgs := []string{}
+	for _, arg := range strings.Split(os.Getenv("HANDLER_HISTORY_REDACT_ARGS"), ",") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			historyRedactArgs = append(historyRedactArgs, arg)
+		}
+	}
+
+	// HANDLER_HISTORY_MIN_COUNT is how many of each handler's most recent
+	// executions are kept however old or large they are
+	historyMinCount := 10
+	if historyMinCountEnv := os.Getenv("HANDLER_HISTORY_MIN_COUNT"); historyMinCountEnv != "" {
+		mc, err := strconv.Atoi(historyMinCountEnv)
+		if err != nil {
+			panic(err)
+		}
+		if mc < 0 {
+			panic(fmt.Errorf("invalid HANDLER_HISTORY_MIN_COUNT %d, must not be negative", mc))
+		}
+		historyMinCount = mc
+	}
+
+	historyRetention, err := ParseHistoryRetention(os.Getenv("HANDLER_HISTORY_RETENTION"))
+	if err != nil {
+		panic(err)
+	}
+
+	// HANDLER_HISTORY_COMPRESS_AFTER is how old history files are before they
+	// are gzipped, or 0, the default, to leave them as they are. Only the file
+	// backend compresses history, so the embedded backend rejects it.
+	var historyCompressAfter time.Duration
+	if historyCompressAfterEnv := os.Getenv("HANDLER_HISTORY_COMPRESS_AFTER"); historyCompressAfterEnv != "" {
+		ca, err := time.ParseDuration(historyCompressAfterEnv)
+		if err != nil {
+			panic(err)
+		}
+		if ca != 0 && historyBackend == HistoryBackendEmbedded {
+			panic(fmt.Errorf("HANDLER_HISTORY_COMPRESS_AFTER is not supported by the %q history backend", HistoryBackendEmbedded))
+		}
+		historyCompressAfter = ca
+	}
+
+	// HANDLER_QUEUE_DURABLE keeps webhook and INVOKE invocations on disk until
+	// a client receives them, so they survive a crash or restart, or the
+	// handler's client being disconnected
+	queueDurable := false
+	if queueDurableEnv := os.Getenv("HANDLER_QUEUE_DURABLE"); queueDurableEnv != "" {
+		queueDurable = queueDurableEnv == "true" || queueDurableEnv == "1"
+	}
+
+	// the queue lives next to the history directory by default
+	queuePath := filepath.Join(filepath.Dir(historyPath), "queue")
+	if queuePathEnv := os.Getenv("HANDLER_QUEUE_PATH"); queuePathEnv != "" {
+		queuePath = queuePathEnv
+	}
+
+	queueMaxBacklog := 10000
+	if queueMaxBacklogEnv := os.Getenv("HANDLER_QUEUE_MAX_BACKLOG"); queueMaxBacklogEnv != "" {
+		qmb, err := strconv.Atoi(queueMaxBacklogEnv)
+		if err != nil {
+			panic(err)
+		}
+		queueMaxBacklog = qmb
+	}
+
+	dispatchQueueCapacity := 1000
+	if dispatchQueueCapacityEnv := os.Getenv("DISPATCH_QUEUE_CAPACITY"); dispatchQueueCapacityEnv != "" {
+		dqc, err := strconv.Atoi(dispatchQueueCapacityEnv)
+		if err != nil {
+			panic(err)
+		}
+		dispatchQueueCapacity = dqc
+	}
+
+	// DISPATCH_QUEUE_OVERFLOW is "reject" to refuse triggers while a client's
+	// dispatch queue is full, or "drop_oldest" to drop the oldest waiting
+	// invocation of the same or a lower priority instead
+	dispatchQueueOverflow := "reject"
+	if dispatchQueueOverflowEnv := os.Getenv("DISPATCH_QUEUE_OVERFLOW"); dispatchQueueOverflowEnv != "" {
+		dispatchQueueOverflow = dispatchQueueOverflowEnv
+	}
+
+	deadLetterPath := filepath.Join(filepath.Dir(historyPath), "deadletter")
+	if deadLetterPathEnv := os.Getenv("WEBHOOK_DEADLETTER_PATH"); deadLetterPathEnv != "" {
+		deadLetterPath = deadLetterPathEnv
+	}
+
+	dedupPath := filepath.Join(filepath.Dir(historyPath), "dedup")
+	if dedupPathEnv := os.Getenv("WEBHOOK_DEDUP_PATH"); dedupPathEnv != "" {
+		dedupPath = dedupPathEnv
+	}
+
+	redactHeaders := DefaultWebhookRedactHeaders
+	if redactHeadersEnv := os.Getenv("WEBHOOK_REDACT_HEADERS"); redactHeadersEnv != "" {
+		redactHeaders = []string{}
+		for _, header := range strings.Split(redactHeadersEnv, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				redactHeaders = append(redactHeaders, header)
+			}
+		}
+	}
+
+	// LEADER_ELECTION is empty to run scheduled handlers on every replica,
+	// "file" to elect the replica holding a lock on a shared volume, or
+	// "http" to elect the replica holding a lease from LEADER_ELECTION_URL
+	leaderElection := os.Getenv("LEADER_ELECTION")
+
+	leaderLockPath := filepath.Join(filepath.Dir(historyPath), "leader.lock")
+	if leaderLockPathEnv := os.Getenv("LEADER_ELECTION_LOCK_PATH"); leaderLockPathEnv != "" {
+		leaderLockPath = leaderLockPathEnv
+	}
+
+	leaderTTL := 15 * time.Second
+	if leaderTTLEnv := os.Getenv("LEADER_ELECTION_TTL"); leaderTTLEnv != "" {
+		ttl, err := time.ParseDuration(leaderTTLEnv)
+		if err != nil {
+			panic(err)
+		}
+		leaderTTL = ttl
+	}
+
+	// SHUTDOWN_DRAIN_TIMEOUT is how long the agent waits on shutdown for
+	// queued and running invocations to complete
+	drainTimeout := 30 * time.Second
+	if drainTimeoutEnv := os.Getenv("SHUTDOWN_DRAIN_TIMEOUT"); drainTimeoutEnv != "" {
+		dt, err := time.ParseDuration(drainTimeoutEnv)
+		if err != nil {
+			panic(err)
+		}
+		drainTimeout = dt
+	}
+
 	identifier := os.Getenv("INTEGRATION_ALIAS")
 	if identifier == "" {
 		identifier = "custom-agent"
@@ -241,24 +455,44 @@ func NewAgentEnvConfig() AgentConfig {
 	}
 
 	cfg := AgentConfig{
-		GrpcPort:                   port,
-		CortexApiBaseUrl:           baseUrl,
-		CortexApiToken:             token,
-		DryRun:                     dryRun,
-		DequeueWaitTime:            dequeueWaitTime,
-		InstanceId:                 getInstanceId(),
-		IntegrationAlias:           identifier,
-		HttpServerPort:             httpPort,
-		WebhookServerPort:          WebhookServerPort,
-		SnykBrokerPort:             snykBrokerPort,
-		EnableApiProxy:             true,
-		EnablePprof:                enablePprof,
-		FailWaitTime:               time.Second * 2,
-		PluginDirs:                 []string{"./plugins"},
-		AutoRegisterFrequency:      reregisterFrequency,
-		HandlerHistoryPath:         historyPath,
-		HandlerHistoryMaxAge:       handlerHistoryMaxAge,
-		HandlerHistoryMaxSizeBytes: handlerHistoryMaxSizeBytes,
+		GrpcPort:                      port,
+		CortexApiBaseUrl:              baseUrl,
+		CortexApiToken:                token,
+		DryRun:                        dryRun,
+		DequeueWaitTime:               dequeueWaitTime,
+		InstanceId:                    getInstanceId(),
+		IntegrationAlias:              identifier,
+		HttpServerPort:                httpPort,
+		WebhookServerPort:             WebhookServerPort,
+		SnykBrokerPort:                snykBrokerPort,
+		EnableApiProxy:                true,
+		EnablePprof:                   enablePprof,
+		FailWaitTime:                  time.Second * 2,
+		PluginDirs:                    []string{"./plugins"},
+		AutoRegisterFrequency:         reregisterFrequency,
+		HandlerHistoryPath:            historyPath,
+		HandlerHistoryMaxAge:          handlerHistoryMaxAge,
+		HandlerHistoryMaxSizeBytes:    handlerHistoryMaxSizeBytes,
+		HandlerHistoryBackend:         historyBackend,
+		HandlerHistoryDbPath:          historyDbPath,
+		HandlerHistoryMaxPayloadBytes: historyMaxPayloadBytes,
+		HandlerHistoryRedactArgs:      historyRedactArgs,
+		HandlerHistoryMinCount:        historyMinCount,
+		HandlerHistoryRetention:       historyRetention,
+		HandlerHistoryCompressAfter:   historyCompressAfter,
+		HandlerQueueDurable:           queueDurable,
+		HandlerQueuePath:              queuePath,
+		HandlerQueueMaxBacklog:        queueMaxBacklog,
+		DispatchQueueCapacity:         dispatchQueueCapacity,
+		DispatchQueueOverflow:         dispatchQueueOverflow,
+		WebhookDeadLetterPath:         deadLetterPath,
+		WebhookDedupPath:              dedupPath,
+		WebhookRedactHeaders:          redactHeaders,
+		LeaderElection:                leaderElection,
+		LeaderElectionLockPath:        leaderLockPath,
+		LeaderElectionUrl:             os.Getenv("LEADER_ELECTION_URL"),
+		LeaderElectionTTL:             leaderTTL,
+		ShutdownDrainTimeout:          drainTimeout,
 	}
 
 	if builtinPluginDir := os.Getenv("BUILTIN_PLUGIN_DIR"); builtinPluginDir != "" {
@@ -23,6 +23,27 @@ func resetEnv() {
 		"ENABLE_RELAY_REFLECTOR",
 		"REFLECTOR_WEBSOCKET_UPGRADE",
 		"RELAY_IDLE_TIMEOUT",
+		"HANDLER_HISTORY_PATH",
+		"HANDLER_QUEUE_DURABLE",
+		"HANDLER_QUEUE_PATH",
+		"HANDLER_QUEUE_MAX_BACKLOG",
+		"DISPATCH_QUEUE_CAPACITY",
+		"DISPATCH_QUEUE_OVERFLOW",
+		"WEBHOOK_DEADLETTER_PATH",
+		"WEBHOOK_DEDUP_PATH",
+		"WEBHOOK_REDACT_HEADERS",
+		"LEADER_ELECTION",
+		"LEADER_ELECTION_LOCK_PATH",
+		"LEADER_ELECTION_URL",
+		"LEADER_ELECTION_TTL",
+		"SHUTDOWN_DRAIN_TIMEOUT",
+		"HANDLER_HISTORY_BACKEND",
+		"HANDLER_HISTORY_DB_PATH",
+		"HANDLER_HISTORY_MAX_PAYLOAD_BYTES",
+		"HANDLER_HISTORY_REDACT_ARGS",
+		"HANDLER_HISTORY_MIN_COUNT",
+		"HANDLER_HISTORY_RETENTION",
+		"HANDLER_HISTORY_COMPRESS_AFTER",
 	}
 
 	for _, v := range varsToClear {
@@ -198,6 +219,163 @@ func TestRelayIdleTimeoutEnvVar(t *testing.T) {
 	}
 }
 
+func TestHandlerQueueEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.False(t, config.HandlerQueueDurable)
+	require.Equal(t, "/tmp/axon-agent/queue", config.HandlerQueuePath)
+	require.Equal(t, 10000, config.HandlerQueueMaxBacklog)
+
+	os.Setenv("HANDLER_HISTORY_PATH", "/var/log/axon/history")
+	require.Equal(t, "/var/log/axon/queue", NewAgentEnvConfig().HandlerQueuePath)
+	require.Equal(t, "/var/log/axon/deadletter", NewAgentEnvConfig().WebhookDeadLetterPath)
+	require.Equal(t, "/var/log/axon/dedup", NewAgentEnvConfig().WebhookDedupPath)
+
+	os.Setenv("HANDLER_QUEUE_DURABLE", "true")
+	os.Setenv("HANDLER_QUEUE_PATH", "/data/queue")
+	os.Setenv("HANDLER_QUEUE_MAX_BACKLOG", "50")
+	config = NewAgentEnvConfig()
+	require.True(t, config.HandlerQueueDurable)
+	require.Equal(t, "/data/queue", config.HandlerQueuePath)
+	require.Equal(t, 50, config.HandlerQueueMaxBacklog)
+}
+
+func TestDispatchQueueEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.Equal(t, 1000, config.DispatchQueueCapacity)
+	require.Equal(t, "reject", config.DispatchQueueOverflow)
+
+	os.Setenv("DISPATCH_QUEUE_CAPACITY", "50")
+	os.Setenv("DISPATCH_QUEUE_OVERFLOW", "drop_oldest")
+	config = NewAgentEnvConfig()
+	require.Equal(t, 50, config.DispatchQueueCapacity)
+	require.Equal(t, "drop_oldest", config.DispatchQueueOverflow)
+}
+
+func TestWebhookRedactHeadersEnvVar(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	require.Equal(t, DefaultWebhookRedactHeaders, NewAgentEnvConfig().WebhookRedactHeaders)
+
+	os.Setenv("WEBHOOK_REDACT_HEADERS", "X-Api-Key, Authorization,")
+	require.Equal(t, []string{"X-Api-Key", "Authorization"}, NewAgentEnvConfig().WebhookRedactHeaders)
+}
+
+func TestLeaderElectionEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.Equal(t, "", config.LeaderElection)
+	require.Equal(t, "/tmp/axon-agent/leader.lock", config.LeaderElectionLockPath)
+	require.Equal(t, 15*time.Second, config.LeaderElectionTTL)
+
+	os.Setenv("LEADER_ELECTION", "http")
+	os.Setenv("LEADER_ELECTION_URL", "http://lease-server/leases/axon")
+	os.Setenv("LEADER_ELECTION_TTL", "30s")
+	config = NewAgentEnvConfig()
+	require.Equal(t, "http", config.LeaderElection)
+	require.Equal(t, "http://lease-server/leases/axon", config.LeaderElectionUrl)
+	require.Equal(t, 30*time.Second, config.LeaderElectionTTL)
+}
+
+func TestHistoryBackendEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.Equal(t, HistoryBackendFile, config.HandlerHistoryBackend)
+	require.Equal(t, "/tmp/axon-agent/history.jsonl", config.HandlerHistoryDbPath)
+
+	os.Setenv("HANDLER_HISTORY_BACKEND", "embedded")
+	os.Setenv("HANDLER_HISTORY_DB_PATH", "/data/history.jsonl")
+	config = NewAgentEnvConfig()
+	require.Equal(t, HistoryBackendEmbedded, config.HandlerHistoryBackend)
+	require.Equal(t, "/data/history.jsonl", config.HandlerHistoryDbPath)
+
+	// the embedded backend doesn't compress history
+	require.Equal(t, time.Duration(0), config.HandlerHistoryCompressAfter)
+	os.Setenv("HANDLER_HISTORY_COMPRESS_AFTER", "0")
+	require.NotPanics(t, func() { NewAgentEnvConfig() })
+	os.Setenv("HANDLER_HISTORY_COMPRESS_AFTER", "24h")
+	require.Panics(t, func() { NewAgentEnvConfig() })
+	os.Unsetenv("HANDLER_HISTORY_COMPRESS_AFTER")
+
+	os.Setenv("HANDLER_HISTORY_BACKEND", "sqlite")
+	require.Panics(t, func() { NewAgentEnvConfig() })
+}
+
+func TestHistoryPayloadEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.Equal(t, 4096, config.HandlerHistoryMaxPayloadBytes)
+	require.Empty(t, config.HandlerHistoryRedactArgs)
+
+	os.Setenv("HANDLER_HISTORY_MAX_PAYLOAD_BYTES", "0")
+	os.Setenv("HANDLER_HISTORY_REDACT_ARGS", "body, headers,")
+	config = NewAgentEnvConfig()
+	require.Equal(t, 0, config.HandlerHistoryMaxPayloadBytes)
+	require.Equal(t, []string{"body", "headers"}, config.HandlerHistoryRedactArgs)
+
+	os.Setenv("HANDLER_HISTORY_MAX_PAYLOAD_BYTES", "-1")
+	require.Panics(t, func() { NewAgentEnvConfig() })
+}
+
+func TestHistoryRetentionEnvVars(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	config := NewAgentEnvConfig()
+	require.Equal(t, 10, config.HandlerHistoryMinCount)
+	require.Empty(t, config.HandlerHistoryRetention)
+	require.Equal(t, time.Duration(0), config.HandlerHistoryCompressAfter)
+
+	os.Setenv("HANDLER_HISTORY_MIN_COUNT", "3")
+	os.Setenv("HANDLER_HISTORY_RETENTION", "nightly-sync=max_age:720h,min_count:30; poller=max_count:1000,max_bytes:10485760;")
+	os.Setenv("HANDLER_HISTORY_COMPRESS_AFTER", "24h")
+	config = NewAgentEnvConfig()
+	require.Equal(t, 3, config.HandlerHistoryMinCount)
+	require.Equal(t, map[string]HistoryRetention{
+		"nightly-sync": {MaxAge: 720 * time.Hour, MinCount: 30},
+		"poller":       {MaxCount: 1000, MaxBytes: 10485760},
+	}, config.HandlerHistoryRetention)
+	require.Equal(t, 24*time.Hour, config.HandlerHistoryCompressAfter)
+
+	os.Setenv("HANDLER_HISTORY_MIN_COUNT", "-1")
+	require.Panics(t, func() { NewAgentEnvConfig() })
+
+	for _, retention := range []string{"poller", "poller=max_count", "poller=max_count:many", "poller=max_rows:1", "=max_count:1"} {
+		_, err := ParseHistoryRetention(retention)
+		require.Error(t, err, retention)
+	}
+}
+
+func TestShutdownDrainTimeoutEnvVar(t *testing.T) {
+	oldEnv := util.SaveEnv(false)
+	defer util.RestoreEnv(oldEnv)
+	resetEnv()
+
+	require.Equal(t, 30*time.Second, NewAgentEnvConfig().ShutdownDrainTimeout)
+
+	os.Setenv("SHUTDOWN_DRAIN_TIMEOUT", "2m")
+	require.Equal(t, 2*time.Minute, NewAgentEnvConfig().ShutdownDrainTimeout)
+}
+
 func TestRelayReflectorMode_Helpers(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryRetention limits how much of a handler's history is kept. Zero
+// fields are unset.
+type HistoryRetention struct {
+	// MaxAge removes executions that started longer ago.
+	MaxAge time.Duration
+	// MaxCount keeps at most this many of the most recent executions.
+	MaxCount int
+	// MaxBytes keeps the most recent executions that fit in this many bytes.
+	MaxBytes int64
+	// MinCount keeps this many of the most recent executions whatever the
+	// other limits.
+	MinCount int
+}
+
+// ParseHistoryRetention parses HANDLER_HISTORY_RETENTION, a semicolon-separated
+// list of handler names and their limits, like
+//
+//	nightly-sync=max_age:720h,min_count:30;poller=max_count:1000,max_bytes:10485760
+func ParseHistoryRetention(value string) (map[string]HistoryRetention, error) {
+	retention := map[string]HistoryRetention{}
+	for _, handler := range strings.Split(value, ";") {
+		handler = strings.TrimSpace(handler)
+		if handler == "" {
+			continue
+		}
+		name, limits, ok := strings.Cut(handler, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid history retention %q, must be handler=limit:value,...", handler)
+		}
+
+		r := HistoryRetention{}
+		for _, limit := range strings.Split(limits, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(limit), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid history retention limit %q for %s", limit, name)
+			}
+			var err error
+			switch key {
+			case "max_age":
+				r.MaxAge, err = time.ParseDuration(value)
+			case "max_count":
+				r.MaxCount, err = strconv.Atoi(value)
+			case "max_bytes":
+				r.MaxBytes, err = strconv.ParseInt(value, 10, 64)
+			case "min_count":
+				r.MinCount, err = strconv.Atoi(value)
+			default:
+				return nil, fmt.Errorf("unknown history retention limit %q for %s", key, name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid history retention %s for %s: %w", key, name, err)
+			}
+		}
+		retention[name] = r
+	}
+	return retention, nil
+}
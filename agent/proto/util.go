@@ -50,11 +50,16 @@ func ReportToExecution(req *pb.ReportInvocationRequest, sentAt time.Time) *pb.Ha
 		DispatchId:             req.HandlerInvoke.DispatchId,
 		HandlerName:            req.HandlerInvoke.HandlerName,
 		InvocationId:           req.HandlerInvoke.InvocationId,
+		Attempt:                req.HandlerInvoke.Attempt,
+		OriginalInvocationId:   req.HandlerInvoke.OriginalInvocationId,
+		Reason:                 req.HandlerInvoke.Reason,
+		Args:                   req.HandlerInvoke.Args,
 		StartClientTimestamp:   req.StartClientTimestamp,
 		DurationMs:             req.DurationMs,
 		PublishServerTimestamp: timestamppb.New(sentAt),
 		ReceiveServerTimestamp: timestamppb.Now(),
 		Error:                  req.GetError(),
+		Result:                 req.GetResult().GetValue(),
 		Logs:                   req.Logs,
 	}
 	return execution
@@ -11,8 +11,20 @@ type Cron interface {
 	Remove(id string)
 }
 
+// parser accepts standard five field specs, an optional leading seconds
+// field, descriptors such as @daily and @every 5m, and a CRON_TZ= or TZ=
+// prefix selecting the time zone the spec is evaluated in.
+var parser = cronLib.NewParser(
+	cronLib.SecondOptional | cronLib.Minute | cronLib.Hour | cronLib.Dom | cronLib.Month | cronLib.Dow | cronLib.Descriptor,
+)
+
+// Parse returns the schedule described by spec.
+func Parse(spec string) (cronLib.Schedule, error) {
+	return parser.Parse(spec)
+}
+
 func New() Cron {
-	c := cronLib.New()
+	c := cronLib.New(cronLib.WithParser(parser))
 	// The robfig/cron scheduler only dispatches jobs once it has been
 	// started; without this, AddFunc registers entries that never fire.
 	c.Start()
@@ -24,3 +24,32 @@ func TestCronFiresAddedJob(t *testing.T) {
 		return atomic.LoadInt32(&fired) > 0
 	}, 3*time.Second, 50*time.Millisecond, "cron job never fired; scheduler was not started")
 }
+
+func TestParse(t *testing.T) {
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"30 * * * *", time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"15 30 * * * *", time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"@every 5m", time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)},
+		{"CRON_TZ=America/New_York 0 9 * * *", time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)},
+		{"TZ=Asia/Tokyo 0 0 * * *", time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			schedule, err := Parse(c.spec)
+			require.NoError(t, err)
+			require.True(t, c.next.Equal(schedule.Next(from)), "got %v", schedule.Next(from))
+		})
+	}
+
+	_, err := Parse("1 *")
+	require.Error(t, err)
+	_, err = Parse("CRON_TZ=Nowhere/Special 0 0 * * *")
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// concurrencyLimit is the HandlerConcurrencyOption declared at registration.
+type concurrencyLimit struct {
+	maxConcurrent int
+	queueOne      bool
+}
+
+// newConcurrencyLimit returns the concurrency limit declared in options, or
+// nil if the handler is unlimited.
+func newConcurrencyLimit(options []*pb.HandlerOption) *concurrencyLimit {
+	for _, option := range options {
+		concurrency := option.GetConcurrency()
+		if concurrency == nil || concurrency.MaxConcurrent <= 0 {
+			continue
+		}
+		return &concurrencyLimit{
+			maxConcurrent: int(concurrency.MaxConcurrent),
+			queueOne:      concurrency.Overflow == pb.HandlerOverflowPolicy_OVERFLOW_QUEUE_ONE,
+		}
+	}
+	return nil
+}
+
+// handlerSlots tracks the invocations of a limited handler that are running,
+// which includes those queued for dispatch, and the one held behind them.
+type handlerSlots struct {
+	running int
+	held    Invocable
+}
+
+type slotResult int
+
+const (
+	slotAcquired slotResult = iota
+	slotHeld
+	slotSkipped
+)
+
+// acquireSlot admits invoke if the handler is below its limit, otherwise
+// holds or skips it according to the overflow policy.
+func (s *handlerManager) acquireSlot(entryId string, invoke Invocable, limit *concurrencyLimit) slotResult {
+	s.slotsLock.Lock()
+	defer s.slotsLock.Unlock()
+
+	slots, ok := s.slots[entryId]
+	if !ok {
+		slots = &handlerSlots{}
+		s.slots[entryId] = slots
+	}
+
+	if slots.running < limit.maxConcurrent {
+		slots.running++
+		return slotAcquired
+	}
+	if limit.queueOne && slots.held == nil {
+		slots.held = invoke
+		return slotHeld
+	}
+	return slotSkipped
+}
+
+// releaseSlot frees the slot of a completed invocation. If an invocation is
+// held it takes over the slot and is returned for dispatch.
+func (s *handlerManager) releaseSlot(entryId string) Invocable {
+	s.slotsLock.Lock()
+	defer s.slotsLock.Unlock()
+
+	slots, ok := s.slots[entryId]
+	if !ok {
+		return nil
+	}
+	if held := slots.held; held != nil {
+		slots.held = nil
+		select {
+		case <-held.Done():
+			// its caller gave up while it was held
+		default:
+			return held
+		}
+	}
+	slots.running--
+	if slots.running <= 0 {
+		delete(s.slots, entryId)
+	}
+	return nil
+}
+
+// unhold removes invoke from its handler's held slot, returning whether it
+// was held.
+func (s *handlerManager) unhold(invoke Invocable) bool {
+	s.slotsLock.Lock()
+	defer s.slotsLock.Unlock()
+
+	slots, ok := s.slots[invoke.GetEntry().Id()]
+	if !ok || slots.held != invoke {
+		return false
+	}
+	slots.held = nil
+	return true
+}
+
+// skip completes an invocation rejected by its handler's concurrency limit,
+// counting it and recording it in history.
+func (s *handlerManager) skip(invoke Invocable, logger *zap.Logger) {
+	msg := invoke.ToDispatchInvoke()
+	err := &InvocationError{
+		Code:    ErrorCodeSkipped,
+		Message: fmt.Sprintf("handler %s is at its concurrency limit", msg.HandlerName),
+	}
+
+	logger.Warn("Handler is at its concurrency limit, skipping trigger")
+	invoke.Complete("", err)
+	if s.queue != nil && isDurableReason(invoke.GetReason()) {
+		s.queue.Complete(invoke, err)
+	}
+	s.invokeCounter.WithLabelValues(msg.HandlerName, ErrorCodeSkipped).Inc()
+	s.recordUndispatched(msg, err, logger)
+}
+
+// recordUndispatched records an invocation that completed with err without
+// being dispatched to a client in history.
+func (s *handlerManager) recordUndispatched(msg *pb.DispatchHandlerInvoke, err *InvocationError, logger *zap.Logger) {
+	now := timestamppb.Now()
+	execution := &pb.HandlerExecution{
+		HandlerName:            msg.HandlerName,
+		HandlerId:              msg.HandlerId,
+		InvocationId:           msg.InvocationId,
+		DispatchId:             msg.DispatchId,
+		Attempt:                msg.Attempt,
+		OriginalInvocationId:   msg.OriginalInvocationId,
+		Reason:                 msg.Reason,
+		Args:                   msg.Args,
+		PublishServerTimestamp: now,
+		StartClientTimestamp:   now,
+		Error: &pb.Error{
+			Code:    err.Code,
+			Message: err.Message,
+		},
+	}
+	if s.history != nil {
+		if err := s.history.Write(context.Background(), execution); err != nil {
+			logger.Error("Failed to record undispatched invocation", zap.Error(err))
+		}
+	}
+	for _, event := range NewCompletionEvents(execution) {
+		s.PublishExecutionEvent(event)
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func concurrencyOption(max int32, overflow pb.HandlerOverflowPolicy) *pb.HandlerOption {
+	return &pb.HandlerOption{
+		Option: &pb.HandlerOption_Concurrency{
+			Concurrency: &pb.HandlerConcurrencyOption{
+				MaxConcurrent: max,
+				Overflow:      overflow,
+			},
+		},
+	}
+}
+
+func invokeCount(t *testing.T, registry *prometheus.Registry, handlerName string, result string) float64 {
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range metrics {
+		if family.GetName() != "axon_handler_invokes" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["handler"] == handlerName && labels["result"] == result {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestConcurrencySkip(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	registry := prometheus.NewRegistry()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	mgr := NewHandlerManager(logger, cron.New(), registry, WithHistoryManager(history))
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		concurrencyOption(1, pb.HandlerOverflowPolicy_OVERFLOW_SKIP),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "first")
+	require.NoError(t, mgr.Trigger(first))
+
+	second := NewInvokeHandlerInvoke(entry, "second")
+	require.NoError(t, mgr.Trigger(second))
+	<-second.Done()
+	_, err = second.GetResult()
+	require.Equal(t, ErrorCodeSkipped, ErrorCode(err))
+	require.Equal(t, float64(1), invokeCount(t, registry, "handler1", ErrorCodeSkipped))
+
+	executions, err := history.GetHistory(context.Background(), "handler1", false, 0)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	require.Equal(t, second.ToDispatchInvoke().InvocationId, executions[0].InvocationId)
+	require.Equal(t, ErrorCodeSkipped, executions[0].Error.Code)
+
+	// once the first completes the handler can run again
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, first, dequeued)
+	first.Complete("ok", nil)
+
+	require.Eventually(t, func() bool {
+		third := NewInvokeHandlerInvoke(entry, "third")
+		require.NoError(t, mgr.Trigger(third))
+		select {
+		case <-third.Done():
+			return false
+		default:
+			return true
+		}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConcurrencyQueueOne(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		concurrencyOption(1, pb.HandlerOverflowPolicy_OVERFLOW_QUEUE_ONE),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "first")
+	second := NewInvokeHandlerInvoke(entry, "second")
+	third := NewInvokeHandlerInvoke(entry, "third")
+	require.NoError(t, mgr.Trigger(first))
+	require.NoError(t, mgr.Trigger(second))
+	require.NoError(t, mgr.Trigger(third))
+
+	// only one trigger is held behind the running one
+	<-third.Done()
+	_, err = third.GetResult()
+	require.Equal(t, ErrorCodeSkipped, ErrorCode(err))
+
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, first, dequeued)
+
+	held, err := mgr.Dequeue(context.Background(), "1", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, held)
+
+	first.Complete("ok", nil)
+	held, err = mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, second, held)
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		concurrencyOption(2, pb.HandlerOverflowPolicy_OVERFLOW_SKIP),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	invokes := []Invocable{
+		NewInvokeHandlerInvoke(entry, "1"),
+		NewInvokeHandlerInvoke(entry, "2"),
+		NewInvokeHandlerInvoke(entry, "3"),
+	}
+	for _, invoke := range invokes {
+		require.NoError(t, mgr.Trigger(invoke))
+	}
+
+	<-invokes[2].Done()
+	_, err = invokes[2].GetResult()
+	require.Equal(t, ErrorCodeSkipped, ErrorCode(err))
+
+	for _, invoke := range invokes[:2] {
+		select {
+		case <-invoke.Done():
+			require.Fail(t, "invocation within the limit was skipped")
+		default:
+		}
+	}
+}
+
+func TestConcurrencyQueueOne_CancelHeld(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		concurrencyOption(1, pb.HandlerOverflowPolicy_OVERFLOW_QUEUE_ONE),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "first")
+	held := NewInvokeHandlerInvoke(entry, "held")
+	require.NoError(t, mgr.Trigger(first))
+	require.NoError(t, mgr.Trigger(held))
+
+	// the held invocation is listed as queued
+	id := held.ToDispatchInvoke().InvocationId
+	invocations := mgr.Invocations()
+	require.Len(t, invocations, 2)
+	require.Equal(t, id, invocations[1].Invocation.InvocationId)
+	require.False(t, invocations[1].Completed())
+
+	require.NoError(t, mgr.Cancel(id))
+	_, err = held.GetResult()
+	require.Equal(t, ErrorCodeCancelled, ErrorCode(err))
+	require.Equal(t, id, mgr.Invocations()[1].Invocation.InvocationId)
+	require.True(t, mgr.Invocations()[1].Completed())
+
+	// cancelling it frees the held slot for the next trigger
+	next := NewInvokeHandlerInvoke(entry, "next")
+	require.NoError(t, mgr.Trigger(next))
+	first.Complete("ok", nil)
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, next, dequeued)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+)
+
+// Args an UPSTREAM invocation carries about the invocation that triggered it.
+const (
+	UpstreamHandlerArg      = "upstream-handler"
+	UpstreamInvocationIdArg = "upstream-invocation-id"
+	UpstreamResultArg       = "upstream-result"
+)
+
+// Dependencies returns the HandlerDependencyOptions in options.
+func Dependencies(options []*pb.HandlerOption) []*pb.HandlerDependencyOption {
+	dependencies := []*pb.HandlerDependencyOption{}
+	for _, option := range options {
+		if dependency := option.GetDependency(); dependency != nil {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+func NewUpstreamHandlerInvoke(entry HandlerEntry, upstream Invocable, result string, dependency *pb.HandlerDependencyOption) Invocable {
+	args := map[string]string{
+		UpstreamHandlerArg:      upstream.GetEntry().Name(),
+		UpstreamInvocationIdArg: upstream.ToDispatchInvoke().InvocationId,
+	}
+	if dependency.PassResult {
+		args[UpstreamResultArg] = result
+	}
+	invoke := NewHandlerInvoke(entry, pb.HandlerInvokeType_UPSTREAM, args)
+	if invoke.Timeout == 0 {
+		invoke.Timeout = defaultTimeout
+	}
+	return invoke
+}
+
+// checkDependencyCycle returns an error if registering name with options
+// would make a handler depend, directly or not, on itself.
+func (s *handlerManager) checkDependencyCycle(name string, options []*pb.HandlerOption) error {
+	upstreams := map[string][]string{}
+	for _, entry := range s.handlers {
+		for _, dependency := range Dependencies(entry.Options()) {
+			upstreams[entry.Name()] = append(upstreams[entry.Name()], dependency.HandlerName)
+		}
+	}
+	upstreams[name] = nil
+	for _, dependency := range Dependencies(options) {
+		upstreams[name] = append(upstreams[name], dependency.HandlerName)
+	}
+
+	visited := map[string]bool{}
+	var walk func(path []string) []string
+	walk = func(path []string) []string {
+		current := path[len(path)-1]
+		for _, upstream := range upstreams[current] {
+			if upstream == name {
+				return append(path, upstream)
+			}
+			if visited[upstream] {
+				continue
+			}
+			visited[upstream] = true
+			if cycle := walk(append(path, upstream)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+
+	if cycle := walk([]string{name}); cycle != nil {
+		slices.Reverse(cycle)
+		return fmt.Errorf("handler %s has a dependency cycle: %s", name, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// triggerDownstream runs the handlers that depend on upstream's handler
+// after it succeeded with result. A downstream handler registered by
+// several clients runs once, preferring the upstream's client.
+func (s *handlerManager) triggerDownstream(upstream Invocable, result string) {
+	upstreamEntry := upstream.GetEntry()
+
+	type downstream struct {
+		entry      HandlerEntry
+		dependency *pb.HandlerDependencyOption
+	}
+	selected := map[string]downstream{}
+	for _, entry := range s.ListHandlers() {
+		if !entry.IsActive() {
+			continue
+		}
+		for _, dependency := range Dependencies(entry.Options()) {
+			if dependency.HandlerName != upstreamEntry.Name() {
+				continue
+			}
+			current, ok := selected[entry.Name()]
+			if !ok || (current.entry.DispatchId() != upstreamEntry.DispatchId() && entry.DispatchId() == upstreamEntry.DispatchId()) {
+				selected[entry.Name()] = downstream{entry: entry, dependency: dependency}
+			}
+			break
+		}
+	}
+
+	for name, downstream := range selected {
+		s.logger.Info("Triggering downstream handler",
+			zap.String("handler", name),
+			zap.String("upstream", upstreamEntry.Name()),
+		)
+		invoke := NewUpstreamHandlerInvoke(downstream.entry, upstream, result, downstream.dependency)
+		if err := s.Trigger(invoke); err != nil {
+			s.logger.Error("Failed to trigger downstream handler", zap.String("handler", name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func dependencyOption(handlerName string, passResult bool) *pb.HandlerOption {
+	return &pb.HandlerOption{
+		Option: &pb.HandlerOption_Dependency{
+			Dependency: &pb.HandlerDependencyOption{
+				HandlerName: handlerName,
+				PassResult:  passResult,
+			},
+		},
+	}
+}
+
+func TestTriggerDownstream(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.NewNoopCron(), nil)
+
+	_, err := mgr.RegisterHandler("1", "fetch", defaultTimeout)
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "publish", defaultTimeout, dependencyOption("fetch", true))
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "notify", defaultTimeout, dependencyOption("fetch", false))
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	fetch := NewInvokeHandlerInvoke(mgr.GetByTag("fetch"), "")
+	require.NoError(t, mgr.Trigger(fetch))
+
+	invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "fetch", invoke.GetEntry().Name())
+	require.NoError(t, invoke.Complete("fetched", nil))
+
+	downstream := map[string]*pb.DispatchHandlerInvoke{}
+	for range 2 {
+		invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, invoke)
+		require.Equal(t, pb.HandlerInvokeType_UPSTREAM, invoke.GetReason())
+		downstream[invoke.GetEntry().Name()] = invoke.ToDispatchInvoke()
+	}
+
+	publish := downstream["publish"]
+	require.NotNil(t, publish)
+	require.Equal(t, "fetch", publish.Args[UpstreamHandlerArg])
+	require.Equal(t, fetch.ToDispatchInvoke().InvocationId, publish.Args[UpstreamInvocationIdArg])
+	require.Equal(t, "fetched", publish.Args[UpstreamResultArg])
+
+	notify := downstream["notify"]
+	require.NotNil(t, notify)
+	require.Equal(t, "fetch", notify.Args[UpstreamHandlerArg])
+	require.NotContains(t, notify.Args, UpstreamResultArg)
+}
+
+func TestTriggerDownstreamNotOnError(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.NewNoopCron(), nil)
+
+	_, err := mgr.RegisterHandler("1", "fetch", defaultTimeout)
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "publish", defaultTimeout, dependencyOption("fetch", true))
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(mgr.GetByTag("fetch"), "")))
+	invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, invoke.Complete("", errors.New("fetch failed")))
+
+	invoke, err = mgr.Dequeue(context.Background(), "1", 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, invoke)
+}
+
+func TestDependencyCycle(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.NewNoopCron(), nil)
+
+	_, err := mgr.RegisterHandler("1", "self", defaultTimeout, dependencyOption("self", false))
+	require.ErrorContains(t, err, "self -> self")
+
+	_, err = mgr.RegisterHandler("1", "a", defaultTimeout, dependencyOption("c", false))
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "b", defaultTimeout, dependencyOption("a", false))
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "c", defaultTimeout, dependencyOption("b", false))
+	require.ErrorContains(t, err, "c -> a -> b -> c")
+	require.Len(t, mgr.ListHandlers(), 2)
+
+	// a diamond is not a cycle
+	_, err = mgr.RegisterHandler("1", "d", defaultTimeout, dependencyOption("a", false), dependencyOption("b", false))
+	require.NoError(t, err)
+}
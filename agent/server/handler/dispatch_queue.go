@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Overflow policies for a full dispatch queue.
+const (
+	QueueOverflowReject     = "reject"
+	QueueOverflowDropOldest = "drop_oldest"
+)
+
+const defaultDispatchQueueCapacity = 1000
+
+// ErrorCodeDropped is the error code of an invocation dropped from a full
+// dispatch queue to make room for a newer one.
+const ErrorCodeDropped = "dropped"
+
+// ErrDispatchQueueFull is returned when triggering a handler whose client
+// already has as many invocations waiting as its dispatch queue holds.
+var ErrDispatchQueueFull = errors.New("dispatch queue is full")
+
+// priority is the class an invocation is dispatched in. Higher classes are
+// dequeued more often, but never to the exclusion of lower ones.
+type priority int
+
+const (
+	priorityScheduled priority = iota
+	priorityWebhook
+	priorityInvoke
+	priorityCount
+)
+
+// priorityWeights are the relative shares of dequeues each class gets while
+// all of them have invocations waiting.
+var priorityWeights = [priorityCount]int{
+	priorityScheduled: 1,
+	priorityWebhook:   2,
+	priorityInvoke:    4,
+}
+
+func (p priority) String() string {
+	switch p {
+	case priorityInvoke:
+		return "invoke"
+	case priorityWebhook:
+		return "webhook"
+	}
+	return "scheduled"
+}
+
+// priorityOf returns the class of an invocation triggered for reason: manual
+// invokes first, then webhooks, then everything run by the agent itself.
+func priorityOf(reason pb.HandlerInvokeType) priority {
+	switch reason {
+	case pb.HandlerInvokeType_INVOKE:
+		return priorityInvoke
+	case pb.HandlerInvokeType_WEBHOOK:
+		return priorityWebhook
+	}
+	return priorityScheduled
+}
+
+// dispatchQueue holds the invocations waiting for a client to dequeue them,
+// in a FIFO per priority class. Dequeues are shared between the classes by
+// smooth weighted round robin, so a burst of webhooks can't starve scheduled
+// runs. Pushing never blocks: once capacity invocations are waiting a push
+// is rejected, or with QueueOverflowDropOldest makes room by dropping the
+// oldest invocation of the lowest class no higher than its own.
+type dispatchQueue struct {
+	capacity int
+	overflow string
+	depth    *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	classes [priorityCount]*list.List
+	credit  [priorityCount]int
+	size    int
+	ready   chan struct{}
+}
+
+func newDispatchQueue(capacity int, overflow string, depth *prometheus.GaugeVec) *dispatchQueue {
+	if capacity <= 0 {
+		capacity = defaultDispatchQueueCapacity
+	}
+	q := &dispatchQueue{
+		capacity: capacity,
+		overflow: overflow,
+		depth:    depth,
+		ready:    make(chan struct{}, 1),
+	}
+	for i := range q.classes {
+		q.classes[i] = list.New()
+	}
+	return q
+}
+
+// push queues invoke, returning the invocation dropped to make room for it,
+// if any, or ErrDispatchQueueFull if there is no room.
+func (q *dispatchQueue) push(invoke Invocable) (Invocable, error) {
+	class := priorityOf(invoke.GetReason())
+
+	q.mu.Lock()
+	var dropped Invocable
+	if q.size >= q.capacity {
+		if q.overflow == QueueOverflowDropOldest {
+			dropped = q.dropOldest(class)
+		}
+		if dropped == nil {
+			q.mu.Unlock()
+			return nil, ErrDispatchQueueFull
+		}
+	}
+	q.classes[class].PushBack(invoke)
+	q.size++
+	q.mu.Unlock()
+
+	q.depth.WithLabelValues(invoke.GetEntry().Name(), class.String()).Inc()
+	q.signal()
+	return dropped, nil
+}
+
+// dropOldest removes the oldest invocation of the lowest non-empty class up
+// to max. Callers hold q.mu.
+func (q *dispatchQueue) dropOldest(max priority) Invocable {
+	for class := priorityScheduled; class <= max; class++ {
+		front := q.classes[class].Front()
+		if front == nil {
+			continue
+		}
+		invoke := q.classes[class].Remove(front).(Invocable)
+		if q.classes[class].Len() == 0 {
+			q.credit[class] = 0
+		}
+		q.size--
+		q.depth.WithLabelValues(invoke.GetEntry().Name(), class.String()).Dec()
+		return invoke
+	}
+	return nil
+}
+
+// pop waits up to waitTime for an invocation, returning nil if none arrives.
+func (q *dispatchQueue) pop(ctx context.Context, waitTime time.Duration) Invocable {
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+	for {
+		if invoke := q.tryPop(); invoke != nil {
+			return invoke
+		}
+		select {
+		case <-q.ready:
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (q *dispatchQueue) tryPop() Invocable {
+	q.mu.Lock()
+	if q.size == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+
+	// smooth weighted round robin over the classes with invocations waiting
+	selected := priority(-1)
+	total := 0
+	for class := priorityScheduled; class < priorityCount; class++ {
+		if q.classes[class].Len() == 0 {
+			continue
+		}
+		q.credit[class] += priorityWeights[class]
+		total += priorityWeights[class]
+		if selected < 0 || q.credit[class] > q.credit[selected] {
+			selected = class
+		}
+	}
+	q.credit[selected] -= total
+
+	invoke := q.classes[selected].Remove(q.classes[selected].Front()).(Invocable)
+	if q.classes[selected].Len() == 0 {
+		q.credit[selected] = 0
+	}
+	q.size--
+	remaining := q.size
+	q.mu.Unlock()
+
+	q.depth.WithLabelValues(invoke.GetEntry().Name(), selected.String()).Dec()
+	if remaining > 0 {
+		// wake another waiting consumer
+		q.signal()
+	}
+	return invoke
+}
+
+// drain removes and returns every invocation waiting.
+func (q *dispatchQueue) drain() []Invocable {
+	invocations := []Invocable{}
+	for invoke := q.tryPop(); invoke != nil; invoke = q.tryPop() {
+		invocations = append(invocations, invoke)
+	}
+	return invocations
+}
+
+func (q *dispatchQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// drop completes an invocation dropped from a full dispatch queue, recording
+// it in history.
+func (s *handlerManager) drop(invoke Invocable, logger *zap.Logger) {
+	msg := invoke.ToDispatchInvoke()
+	err := &InvocationError{
+		Code:    ErrorCodeDropped,
+		Message: fmt.Sprintf("dropped from the full dispatch queue of handler %s", msg.HandlerName),
+	}
+
+	logger.Warn("Dispatch queue is full, dropping oldest invocation",
+		zap.String("dropped-handler", msg.HandlerName),
+		zap.String("dropped-invocation-id", msg.InvocationId),
+	)
+	invoke.Complete("", err)
+	s.recordUndispatched(msg, err, logger)
+}
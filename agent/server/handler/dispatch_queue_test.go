@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestDispatchQueue(capacity int, overflow string) (*dispatchQueue, *prometheus.GaugeVec) {
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "depth"}, []string{"handler", "priority"})
+	return newDispatchQueue(capacity, overflow, depth), depth
+}
+
+func queueDepth(t *testing.T, depth *prometheus.GaugeVec, handler string, priority string) float64 {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(depth)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["handler"] == handler && labels["priority"] == priority {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestDispatchQueueWeightedFairness(t *testing.T) {
+	queue, depth := newTestDispatchQueue(100, QueueOverflowReject)
+	entry := NewHandlerEntry("1", "1", "handler1", defaultTimeout)
+
+	for range 7 {
+		for _, reason := range []pb.HandlerInvokeType{pb.HandlerInvokeType_INVOKE, pb.HandlerInvokeType_WEBHOOK, pb.HandlerInvokeType_RUN_INTERVAL} {
+			_, err := queue.push(NewHandlerInvoke(entry, reason, nil))
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, 7.0, queueDepth(t, depth, "handler1", "webhook"))
+
+	// every 7 dequeues serve 4 invokes, 2 webhooks and 1 scheduled run while
+	// all three are waiting
+	counts := map[pb.HandlerInvokeType]int{}
+	for range 7 {
+		invoke := queue.pop(context.Background(), time.Millisecond)
+		require.NotNil(t, invoke)
+		counts[invoke.GetReason()]++
+	}
+	require.Equal(t, map[pb.HandlerInvokeType]int{
+		pb.HandlerInvokeType_INVOKE:       4,
+		pb.HandlerInvokeType_WEBHOOK:      2,
+		pb.HandlerInvokeType_RUN_INTERVAL: 1,
+	}, counts)
+	require.Equal(t, 5.0, queueDepth(t, depth, "handler1", "webhook"))
+	require.Equal(t, 3.0, queueDepth(t, depth, "handler1", "invoke"))
+
+	for range 14 {
+		require.NotNil(t, queue.pop(context.Background(), time.Millisecond))
+	}
+	require.Nil(t, queue.pop(context.Background(), time.Millisecond))
+	require.Equal(t, 0.0, queueDepth(t, depth, "handler1", "scheduled"))
+}
+
+func TestDispatchQueueOverflow(t *testing.T) {
+	entry := NewHandlerEntry("1", "1", "handler1", defaultTimeout)
+	scheduled := NewHandlerInvoke(entry, pb.HandlerInvokeType_RUN_INTERVAL, nil)
+	webhook := NewHandlerInvoke(entry, pb.HandlerInvokeType_WEBHOOK, nil)
+
+	t.Run("reject", func(t *testing.T) {
+		queue, _ := newTestDispatchQueue(2, QueueOverflowReject)
+		_, err := queue.push(scheduled)
+		require.NoError(t, err)
+		_, err = queue.push(webhook)
+		require.NoError(t, err)
+		_, err = queue.push(NewHandlerInvoke(entry, pb.HandlerInvokeType_INVOKE, nil))
+		require.ErrorIs(t, err, ErrDispatchQueueFull)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		queue, _ := newTestDispatchQueue(2, QueueOverflowDropOldest)
+		_, err := queue.push(webhook)
+		require.NoError(t, err)
+		_, err = queue.push(scheduled)
+		require.NoError(t, err)
+
+		// the scheduled run goes first, being the lowest priority
+		invoke := NewHandlerInvoke(entry, pb.HandlerInvokeType_INVOKE, nil)
+		dropped, err := queue.push(invoke)
+		require.NoError(t, err)
+		require.Same(t, scheduled, dropped)
+
+		// a scheduled run can't push out a webhook
+		_, err = queue.push(NewHandlerInvoke(entry, pb.HandlerInvokeType_CRON_SCHEDULE, nil))
+		require.ErrorIs(t, err, ErrDispatchQueueFull)
+
+		require.Same(t, invoke, queue.pop(context.Background(), time.Millisecond))
+		require.Same(t, webhook, queue.pop(context.Background(), time.Millisecond))
+	})
+}
+
+func TestTriggerDispatchQueueFull(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.NewNoopCron(), nil, WithDispatchQueue(1, QueueOverflowDropOldest))
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "one")
+	require.NoError(t, mgr.Trigger(first))
+	second := NewInvokeHandlerInvoke(entry, "two")
+	require.NoError(t, mgr.Trigger(second))
+
+	<-first.Done()
+	_, err = first.GetResult()
+	require.Equal(t, ErrorCodeDropped, ErrorCode(err))
+
+	invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, second, invoke)
+
+	// nothing lower to drop, so a scheduled run is rejected
+	require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(entry, "three")))
+	scheduled := NewScheduledHandlerInvoke(entry, pb.HandlerInvokeType_RUN_NOW)
+	require.ErrorIs(t, mgr.Trigger(scheduled), ErrDispatchQueueFull)
+	_, err = scheduled.GetResult()
+	require.ErrorIs(t, err, ErrDispatchQueueFull)
+}
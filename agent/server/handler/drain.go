@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrDraining is returned when an invocation is triggered while the agent is
+// draining before it shuts down.
+var ErrDraining = errors.New("agent is shutting down")
+
+// errRetriesStopped completes an invocation whose retries a drain stopped.
+// The last attempt's failure is in history, and a durable invocation stays in
+// the durable queue to be sent again after the restart.
+var errRetriesStopped = &InvocationError{
+	Code:    ErrorCodeCancelled,
+	Message: "retries stopped: " + ErrDraining.Error(),
+}
+
+// drainPollInterval is how often Drain checks whether the invocations it is
+// waiting for have completed.
+const drainPollInterval = 100 * time.Millisecond
+
+// DrainSummary counts what became of the invocations that were queued or
+// running when a drain began, or were triggered by them.
+type DrainSummary struct {
+	// Completed is the number that completed during the drain.
+	Completed int
+	// Cancelled is the number still unfinished at the deadline, which were
+	// cancelled and recorded in history.
+	Cancelled int
+	// Persisted is the number still unfinished at the deadline that stay in
+	// the durable queue, to be replayed when the agent restarts.
+	Persisted int
+}
+
+// Drain stops the manager accepting new invocations, which fail with
+// ErrDraining, and retrying failed ones, and waits until those queued or
+// running complete or ctx is done. The ones still unfinished then are
+// cancelled, except that webhook and INVOKE invocations in the durable queue
+// are kept there for the next run.
+// Invocations that were never sent to a client are recorded in history here;
+// the agent reports those it sent when it tells their clients to stop.
+func (s *handlerManager) Drain(ctx context.Context) DrainSummary {
+	if !s.draining.Swap(true) {
+		close(s.drainStarted)
+	}
+
+	s.invocationsLock.Lock()
+	started := len(s.invocations)
+	s.invocationsLock.Unlock()
+	s.logger.Info("Draining invocations", zap.Int("count", started))
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for s.activeInvocations() > 0 {
+		select {
+		case <-ctx.Done():
+			return s.abandon(started)
+		case <-ticker.C:
+		}
+	}
+	return DrainSummary{Completed: started}
+}
+
+func (s *handlerManager) activeInvocations() int {
+	s.invocationsLock.Lock()
+	defer s.invocationsLock.Unlock()
+	return len(s.invocations)
+}
+
+// abandon cancels the invocations still unfinished at the end of a drain.
+func (s *handlerManager) abandon(started int) DrainSummary {
+	// from here completions no longer remove invocations from the durable
+	// queue
+	s.drained.Store(true)
+
+	s.invocationsLock.Lock()
+	remaining := make([]*trackedInvocation, 0, len(s.invocations))
+	for _, tracked := range s.invocations {
+		remaining = append(remaining, &trackedInvocation{
+			invoke:       tracked.invoke,
+			dispatchedAt: tracked.dispatchedAt,
+		})
+	}
+	s.invocationsLock.Unlock()
+
+	summary := DrainSummary{}
+	err := &InvocationError{
+		Code:    ErrorCodeCancelled,
+		Message: ErrDraining.Error(),
+	}
+	for _, tracked := range remaining {
+		if tracked.invoke.Complete("", err) != nil {
+			continue
+		}
+		if s.queue != nil && isDurableReason(tracked.invoke.GetReason()) {
+			summary.Persisted++
+			continue
+		}
+		summary.Cancelled++
+		if tracked.dispatchedAt.IsZero() {
+			logger := s.invokeLogger(tracked.invoke.GetEntry(), tracked.invoke)
+			s.recordUndispatched(tracked.invoke.ToDispatchInvoke(), err, logger)
+		}
+	}
+	summary.Completed = max(started-summary.Cancelled-summary.Persisted, 0)
+	return summary
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDrainWaitsForInvocations(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	running := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(running))
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, running, dequeued)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	drained := make(chan DrainSummary)
+	go func() {
+		drained <- mgr.Drain(ctx)
+	}()
+
+	// new invocations are rejected while draining
+	require.Eventually(t, func() bool {
+		return mgr.Trigger(NewInvokeHandlerInvoke(entry, "")) == ErrDraining
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, running.Complete("ok", nil))
+	select {
+	case summary := <-drained:
+		require.Equal(t, DrainSummary{Completed: 1}, summary)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "drain did not finish")
+	}
+}
+
+func TestDrainDeadline(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	queue, err := OpenDurableQueue(t.TempDir(), 0, logger)
+	require.NoError(t, err)
+	mgr := NewHandlerManager(logger, cron.New(), nil, WithHistoryManager(history), WithDurableQueue(queue))
+
+	_, err = mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	scheduled := NewScheduledHandlerInvoke(entry, pb.HandlerInvokeType_RUN_INTERVAL)
+	require.NoError(t, mgr.Trigger(scheduled))
+	invoke := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(invoke))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	summary := mgr.Drain(ctx)
+	require.Equal(t, DrainSummary{Cancelled: 1, Persisted: 1}, summary)
+
+	_, err = scheduled.GetResult()
+	require.Equal(t, ErrorCodeCancelled, ErrorCode(err))
+
+	// the scheduled run is recorded, and the INVOKE kept for the next run
+	executions, err := history.GetHistory(context.Background(), "handler1", false, 0)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	require.Equal(t, scheduled.ToDispatchInvoke().InvocationId, executions[0].InvocationId)
+
+	require.Never(t, func() bool {
+		return queue.Len() != 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestDrainStopsRetries(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue(dir, 0, logger)
+	require.NoError(t, err)
+	mgr := NewHandlerManager(logger, cron.New(), nil, WithDurableQueue(queue))
+
+	_, err = mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 3, InitialBackoffMs: int32(time.Minute.Milliseconds())}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	invoke := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(invoke))
+	first, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, first.Complete("", &InvocationError{Code: ErrorCodeUnexpected, Message: "boom"}))
+
+	// the drain doesn't wait out the backoff for a retry it would drop
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.Equal(t, DrainSummary{Completed: 1}, mgr.Drain(ctx))
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	_, err = invoke.GetResult()
+	require.Equal(t, ErrorCodeCancelled, ErrorCode(err))
+	next, err := mgr.Dequeue(context.Background(), "1", 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	// it is sent again after the restart
+	require.NoError(t, queue.Close())
+	queue, err = OpenDurableQueue(dir, 0, logger)
+	require.NoError(t, err)
+	defer queue.Close()
+	records := queue.Replayable()
+	require.Len(t, records, 1)
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, records[0].Id)
+}
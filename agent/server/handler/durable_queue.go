@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+)
+
+const (
+	durableQueueFileName    = "invocations.wal"
+	durableQueueCompactSize = 1000
+
+	queueOpEnqueue = "enqueue"
+	queueOpAck     = "ack"
+)
+
+var ErrQueueFull = errors.New("handler queue backlog is full")
+
+// ErrorCodeQueued is the code of ErrQueued.
+const ErrorCodeQueued = "queued"
+
+// ErrQueued completes a webhook or INVOKE invocation for a handler whose
+// client isn't connected once the durable queue holds it. It is sent when a
+// client for the handler next starts a dispatch session.
+var ErrQueued = &InvocationError{
+	Code:    ErrorCodeQueued,
+	Message: "handler is not connected, queued until it connects",
+}
+
+// queueRecord is a line in the durable queue's write-ahead log. An enqueue
+// record stays pending until an ack record with the same id follows it.
+type queueRecord struct {
+	Op          string            `json:"op"`
+	Id          string            `json:"id"`
+	HandlerName string            `json:"handler,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+	Args        map[string]string `json:"args,omitempty"`
+	Timestamp   time.Time         `json:"timestamp,omitempty"`
+}
+
+// DurableQueue persists webhook and manual invocations to disk until a client
+// has received them, so invocations that were queued when the agent crashed
+// or restarted, or that expired before a client received them, are replayed
+// when a client next starts a dispatch session. Invocations for a registered
+// handler whose client isn't connected are held the same way. Delivery is
+// at-least-once: an invocation that was sent but not yet reported when the
+// agent stopped is sent again.
+type DurableQueue struct {
+	path       string
+	maxBacklog int
+	logger     *zap.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]*queueRecord
+	order   []string
+	// live holds the invocations currently queued in memory, and dispatched
+	// those of them that have been sent to a client
+	live       map[string]Invocable
+	dispatched map[string]bool
+	acked      int
+}
+
+// OpenDurableQueue opens, or creates, the write-ahead log in dir. Pending
+// invocations from a previous run are kept for replay. A maxBacklog of zero
+// or less means the backlog is unbounded.
+func OpenDurableQueue(dir string, maxBacklog int, logger *zap.Logger) (*DurableQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	q := &DurableQueue{
+		path:       filepath.Join(dir, durableQueueFileName),
+		maxBacklog: maxBacklog,
+		logger:     logger.Named("durable-queue"),
+		pending:    make(map[string]*queueRecord),
+		live:       make(map[string]Invocable),
+		dispatched: make(map[string]bool),
+	}
+
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+
+	if len(q.pending) > 0 {
+		q.logger.Info("Loaded pending invocations", zap.Int("count", len(q.pending)))
+	}
+	return q, nil
+}
+
+func (q *DurableQueue) load() error {
+	file, err := os.Open(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open queue file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		record := &queueRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			// a crash mid-write can leave a partial last line
+			q.logger.Warn("Skipping invalid queue record", zap.Error(err))
+			continue
+		}
+		switch record.Op {
+		case queueOpEnqueue:
+			if _, ok := q.pending[record.Id]; !ok {
+				q.order = append(q.order, record.Id)
+			}
+			q.pending[record.Id] = record
+		case queueOpAck:
+			delete(q.pending, record.Id)
+		}
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the log with only the pending records. Callers hold q.mu.
+func (q *DurableQueue) compact() error {
+	tmpPath := q.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create queue file: %w", err)
+	}
+
+	order := make([]string, 0, len(q.pending))
+	writer := bufio.NewWriter(tmp)
+	for _, id := range q.order {
+		record, ok := q.pending[id]
+		if !ok {
+			continue
+		}
+		order = append(order, id)
+		if err := writeRecord(writer, record); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if q.file != nil {
+		q.file.Close()
+	}
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return fmt.Errorf("failed to replace queue file: %w", err)
+	}
+
+	q.file, err = os.OpenFile(q.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open queue file: %w", err)
+	}
+	q.order = order
+	q.acked = 0
+	return nil
+}
+
+func writeRecord(writer interface{ Write([]byte) (int, error) }, record *queueRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(line, '\n'))
+	return err
+}
+
+// append writes a record and syncs it to disk. Callers hold q.mu.
+func (q *DurableQueue) append(record *queueRecord) error {
+	if q.file == nil {
+		return fmt.Errorf("queue is closed")
+	}
+	if err := writeRecord(q.file, record); err != nil {
+		return err
+	}
+	return q.file.Sync()
+}
+
+// Enqueue records an invocation before it is queued for dispatch. Invocations
+// replayed from the log are already recorded and are only marked as queued.
+func (q *DurableQueue) Enqueue(invoke Invocable) error {
+	msg := invoke.ToDispatchInvoke()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.pending[msg.InvocationId]; ok {
+		q.live[msg.InvocationId] = invoke
+		return nil
+	}
+
+	if err := q.persist(msg); err != nil {
+		return err
+	}
+	q.live[msg.InvocationId] = invoke
+	return nil
+}
+
+// Hold records an invocation that isn't queued for dispatch, for a handler
+// whose client isn't connected. It is replayed like any other pending
+// invocation.
+func (q *DurableQueue) Hold(invoke Invocable) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.persist(invoke.ToDispatchInvoke())
+}
+
+// persist appends a pending invocation to the log. Callers hold q.mu.
+func (q *DurableQueue) persist(msg *pb.DispatchHandlerInvoke) error {
+	if q.maxBacklog > 0 && len(q.pending) >= q.maxBacklog {
+		return ErrQueueFull
+	}
+
+	record := &queueRecord{
+		Op:          queueOpEnqueue,
+		Id:          msg.InvocationId,
+		HandlerName: msg.HandlerName,
+		Reason:      msg.Reason.String(),
+		Args:        msg.Args,
+		Timestamp:   time.Now(),
+	}
+	if err := q.append(record); err != nil {
+		return fmt.Errorf("failed to persist invocation: %w", err)
+	}
+	q.pending[record.Id] = record
+	q.order = append(q.order, record.Id)
+	return nil
+}
+
+// Dispatched marks an invocation as handed to a client. With a retry policy
+// this is the first attempt, which shares the invocation's id.
+func (q *DurableQueue) Dispatched(invoke Invocable) {
+	id := invoke.ToDispatchInvoke().InvocationId
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.live[id]; ok {
+		q.dispatched[id] = true
+	}
+}
+
+// Complete removes a finished invocation from the log. An invocation that
+// expired before it was dispatched stays pending so it can be replayed on the
+// next dispatch session, in which case Complete returns true.
+func (q *DurableQueue) Complete(invoke Invocable, err error) bool {
+	id := invoke.ToDispatchInvoke().InvocationId
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.live[id] != invoke {
+		// replayed while this copy was still queued
+		return false
+	}
+	dispatched := q.dispatched[id]
+	delete(q.live, id)
+	delete(q.dispatched, id)
+
+	if !dispatched && errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if _, ok := q.pending[id]; !ok {
+		return false
+	}
+	if err := q.append(&queueRecord{Op: queueOpAck, Id: id}); err != nil {
+		q.logger.Error("Failed to acknowledge invocation", zap.String("invocation-id", id), zap.Error(err))
+		return false
+	}
+	delete(q.pending, id)
+
+	q.acked++
+	if q.acked >= durableQueueCompactSize {
+		if err := q.compact(); err != nil {
+			q.logger.Error("Failed to compact queue", zap.Error(err))
+		}
+	}
+	return false
+}
+
+// Replayable returns the pending invocations that are not currently queued,
+// oldest first.
+func (q *DurableQueue) Replayable() []*queueRecord {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var records []*queueRecord
+	for _, id := range q.order {
+		record, ok := q.pending[id]
+		if !ok {
+			continue
+		}
+		if _, ok := q.live[id]; ok {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Len returns the number of pending invocations.
+func (q *DurableQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *DurableQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return nil
+	}
+	err := q.file.Close()
+	q.file = nil
+	return err
+}
+
+// isDurableReason reports whether invocations for a reason are persisted.
+// Scheduled invocations are not, as the schedule fires them again.
+func isDurableReason(reason pb.HandlerInvokeType) bool {
+	return reason == pb.HandlerInvokeType_WEBHOOK || reason == pb.HandlerInvokeType_INVOKE
+}
+
+func (r *queueRecord) toInvoke(entry HandlerEntry) *HandlerInvoke {
+	invoke := NewHandlerInvoke(entry, pb.HandlerInvokeType(pb.HandlerInvokeType_value[r.Reason]), r.Args)
+	invoke.Id = r.Id
+	return invoke
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newDurableManager(t *testing.T, dir string, maxBacklog int) (Manager, *DurableQueue) {
+	logger, _ := zap.NewDevelopment()
+	queue, err := OpenDurableQueue(dir, maxBacklog, logger)
+	require.NoError(t, err)
+	return NewHandlerManager(logger, cron.New(), nil, WithDurableQueue(queue)), queue
+}
+
+func TestDurableQueue_ReplayAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	mgr, queue := newDurableManager(t, dir, 0)
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	invoke := NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+	require.Equal(t, 1, queue.Len())
+
+	// simulate the agent stopping before the invocation is reported
+	require.NoError(t, mgr.Close())
+
+	mgr, queue = newDurableManager(t, dir, 0)
+	defer mgr.Close()
+	require.Equal(t, 1, queue.Len())
+
+	_, err = mgr.RegisterHandler("2", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("2"))
+
+	replayed, err := mgr.Dequeue(context.Background(), "2", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	msg := replayed.ToDispatchInvoke()
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, msg.InvocationId)
+	require.Equal(t, "payload", msg.Args["body"])
+	require.Equal(t, "2", msg.DispatchId)
+
+	replayed.Complete("ok", nil)
+	require.Eventually(t, func() bool { return queue.Len() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestDurableQueue_AckOnComplete(t *testing.T) {
+	dir := t.TempDir()
+
+	mgr, queue := newDurableManager(t, dir, 0)
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "payload")))
+
+	invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	invoke.Complete("", &InvocationError{Code: ErrorCodeUnexpected})
+
+	require.Eventually(t, func() bool { return queue.Len() == 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, mgr.Close())
+
+	_, queue = newDurableManager(t, dir, 0)
+	require.Equal(t, 0, queue.Len())
+}
+
+func TestDurableQueue_ScheduledNotPersisted(t *testing.T) {
+	mgr, queue := newDurableManager(t, t.TempDir(), 0)
+	defer mgr.Close()
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout, FixtureHandlerOption())
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	invoke, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	require.Equal(t, 0, queue.Len())
+}
+
+func TestDurableQueue_MaxBacklog(t *testing.T) {
+	mgr, queue := newDurableManager(t, t.TempDir(), 1)
+	defer mgr.Close()
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	entry := mgr.GetByTag("handler1")
+	require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(entry, "one")))
+	require.ErrorIs(t, mgr.Trigger(NewInvokeHandlerInvoke(entry, "two")), ErrQueueFull)
+	require.Equal(t, 1, queue.Len())
+}
+
+func TestDurableQueue_ExpiredBeforeDispatch(t *testing.T) {
+	mgr, queue := newDurableManager(t, t.TempDir(), 0)
+	defer mgr.Close()
+
+	_, err := mgr.RegisterHandler("1", "handler1", 100*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	invoke := NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+
+	// the client goes away before the invocation is dispatched
+	require.NoError(t, mgr.Stop("1"))
+	<-invoke.Done()
+	require.Eventually(t, func() bool { return len(queue.Replayable()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// the expired invocation is skipped and only the replayed copy is sent
+	require.NoError(t, mgr.Start("1"))
+	replayed, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	require.NotSame(t, invoke, replayed)
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, replayed.ToDispatchInvoke().InvocationId)
+	next, err := mgr.Dequeue(context.Background(), "1", 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, next)
+}
+
+func TestDurableQueue_ExpiredNotReplayedUntilStart(t *testing.T) {
+	mgr, queue := newDurableManager(t, t.TempDir(), 0)
+	defer mgr.Close()
+
+	_, err := mgr.RegisterHandler("1", "handler1", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	// the client stays connected but doesn't receive
+	invoke := NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+	<-invoke.Done()
+	require.Eventually(t, func() bool { return len(queue.Replayable()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// it is kept, not queued again right away to expire again
+	time.Sleep(150 * time.Millisecond)
+	require.Len(t, queue.Replayable(), 1)
+	require.Equal(t, 1, queue.Len())
+
+	require.NoError(t, mgr.Start("1"))
+	replayed, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, replayed.ToDispatchInvoke().InvocationId)
+}
+
+func TestDurableQueue_HeldWhileDisconnected(t *testing.T) {
+	mgr, queue := newDurableManager(t, t.TempDir(), 0)
+	defer mgr.Close()
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	require.NoError(t, mgr.Stop("1"))
+
+	// the handler is still registered, so its invocation is held
+	entry := mgr.GetByTag("handler1")
+	require.NotNil(t, entry)
+	invoke := NewInvokeHandlerInvoke(entry, "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+	<-invoke.Done()
+	_, err = invoke.GetResult()
+	require.ErrorIs(t, err, ErrQueued)
+	require.Equal(t, 1, queue.Len())
+
+	// scheduled runs are not held
+	require.Error(t, mgr.Trigger(NewScheduledHandlerInvoke(entry, pb.HandlerInvokeType_RUN_NOW)))
+
+	require.NoError(t, mgr.Start("1"))
+	replayed, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, replayed.ToDispatchInvoke().InvocationId)
+	require.Equal(t, "payload", replayed.ToDispatchInvoke().Args["body"])
+
+	replayed.Complete("ok", nil)
+	require.Eventually(t, func() bool { return queue.Len() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestDurableQueue_SkipsPartialRecord(t *testing.T) {
+	dir := t.TempDir()
+	logger, _ := zap.NewDevelopment()
+
+	content := `{"op":"enqueue","id":"a","handler":"handler1","reason":"WEBHOOK","args":{"body":"x"}}
+{"op":"enqueue","id":"b","handler":"handler1","reason":"INVOKE"}
+{"op":"ack","id":"b"}
+{"op":"enqueue","id":"c","hand`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, durableQueueFileName), []byte(content), 0644))
+
+	queue, err := OpenDurableQueue(dir, 0, logger)
+	require.NoError(t, err)
+	defer queue.Close()
+
+	records := queue.Replayable()
+	require.Len(t, records, 1)
+	require.Equal(t, "a", records[0].Id)
+	require.Equal(t, "x", records[0].Args["body"])
+}
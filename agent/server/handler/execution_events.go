@@ -0,0 +1,102 @@
+package handler
+
+import (
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// executionEventBuffer is how many events a watcher can fall behind by
+// before further events are dropped for it.
+const executionEventBuffer = 256
+
+// executionWatcher receives the execution events of the handler named
+// handlerName, or of every handler if it is empty.
+type executionWatcher struct {
+	handlerName string
+	events      chan *pb.HandlerExecutionEvent
+}
+
+// WatchExecutions returns a channel of the execution events of the handler
+// named handlerName, or of every handler if it is empty, and a func that
+// stops watching and closes the channel. A watcher that doesn't keep up
+// misses events rather than holding up invocations.
+func (s *handlerManager) WatchExecutions(handlerName string) (<-chan *pb.HandlerExecutionEvent, func()) {
+	watcher := &executionWatcher{
+		handlerName: handlerName,
+		events:      make(chan *pb.HandlerExecutionEvent, executionEventBuffer),
+	}
+
+	s.watchersLock.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.watchersLock.Unlock()
+
+	return watcher.events, func() {
+		s.watchersLock.Lock()
+		defer s.watchersLock.Unlock()
+		if _, ok := s.watchers[watcher]; ok {
+			delete(s.watchers, watcher)
+			close(watcher.events)
+		}
+	}
+}
+
+// PublishExecutionEvent sends an event to the watchers of its handler.
+func (s *handlerManager) PublishExecutionEvent(event *pb.HandlerExecutionEvent) {
+	s.watchersLock.Lock()
+	defer s.watchersLock.Unlock()
+	for watcher := range s.watchers {
+		if watcher.handlerName != "" && watcher.handlerName != event.HandlerName {
+			continue
+		}
+		select {
+		case watcher.events <- event:
+		default:
+		}
+	}
+}
+
+// NewExecutionEvent returns an event of eventType about an invocation.
+func NewExecutionEvent(eventType pb.HandlerExecutionEventType, msg *pb.DispatchHandlerInvoke) *pb.HandlerExecutionEvent {
+	return &pb.HandlerExecutionEvent{
+		Type:                 eventType,
+		Timestamp:            timestamppb.Now(),
+		HandlerName:          msg.HandlerName,
+		InvocationId:         msg.InvocationId,
+		OriginalInvocationId: msg.OriginalInvocationId,
+		DispatchId:           msg.DispatchId,
+		Attempt:              msg.Attempt,
+		Reason:               msg.Reason,
+	}
+}
+
+// NewCompletionEvents returns the events for an execution recorded in
+// history: one for each of its log lines, then one whose type depends on how
+// it completed.
+func NewCompletionEvents(execution *pb.HandlerExecution) []*pb.HandlerExecutionEvent {
+	msg := &pb.DispatchHandlerInvoke{
+		HandlerName:          execution.HandlerName,
+		InvocationId:         execution.InvocationId,
+		OriginalInvocationId: execution.OriginalInvocationId,
+		DispatchId:           execution.DispatchId,
+		Attempt:              execution.Attempt,
+		Reason:               execution.Reason,
+	}
+
+	events := make([]*pb.HandlerExecutionEvent, 0, len(execution.Logs)+1)
+	for _, log := range execution.Logs {
+		event := NewExecutionEvent(pb.HandlerExecutionEventType_EXECUTION_EVENT_LOG, msg)
+		event.Log = log
+		events = append(events, event)
+	}
+
+	eventType := pb.HandlerExecutionEventType_EXECUTION_EVENT_COMPLETED
+	switch execution.GetError().GetCode() {
+	case ErrorCodeTimeout:
+		eventType = pb.HandlerExecutionEventType_EXECUTION_EVENT_TIMED_OUT
+	case ErrorCodeCancelled:
+		eventType = pb.HandlerExecutionEventType_EXECUTION_EVENT_CANCELLED
+	}
+	event := NewExecutionEvent(eventType, msg)
+	event.Execution = execution
+	return append(events, event)
+}
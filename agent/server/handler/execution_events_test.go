@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func nextExecutionEvent(t *testing.T, events <-chan *pb.HandlerExecutionEvent) *pb.HandlerExecutionEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "no execution event")
+		return nil
+	}
+}
+
+func TestWatchExecutions(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	_, err = mgr.RegisterHandler("1", "handler2", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	events, stop := mgr.WatchExecutions("handler1")
+	all, stopAll := mgr.WatchExecutions("")
+	defer stopAll()
+
+	invoke := NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "")
+	require.NoError(t, mgr.Trigger(invoke))
+	require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(mgr.GetByTag("handler2"), "")))
+	id := invoke.ToDispatchInvoke().InvocationId
+
+	queued := nextExecutionEvent(t, events)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_QUEUED, queued.Type)
+	require.Equal(t, id, queued.InvocationId)
+	require.Equal(t, pb.HandlerInvokeType_INVOKE, queued.Reason)
+
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, invoke, dequeued)
+	dispatched := nextExecutionEvent(t, events)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_DISPATCHED, dispatched.Type)
+	require.Equal(t, "1", dispatched.DispatchId)
+
+	// a watcher of every handler sees the other handler's invocations too
+	handlers := map[string]int{}
+	for range 3 {
+		handlers[nextExecutionEvent(t, all).HandlerName]++
+	}
+	require.Equal(t, map[string]int{"handler1": 2, "handler2": 1}, handlers)
+
+	stop()
+	_, ok := <-events
+	require.False(t, ok)
+	stop()
+}
+
+func TestWatchExecutions_Undispatched(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil).(*handlerManager)
+
+	events, stop := mgr.WatchExecutions("handler1")
+	defer stop()
+
+	// completed before it was sent, with no history to record it in
+	msg := &pb.DispatchHandlerInvoke{HandlerName: "handler1", InvocationId: "invocation-1"}
+	mgr.recordUndispatched(msg, &InvocationError{Code: ErrorCodeCancelled}, logger)
+	cancelled := nextExecutionEvent(t, events)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_CANCELLED, cancelled.Type)
+	require.Equal(t, "invocation-1", cancelled.InvocationId)
+	require.Equal(t, ErrorCodeCancelled, cancelled.Execution.Error.Code)
+}
+
+func TestWatchExecutions_SlowWatcher(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	events, stop := mgr.WatchExecutions("")
+	defer stop()
+
+	// events beyond the buffer are dropped rather than blocking the publisher
+	for range executionEventBuffer + 10 {
+		mgr.PublishExecutionEvent(&pb.HandlerExecutionEvent{HandlerName: "handler1"})
+	}
+	require.Len(t, events, executionEventBuffer)
+}
+
+func TestNewCompletionEvents(t *testing.T) {
+	execution := &pb.HandlerExecution{
+		HandlerName:  "handler1",
+		InvocationId: "invocation-1",
+		DispatchId:   "dispatch-1",
+		Logs: []*pb.Log{
+			{Level: "INFO", Message: "one"},
+			{Level: "INFO", Message: "two"},
+		},
+	}
+
+	events := NewCompletionEvents(execution)
+	require.Len(t, events, 3)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_LOG, events[0].Type)
+	require.Equal(t, "one", events[0].Log.Message)
+	require.Equal(t, "two", events[1].Log.Message)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_COMPLETED, events[2].Type)
+	require.Same(t, execution, events[2].Execution)
+	for _, event := range events {
+		require.Equal(t, "invocation-1", event.InvocationId)
+		require.Equal(t, "dispatch-1", event.DispatchId)
+	}
+
+	execution.Logs = nil
+	execution.Error = &pb.Error{Code: ErrorCodeTimeout}
+	events = NewCompletionEvents(execution)
+	require.Len(t, events, 1)
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_TIMED_OUT, events[0].Type)
+
+	execution.Error = &pb.Error{Code: "handler-failed"}
+	require.Equal(t, pb.HandlerExecutionEventType_EXECUTION_EVENT_COMPLETED, NewCompletionEvents(execution)[0].Type)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -10,6 +11,38 @@ import (
 	"github.com/google/uuid"
 )
 
+// Error codes reported for failed invocations. Clients may report their own
+// codes; these are the ones the agent and SDKs produce.
+const (
+	ErrorCodeTimeout    = "timeout"
+	ErrorCodeUnexpected = "unexpected"
+	ErrorCodeSkipped    = "skipped"
+)
+
+// InvocationError is the error an invocation completes with when the client
+// reports a failure. It keeps the client's error code so a timeout can be told
+// apart from a handler failure.
+type InvocationError struct {
+	Code    string
+	Message string
+}
+
+func (e *InvocationError) Error() string {
+	return fmt.Sprintf("invocation error: %s", e.Message)
+}
+
+// ErrorCode returns the error code for an invocation failure.
+func ErrorCode(err error) string {
+	var invocationErr *InvocationError
+	if errors.As(err, &invocationErr) {
+		return invocationErr.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeTimeout
+	}
+	return ErrorCodeUnexpected
+}
+
 type Invocable interface {
 	GetEntry() HandlerEntry
 	GetReason() pb.HandlerInvokeType
@@ -29,6 +62,11 @@ type HandlerInvoke struct {
 	Timeout   time.Duration
 	Timestamp time.Time
 
+	// Attempt is the 1-based attempt number of this invocation, and
+	// OriginalId the id of the first attempt, when a retry policy re-dispatches it.
+	Attempt    int32
+	OriginalId string
+
 	result string
 	err    error
 	done   chan struct{}
@@ -49,6 +87,7 @@ func NewHandlerInvoke(
 		Args:      args,
 		Timeout:   entry.Timeout(),
 		Timestamp: time.Now(),
+		Attempt:   1,
 
 		done:     make(chan struct{}),
 		started:  &atomic.Bool{},
@@ -56,14 +95,27 @@ func NewHandlerInvoke(
 	}
 }
 
-func (h HandlerInvoke) GetEntry() HandlerEntry {
+// newInvokeAttempt creates the given attempt of an invocation, sharing its
+// entry, reason and args. The first attempt keeps the original invocation id.
+func newInvokeAttempt(original Invocable, attempt int32) *HandlerInvoke {
+	msg := original.ToDispatchInvoke()
+	invoke := NewHandlerInvoke(original.GetEntry(), original.GetReason(), msg.Args)
+	invoke.Attempt = attempt
+	invoke.OriginalId = msg.InvocationId
+	if attempt == 1 {
+		invoke.Id = msg.InvocationId
+	}
+	return invoke
+}
+
+func (h *HandlerInvoke) GetEntry() HandlerEntry {
 	return h.Entry
 }
-func (h HandlerInvoke) GetName() string {
+func (h *HandlerInvoke) GetName() string {
 	return h.Entry.Name()
 }
 
-func (h HandlerInvoke) GetReason() pb.HandlerInvokeType {
+func (h *HandlerInvoke) GetReason() pb.HandlerInvokeType {
 	return h.Reason
 }
 
@@ -96,25 +148,34 @@ func (h *HandlerInvoke) Complete(result string, err error) error {
 	return fmt.Errorf("handler already finished")
 }
 
-func (h HandlerInvoke) GetResult() (string, error) {
-	if h.finished.Load() {
+func (h *HandlerInvoke) GetResult() (string, error) {
+	// done is closed after the result is set, unlike finished
+	select {
+	case <-h.done:
 		return h.result, h.err
+	default:
+		return "", fmt.Errorf("handler not finished")
 	}
-	return "", fmt.Errorf("handler not finished")
 }
 
-func (h HandlerInvoke) Done() <-chan struct{} {
+func (h *HandlerInvoke) Done() <-chan struct{} {
 	return h.done
 }
 
-func (h HandlerInvoke) ToDispatchInvoke() *pb.DispatchHandlerInvoke {
+func (h *HandlerInvoke) ToDispatchInvoke() *pb.DispatchHandlerInvoke {
+	originalId := h.OriginalId
+	if originalId == "" {
+		originalId = h.Id
+	}
 	return &pb.DispatchHandlerInvoke{
-		InvocationId: h.Id,
-		DispatchId:   h.Entry.DispatchId(),
-		HandlerId:    h.Entry.Id(),
-		HandlerName:  h.Entry.Name(),
-		Reason:       h.Reason,
-		Args:         h.Args,
-		TimeoutMs:    int32(h.Entry.Timeout().Milliseconds()),
+		InvocationId:         h.Id,
+		DispatchId:           h.Entry.DispatchId(),
+		HandlerId:            h.Entry.Id(),
+		HandlerName:          h.Entry.Name(),
+		Reason:               h.Reason,
+		Args:                 h.Args,
+		TimeoutMs:            int32(h.Entry.Timeout().Milliseconds()),
+		Attempt:              h.Attempt,
+		OriginalInvocationId: originalId,
 	}
 }
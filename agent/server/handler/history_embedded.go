@@ -0,0 +1,439 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"go.uber.org/zap"
+)
+
+// historyRecord is a line in the embedded history file.
+type historyRecord struct {
+	HandlerName string               `json:"handler"`
+	Start       int64                `json:"start"`
+	Execution   *pb.HandlerExecution `json:"execution"`
+}
+
+// historyIndexEntry locates a record in the history file.
+type historyIndexEntry struct {
+	start        time.Time
+	invocationId string
+	offset       int64
+	length       int64
+}
+
+// embeddedHistoryManager keeps history in a single append-only file of JSON
+// records, indexed in memory by handler name and start time, so reading a
+// handler's runs reads only those records from disk. It isn't backed by an
+// embedded database such as bbolt or SQLite, which the agent doesn't depend
+// on, so there is no on-disk index. When it opens it
+// imports, and then removes, any <millis>-<handler>.json files the file
+// backend left in the history directory.
+type embeddedHistoryManager struct {
+	*historyRetention
+	config  config.AgentConfig
+	logger  *zap.Logger
+	path    string
+	running atomic.Bool
+	done    chan struct{}
+
+	mu   sync.RWMutex
+	file *os.File
+	size int64
+	// index holds each handler's records ordered by start time
+	index map[string][]historyIndexEntry
+}
+
+func newEmbeddedHistoryManager(config config.AgentConfig, logger *zap.Logger) *embeddedHistoryManager {
+	return &embeddedHistoryManager{
+		historyRetention: newHistoryRetention(config),
+		config:           config,
+		logger:           logger,
+		path:             config.HandlerHistoryDbPath,
+		done:             make(chan struct{}),
+	}
+}
+
+func (s *embeddedHistoryManager) Start() error {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil
+	}
+	s.logger.Info("history manager started", zap.String("backend", config.HistoryBackendEmbedded), zap.String("path", s.path))
+
+	s.mu.Lock()
+	err := s.open()
+	s.mu.Unlock()
+	if err != nil {
+		s.logger.Error("failed to open history file", zap.Error(err))
+		return err
+	}
+
+	maxHistoryAge := s.config.HandlerHistoryMaxAge
+	maxSizeBytes := s.config.HandlerHistoryMaxSizeBytes
+	done := s.done
+	go func() {
+		for s.running.Load() {
+			select {
+			case <-done:
+				return
+			case <-time.After(cleanupInterval):
+				if _, err := s.cleanup(time.Now().Add(-maxHistoryAge), maxSizeBytes); err != nil {
+					s.logger.Error("failed to clean up history file", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *embeddedHistoryManager) Close() error {
+	if s.running.CompareAndSwap(true, false) {
+		close(s.done)
+		s.done = make(chan struct{})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.index = nil
+	return err
+}
+
+// open loads the index and imports file backend history, unless the history
+// file is already open. Callers hold s.mu for writing.
+func (s *embeddedHistoryManager) open() error {
+	if s.file != nil {
+		return nil
+	}
+	if s.path == "" {
+		return fmt.Errorf("history file path not set")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history file directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	s.file = file
+	s.index = make(map[string][]historyIndexEntry)
+	s.size = 0
+	if err := s.load(); err != nil {
+		s.file.Close()
+		s.file = nil
+		return err
+	}
+	return s.migrate()
+}
+
+// load builds the index from the history file. Callers hold s.mu for
+// writing.
+func (s *embeddedHistoryManager) load() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(s.file, 64*1024)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				// a crash mid-write can leave a partial last line, which the
+				// next write would corrupt
+				s.logger.Warn("Truncating partial history record", zap.Int64("offset", offset))
+				if err := s.file.Truncate(offset); err != nil {
+					return fmt.Errorf("failed to truncate history file: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read history file: %w", err)
+		}
+
+		length := int64(len(line))
+		record := &historyRecord{}
+		if err := json.Unmarshal(line, record); err != nil {
+			s.logger.Warn("Skipping invalid history record", zap.Int64("offset", offset), zap.Error(err))
+		} else {
+			s.insert(record, offset, length)
+		}
+		offset += length
+	}
+	s.size = offset
+	return nil
+}
+
+// insert adds a record to the index. Callers hold s.mu for writing.
+func (s *embeddedHistoryManager) insert(record *historyRecord, offset int64, length int64) {
+	entries := s.index[record.HandlerName]
+	entry := historyIndexEntry{
+		start:        time.Unix(0, record.Start),
+		invocationId: record.Execution.GetInvocationId(),
+		offset:       offset,
+		length:       length,
+	}
+	// records are mostly appended in order
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].start.After(entry.start)
+	})
+	entries = append(entries, historyIndexEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = entry
+	s.index[record.HandlerName] = entries
+}
+
+// contains returns whether the index has a record of the invocation starting
+// at start. Callers hold s.mu.
+func (s *embeddedHistoryManager) contains(handlerName string, start time.Time, invocationId string) bool {
+	entries := s.index[handlerName]
+	i := sort.Search(len(entries), func(i int) bool {
+		return !entries[i].start.Before(start)
+	})
+	for ; i < len(entries) && entries[i].start.Equal(start); i++ {
+		if entries[i].invocationId == invocationId {
+			return true
+		}
+	}
+	return false
+}
+
+// append writes a record and indexes it. Callers hold s.mu for writing.
+func (s *embeddedHistoryManager) append(execution *pb.HandlerExecution) error {
+	record := &historyRecord{
+		HandlerName: execution.HandlerName,
+		Start:       execution.StartClientTimestamp.AsTime().UnixNano(),
+		Execution:   execution,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write history record: %w", err)
+	}
+	s.insert(record, s.size, int64(len(line)))
+	s.size += int64(len(line))
+	return nil
+}
+
+// migrate imports the executions the file backend wrote to the history
+// directory, removing each file once it is imported. Callers hold s.mu for
+// writing.
+func (s *embeddedHistoryManager) migrate() error {
+	dir := s.config.HandlerHistoryPath
+	if dir == "" {
+		return nil
+	}
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	imported := 0
+	for _, file := range files {
+		if file.IsDir() || !fileParseRegExp.MatchString(file.Name()) {
+			continue
+		}
+		filePath := filepath.Join(dir, file.Name())
+		contents, err := readHistoryFile(filePath)
+		if err != nil {
+			return err
+		}
+		execution := &pb.HandlerExecution{}
+		if err := json.Unmarshal(contents, execution); err != nil {
+			s.logger.Error("failed to unmarshal history file", zap.String("path", filePath), zap.Error(err))
+			continue
+		}
+
+		// a previous import may have stopped before removing the file
+		if !s.contains(execution.HandlerName, execution.StartClientTimestamp.AsTime(), execution.InvocationId) {
+			if err := s.append(execution); err != nil {
+				return err
+			}
+			imported++
+		}
+		if err := os.Remove(filePath); err != nil {
+			s.logger.Error("failed to remove imported history file", zap.String("path", filePath), zap.Error(err))
+		}
+	}
+	if imported > 0 {
+		if err := s.file.Sync(); err != nil {
+			return err
+		}
+		s.logger.Info("Imported history files", zap.String("path", dir), zap.Int("count", imported))
+	}
+	return nil
+}
+
+func (s *embeddedHistoryManager) Write(ctx context.Context, execution *pb.HandlerExecution) error {
+	capturePayloads(s.config, execution)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.open(); err != nil {
+		s.logger.Error("failed to open history file", zap.Error(err))
+		return err
+	}
+	if err := s.append(execution); err != nil {
+		s.logger.Error("failed to write history record", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// read returns the execution recorded at entry. Callers hold s.mu.
+func (s *embeddedHistoryManager) read(entry historyIndexEntry) (*pb.HandlerExecution, error) {
+	line := make([]byte, entry.length)
+	if _, err := s.file.ReadAt(line, entry.offset); err != nil {
+		return nil, fmt.Errorf("failed to read history record: %w", err)
+	}
+	record := &historyRecord{}
+	if err := json.Unmarshal(line, record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history record: %w", err)
+	}
+	return record.Execution, nil
+}
+
+func (s *embeddedHistoryManager) GetHistory(ctx context.Context, handlerName string, includeLogs bool, tail int32) ([]*pb.HandlerExecution, error) {
+	page, err := s.Query(ctx, HistoryQuery{
+		HandlerName: handlerName,
+		IncludeLogs: includeLogs,
+		Tail:        tail,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page.Executions, nil
+}
+
+func (s *embeddedHistoryManager) Query(ctx context.Context, query HistoryQuery) (*HistoryPage, error) {
+	s.mu.RLock()
+	if s.file == nil {
+		s.mu.RUnlock()
+		s.mu.Lock()
+		err := s.open()
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		s.mu.RLock()
+	}
+	defer s.mu.RUnlock()
+
+	entries := s.index[query.HandlerName]
+	candidates := make([]historyCandidate, len(entries))
+	for i, entry := range entries {
+		candidates[i] = historyCandidate{
+			start: entry.start,
+			key:   entry.invocationId,
+			read: func() (*pb.HandlerExecution, error) {
+				execution, err := s.read(entry)
+				if err != nil {
+					s.logger.Error("failed to read history record", zap.String("handler", query.HandlerName), zap.Int64("offset", entry.offset), zap.Error(err))
+					return nil, nil
+				}
+				return execution, nil
+			},
+		}
+	}
+	return queryHistory(candidates, query)
+}
+
+// cleanup removes the records each handler's retention limits expire, or
+// that started before minTimestamp, then the oldest records until the rest fit
+// in maxSizeBytes, by rewriting the history file. It returns the number of records
+// removed.
+func (s *embeddedHistoryManager) cleanup(minTimestamp time.Time, maxSizeBytes int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.open(); err != nil {
+		return 0, err
+	}
+
+	all := make([]historyIndexEntry, 0)
+	executions := make([]storedExecution, 0)
+	for handlerName, entries := range s.index {
+		all = append(all, entries...)
+		for _, entry := range entries {
+			executions = append(executions, storedExecution{
+				handlerName: handlerName,
+				start:       entry.start,
+				size:        entry.length,
+			})
+		}
+	}
+
+	keep := make([]historyIndexEntry, 0, len(all))
+	for i, expired := range s.expired(executions, minTimestamp, maxSizeBytes, time.Now()) {
+		if !expired {
+			keep = append(keep, all[i])
+		}
+	}
+	deleteCount := len(all) - len(keep)
+	if deleteCount == 0 {
+		return 0, nil
+	}
+
+	// rewrite in file order
+	sort.Slice(keep, func(i, j int) bool {
+		return keep[i].offset < keep[j].offset
+	})
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create history file: %w", err)
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range keep {
+		line := make([]byte, entry.length)
+		if _, err := s.file.ReadAt(line, entry.offset); err != nil {
+			tmp.Close()
+			return 0, fmt.Errorf("failed to read history record: %w", err)
+		}
+		if _, err := writer.Write(line); err != nil {
+			tmp.Close()
+			return 0, err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	tmp.Close()
+
+	s.file.Close()
+	s.file = nil
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return 0, fmt.Errorf("failed to replace history file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("cleaned up history file", zap.String("path", s.path), zap.Int("deleted_record_count", deleteCount))
+	return deleteCount, nil
+}
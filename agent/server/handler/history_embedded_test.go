@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func embeddedHistoryConfig(t *testing.T) config.AgentConfig {
+	dir := t.TempDir()
+	return config.AgentConfig{
+		HandlerHistoryBackend: config.HistoryBackendEmbedded,
+		HandlerHistoryPath:    filepath.Join(dir, "history"),
+		HandlerHistoryDbPath:  filepath.Join(dir, "history.jsonl"),
+	}
+}
+
+func TestEmbeddedHistory(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := embeddedHistoryConfig(t)
+	hm := NewHistoryManager(cfg, logger)
+	require.IsType(t, &embeddedHistoryManager{}, hm)
+	require.NoError(t, hm.Start())
+
+	startTime := time.Now().Add(-time.Hour)
+	executions := createHandlerExecutions(startTime, 10, time.Second)
+	// written out of order, and with another handler's in between
+	executions[0], executions[1] = executions[1], executions[0]
+	executions[4].HandlerName = "other"
+	executions[5].Logs = []*pb.Log{{Level: "INFO", Message: "hello", Timestamp: timestamppb.New(startTime)}}
+	for _, e := range executions {
+		require.NoError(t, hm.Write(context.Background(), e))
+	}
+
+	history, err := hm.GetHistory(context.Background(), "HandlerName", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 9)
+	require.Equal(t, "InvocationId-0", history[0].InvocationId)
+	require.Equal(t, "InvocationId-1", history[1].InvocationId)
+	require.Nil(t, history[4].Logs)
+
+	history, err = hm.GetHistory(context.Background(), "HandlerName", true, 5)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	require.Equal(t, "InvocationId-5", history[0].InvocationId)
+	require.Equal(t, "hello", history[0].Logs[0].Message)
+
+	// the index is rebuilt from the history file when it reopens
+	require.NoError(t, hm.Close())
+	hm = NewHistoryManager(cfg, logger)
+	history, err = hm.GetHistory(context.Background(), "other", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, "InvocationId-4", history[0].InvocationId)
+	require.NoError(t, hm.Close())
+}
+
+func TestEmbeddedHistory_Migrate(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := embeddedHistoryConfig(t)
+
+	require.NoError(t, os.MkdirAll(cfg.HandlerHistoryPath, 0755))
+	files := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: cfg.HandlerHistoryPath}, logger)
+	executions := createHandlerExecutions(time.Now().Add(-time.Hour), 5, time.Second)
+	for _, e := range executions {
+		require.NoError(t, files.Write(context.Background(), e))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.HandlerHistoryPath, "not-history.txt"), []byte("x"), 0644))
+
+	hm := NewHistoryManager(cfg, logger)
+	require.NoError(t, hm.Start())
+	defer hm.Close()
+
+	history, err := hm.GetHistory(context.Background(), "HandlerName", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	for i, e := range executions {
+		require.Equal(t, e.InvocationId, history[i].InvocationId)
+		require.Equal(t, e.StartClientTimestamp.AsTime(), history[i].StartClientTimestamp.AsTime())
+	}
+
+	// imported files are removed, and other files left alone
+	entries, err := os.ReadDir(cfg.HandlerHistoryPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "not-history.txt", entries[0].Name())
+}
+
+func TestEmbeddedHistory_Cleanup(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	hm := NewHistoryManager(embeddedHistoryConfig(t), logger).(*embeddedHistoryManager)
+	defer hm.Close()
+
+	startTime := time.Now().Add(-time.Hour)
+	for _, e := range createHandlerExecutions(startTime, 60*60, time.Second) {
+		require.NoError(t, hm.Write(context.Background(), e))
+	}
+
+	deleted, err := hm.cleanup(startTime.Add(2*time.Minute), 1<<30)
+	require.NoError(t, err)
+	require.Equal(t, 120, deleted)
+
+	history, err := hm.GetHistory(context.Background(), "HandlerName", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 60*60-120)
+	require.Equal(t, "InvocationId-120", history[0].InvocationId)
+
+	// the newest records that fit in the size limit are kept
+	entries := hm.index["HandlerName"]
+	var size int64
+	for _, entry := range entries[len(entries)-100:] {
+		size += entry.length
+	}
+	deleted, err = hm.cleanup(startTime, size)
+	require.NoError(t, err)
+	require.Equal(t, 60*60-120-100, deleted)
+
+	history, err = hm.GetHistory(context.Background(), "HandlerName", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 100)
+	require.Equal(t, "InvocationId-3599", history[99].InvocationId)
+}
@@ -1,15 +1,18 @@
 package handler
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
-	"sort"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -24,20 +27,31 @@ type HistoryManager interface {
 	Close() error
 	Write(ctx context.Context, ex *pb.HandlerExecution) error
 	GetHistory(ctx context.Context, handlerName string, includeLogs bool, tail int32) ([]*pb.HandlerExecution, error)
+	Query(ctx context.Context, query HistoryQuery) (*HistoryPage, error)
+	// SetRetention sets the retention limits a handler registered with.
+	SetRetention(handlerName string, retention *pb.HandlerRetentionOption)
 }
 
 type historyManager struct {
+	*historyRetention
 	config  config.AgentConfig
 	logger  *zap.Logger
 	running atomic.Bool
 	done    chan struct{}
 }
 
-func NewHistoryManager(config config.AgentConfig, logger *zap.Logger) HistoryManager {
+// NewHistoryManager returns the history storage backend the config selects:
+// a JSON file per execution in the history directory by default, or a single
+// append-only file of JSON lines with HANDLER_HISTORY_BACKEND=embedded.
+func NewHistoryManager(cfg config.AgentConfig, logger *zap.Logger) HistoryManager {
+	if cfg.HandlerHistoryBackend == config.HistoryBackendEmbedded {
+		return newEmbeddedHistoryManager(cfg, logger)
+	}
 	return &historyManager{
-		config: config,
-		logger: logger,
-		done:   make(chan struct{}),
+		historyRetention: newHistoryRetention(cfg),
+		config:           cfg,
+		logger:           logger,
+		done:             make(chan struct{}),
 	}
 }
 
@@ -53,6 +67,7 @@ func (s *historyManager) Start() error {
 		}
 		maxHistoryAge := s.config.HandlerHistoryMaxAge
 		maxSizeBytes := s.config.HandlerHistoryMaxSizeBytes
+		compressAfter := s.config.HandlerHistoryCompressAfter
 		go func() {
 			for s.running.Load() {
 				select {
@@ -61,6 +76,9 @@ func (s *historyManager) Start() error {
 				case <-time.After(cleanupInterval):
 					minTimestamp := time.Now().Add(-maxHistoryAge)
 					s.cleanupDirectory(historyPath, minTimestamp, maxSizeBytes, nil)
+					if compressAfter > 0 {
+						s.compressDirectory(historyPath, time.Now().Add(-compressAfter))
+					}
 				}
 			}
 		}()
@@ -75,13 +93,16 @@ func (s *historyManager) Close() error {
 	return nil
 }
 
-var fileParseRegExp = regexp.MustCompile(`^(\d+)-(.+)\.json$`)
+var fileParseRegExp = regexp.MustCompile(`^(\d+)-(.+?)\.json(\.gz)?$`)
+
+// compressedSuffix is added to the name of a history file when it is gzipped.
+const compressedSuffix = ".gz"
 
 func (s *historyManager) parseHistoryFileName(historyFilePath string) (string, time.Time, error) {
 
 	matches := fileParseRegExp.FindStringSubmatch(historyFilePath)
 
-	if len(matches) != 3 {
+	if len(matches) != 4 {
 		return "", time.Time{}, fmt.Errorf("failed to parse history file name: %s", historyFilePath)
 	}
 
@@ -134,29 +155,27 @@ func (s *historyManager) cleanupDirectory(path string, minTimestamp time.Time, m
 		infos = append(infos, info)
 	}
 
-	// Sort files by modification time (newest first)
-	sort.Slice(infos, func(i, j int) bool {
-		leftModTime := infos[i].ModTime()
-		rightModTime := infos[j].ModTime()
-		return leftModTime.UnixMilli() > rightModTime.UnixMilli()
-	})
-
 	if extractTimestamp == nil {
 		extractTimestamp = func(info os.FileInfo) time.Time {
 			return info.ModTime()
 		}
 	}
 
+	executions := make([]storedExecution, len(infos))
+	for i, info := range infos {
+		// files that aren't executions are only limited by age and size
+		handlerName, _, _ := s.parseHistoryFileName(info.Name())
+		executions[i] = storedExecution{
+			handlerName: handlerName,
+			start:       extractTimestamp(info),
+			size:        info.Size(),
+		}
+	}
+
 	var deleteCount int
-	var remainingSize int64 = int64(maxSizeBytes)
-	for _, info := range infos {
-		timestamp := extractTimestamp(info)
-		tooOld := timestamp.Before(minTimestamp)
-		if !tooOld {
-			remainingSize -= info.Size()
-		}
-		if tooOld || remainingSize < 0 {
-			filePath := filepath.Join(path, info.Name())
+	for i, expired := range s.expired(executions, minTimestamp, maxSizeBytes, time.Now()) {
+		if expired {
+			filePath := filepath.Join(path, infos[i].Name())
 			err := os.Remove(filePath)
 			if err != nil {
 				s.logger.Error("failed to remove history file", zap.String("path", filePath), zap.Error(err))
@@ -170,9 +189,84 @@ func (s *historyManager) cleanupDirectory(path string, minTimestamp time.Time, m
 	return deleteCount, nil
 }
 
+// compressDirectory gzips the history files last modified before
+// maxTimestamp. Their modification time is kept, so cleanup sees their age.
+func (s *historyManager) compressDirectory(path string, maxTimestamp time.Time) (int, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var compressCount int
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || !fileParseRegExp.MatchString(file.Name()) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil || !info.ModTime().Before(maxTimestamp) {
+			continue
+		}
+		filePath := filepath.Join(path, file.Name())
+		if err := compressFile(filePath, info.ModTime()); err != nil {
+			s.logger.Error("failed to compress history file", zap.String("path", filePath), zap.Error(err))
+			continue
+		}
+		compressCount++
+	}
+
+	if compressCount > 0 {
+		s.logger.Info("compressed history files", zap.String("path", path), zap.Int("compressed_file_count", compressCount))
+	}
+	return compressCount, nil
+}
+
+// compressFile replaces the file at filePath with a gzipped copy named with
+// compressedSuffix and last modified at modTime.
+func compressFile(filePath string, modTime time.Time) error {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(contents); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	compressedPath := filePath + compressedSuffix
+	if err := os.WriteFile(compressedPath, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Chtimes(compressedPath, modTime, modTime); err != nil {
+		os.Remove(compressedPath)
+		return err
+	}
+	return os.Remove(filePath)
+}
+
+// readHistoryFile returns the contents of a history file, decompressing it
+// if it was gzipped.
+func readHistoryFile(filePath string) ([]byte, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil || !strings.HasSuffix(filePath, compressedSuffix) {
+		return contents, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(contents))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
 // ReportInvocation is called by the client to report the result of an invocation, which will
 // log the result of an invocation into the history path.
 func (s *historyManager) Write(ctx context.Context, execution *pb.HandlerExecution) error {
+	capturePayloads(s.config, execution)
 
 	jsonData, err := json.Marshal(execution)
 	if err != nil {
@@ -192,57 +286,137 @@ func (s *historyManager) Write(ctx context.Context, execution *pb.HandlerExecuti
 
 // GetHandlerHistory returns the history of a handler
 func (s *historyManager) GetHistory(ctx context.Context, handlerName string, includeLogs bool, tail int32) ([]*pb.HandlerExecution, error) {
+	page, err := s.Query(ctx, HistoryQuery{
+		HandlerName: handlerName,
+		IncludeLogs: includeLogs,
+		Tail:        tail,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page.Executions, nil
+}
+
+// Query returns the executions of a handler that match the query. The time
+// range is matched against the timestamp in the file names, so only the files
+// in range are read.
+func (s *historyManager) Query(ctx context.Context, query HistoryQuery) (*HistoryPage, error) {
 	// search the handler history path for anything with this handler name
 	files, err := os.ReadDir(s.config.HandlerHistoryPath)
 
 	if os.IsNotExist(err) {
-		return make([]*pb.HandlerExecution, 0), nil
+		return &HistoryPage{Executions: make([]*pb.HandlerExecution, 0)}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	historyItems := make([]*pb.HandlerExecution, 0, 16)
+	candidates := make([]historyCandidate, 0, len(files))
 	for _, file := range files {
 		if file.IsDir() {
 			continue
 		}
 
-		fileName, _, err := s.parseHistoryFileName(file.Name())
+		fileName, timestamp, err := s.parseHistoryFileName(file.Name())
 
 		if err != nil {
 			s.logger.Error("failed to parse history file name", zap.Error(err))
 			continue
 		}
 
-		if fileName == handlerName {
+		if fileName == query.HandlerName {
 			filePath := filepath.Join(s.config.HandlerHistoryPath, file.Name())
-			contents, err := os.ReadFile(filePath)
-			if err != nil {
-				return nil, err
-			}
+			candidates = append(candidates, historyCandidate{
+				start: timestamp,
+				key:   file.Name(),
+				read: func() (*pb.HandlerExecution, error) {
+					contents, err := readHistoryFile(filePath)
+					if err != nil {
+						return nil, err
+					}
+
+					execution := &pb.HandlerExecution{}
+					err = json.Unmarshal(contents, execution)
+					if err != nil {
+						s.logger.Error("failed to unmarshal history file", zap.String("path", filePath), zap.Error(err))
+						return nil, nil
+					}
+					return execution, nil
+				},
+			})
+		}
+	}
+	return queryHistory(candidates, query)
+}
 
-			execution := &pb.HandlerExecution{}
-			err = json.Unmarshal(contents, execution)
-			if err != nil {
-				s.logger.Error("failed to unmarshal history file", zap.String("path", filePath), zap.Error(err))
-				continue
-			}
+// RunSummary describes the outcome of a handler's recorded runs.
+type RunSummary struct {
+	LastResult          pb.HandlerRunResult
+	LastDurationMs      int32
+	LastError           *pb.Error
+	ConsecutiveFailures int32
+	// ConsecutiveFailuresAtLeast is set when ConsecutiveFailures stopped at
+	// the most history that is read, so there may be more.
+	ConsecutiveFailuresAtLeast bool
+}
 
-			if !includeLogs {
-				execution.Logs = nil
-			}
+// runSummaryPageSize is how many executions SummarizeHandlerRuns reads at a
+// time, and runSummaryMaxPages how many pages it reads at most.
+const (
+	runSummaryPageSize = 20
+	runSummaryMaxPages = 5
+)
 
-			historyItems = append(historyItems, execution)
+// SummarizeHandlerRuns summarizes a handler's history, reading it newest first
+// a page at a time, and only reading earlier pages while every run failed, up
+// to runSummaryMaxPages.
+func SummarizeHandlerRuns(ctx context.Context, history HistoryManager, handlerName string) (RunSummary, error) {
+	var executions []*pb.HandlerExecution
+	query := HistoryQuery{
+		HandlerName: handlerName,
+		Tail:        runSummaryPageSize,
+	}
+	for pages := 1; ; pages++ {
+		page, err := history.Query(ctx, query)
+		if err != nil {
+			return SummarizeRuns(executions), err
+		}
+		executions = append(page.Executions, executions...)
+		if page.NextCursor == "" || slices.ContainsFunc(page.Executions, func(e *pb.HandlerExecution) bool {
+			return e.Error == nil
+		}) {
+			return SummarizeRuns(executions), nil
 		}
+		if pages == runSummaryMaxPages {
+			summary := SummarizeRuns(executions)
+			summary.ConsecutiveFailuresAtLeast = summary.ConsecutiveFailures > 0
+			return summary, nil
+		}
+		query.Cursor = page.NextCursor
 	}
-	slices.SortFunc(historyItems, func(l, r *pb.HandlerExecution) int {
-		return int(l.StartClientTimestamp.AsTime().Unix() - r.StartClientTimestamp.AsTime().Unix())
-	})
+}
 
-	if tail > 0 && len(historyItems) > int(tail) {
-		historyItems = historyItems[len(historyItems)-int(tail):]
+// SummarizeRuns summarizes history, oldest first as GetHistory returns it.
+// Runs skipped by a concurrency limit never ran, so they are ignored.
+func SummarizeRuns(history []*pb.HandlerExecution) RunSummary {
+	summary := RunSummary{}
+	for i := len(history) - 1; i >= 0; i-- {
+		execution := history[i]
+		if execution.GetError().GetCode() == ErrorCodeSkipped {
+			continue
+		}
+		if summary.LastResult == pb.HandlerRunResult_HANDLER_RUN_RESULT_NONE {
+			summary.LastDurationMs = execution.DurationMs
+			summary.LastError = execution.Error
+			summary.LastResult = pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS
+			if execution.Error != nil {
+				summary.LastResult = pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR
+			}
+		}
+		if execution.Error == nil {
+			break
+		}
+		summary.ConsecutiveFailures++
 	}
-
-	return historyItems, nil
+	return summary
 }
@@ -307,3 +307,72 @@ func createHandlerExecutions(startTimestamp time.Time, n int, increment time.Dur
 
 	return handlerExecutions
 }
+
+func TestSummarizeRuns(t *testing.T) {
+	require.Equal(t, RunSummary{}, SummarizeRuns(nil))
+
+	failed := &pb.Error{Code: ErrorCodeUnexpected, Message: "boom"}
+	history := []*pb.HandlerExecution{
+		{DurationMs: 10, Error: failed},
+		{DurationMs: 20},
+		{DurationMs: 30, Error: failed},
+		{DurationMs: 40, Error: failed},
+		{Error: &pb.Error{Code: ErrorCodeSkipped}},
+	}
+	summary := SummarizeRuns(history)
+	require.Equal(t, pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR, summary.LastResult)
+	require.Equal(t, int32(40), summary.LastDurationMs)
+	require.Equal(t, "boom", summary.LastError.Message)
+	require.Equal(t, int32(2), summary.ConsecutiveFailures)
+
+	summary = SummarizeRuns(history[:2])
+	require.Equal(t, pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS, summary.LastResult)
+	require.Equal(t, int32(20), summary.LastDurationMs)
+	require.Nil(t, summary.LastError)
+	require.Equal(t, int32(0), summary.ConsecutiveFailures)
+}
+
+func TestSummarizeHandlerRuns(t *testing.T) {
+	for backend, newConfig := range historyBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			logger, _ := zap.NewDevelopment()
+			hm := NewHistoryManager(newConfig(), logger)
+			require.NoError(t, hm.Start())
+			defer hm.Close()
+
+			summary, err := SummarizeHandlerRuns(context.Background(), hm, "HandlerName")
+			require.NoError(t, err)
+			require.Equal(t, RunSummary{}, summary)
+
+			// a success, then more failures than fit in a page
+			failures := runSummaryPageSize + 5
+			executions := createHandlerExecutions(time.Now().Add(-time.Hour), failures+2, time.Second)
+			for i, e := range executions[2:] {
+				e.Error = &pb.Error{Code: ErrorCodeUnexpected, Message: "boom"}
+				e.DurationMs = int32(i)
+			}
+			for _, e := range executions {
+				require.NoError(t, hm.Write(context.Background(), e))
+			}
+
+			summary, err = SummarizeHandlerRuns(context.Background(), hm, "HandlerName")
+			require.NoError(t, err)
+			require.Equal(t, pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR, summary.LastResult)
+			require.Equal(t, int32(failures-1), summary.LastDurationMs)
+			require.Equal(t, int32(failures), summary.ConsecutiveFailures)
+			require.False(t, summary.ConsecutiveFailuresAtLeast)
+
+			// failures are only counted back so far
+			more := createHandlerExecutions(time.Now().Add(-time.Minute), runSummaryPageSize*runSummaryMaxPages, time.Millisecond)
+			for _, e := range more {
+				e.InvocationId = "more-" + e.InvocationId
+				e.Error = &pb.Error{Code: ErrorCodeUnexpected, Message: "boom"}
+				require.NoError(t, hm.Write(context.Background(), e))
+			}
+			summary, err = SummarizeHandlerRuns(context.Background(), hm, "HandlerName")
+			require.NoError(t, err)
+			require.Equal(t, int32(runSummaryPageSize*runSummaryMaxPages), summary.ConsecutiveFailures)
+			require.True(t, summary.ConsecutiveFailuresAtLeast)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+)
+
+// RedactedValue replaces the values of args that are redacted in history.
+const RedactedValue = "[REDACTED]"
+
+// truncatedMarker ends a value in history that was cut short, with the number
+// of bytes left out.
+const truncatedMarker = "...[truncated %d bytes]"
+
+// capturePayloads prepares an execution's result and args to be written to
+// history, capping each at HandlerHistoryMaxPayloadBytes and hiding the args
+// HandlerHistoryRedactArgs names. The args are copied, as they are shared
+// with the invocation.
+func capturePayloads(cfg config.AgentConfig, execution *pb.HandlerExecution) {
+	maxBytes := cfg.HandlerHistoryMaxPayloadBytes
+	if maxBytes == 0 {
+		execution.Result = ""
+		execution.Args = nil
+		return
+	}
+
+	if result, truncated := truncatePayload(execution.Result, maxBytes); truncated {
+		execution.Result = result
+		execution.ResultTruncated = true
+	}
+
+	if len(execution.Args) == 0 {
+		return
+	}
+	args := make(map[string]string, len(execution.Args))
+	for name, value := range execution.Args {
+		if slices.ContainsFunc(cfg.HandlerHistoryRedactArgs, func(redacted string) bool {
+			return strings.EqualFold(redacted, name)
+		}) {
+			args[name] = RedactedValue
+			continue
+		}
+		args[name], _ = truncatePayload(value, maxBytes)
+	}
+	execution.Args = args
+}
+
+// truncatePayload cuts value to at most maxBytes, on a character boundary, and
+// appends a truncation marker.
+func truncatePayload(value string, maxBytes int) (string, bool) {
+	if len(value) <= maxBytes {
+		return value, false
+	}
+	n := maxBytes
+	for n > 0 && !utf8.RuneStart(value[n]) {
+		n--
+	}
+	return value[:n] + fmt.Sprintf(truncatedMarker, len(value)-n), true
+}
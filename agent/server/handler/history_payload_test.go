@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/proto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestHistoryPayloads(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	hm := NewHistoryManager(config.AgentConfig{
+		HandlerHistoryPath:            t.TempDir(),
+		HandlerHistoryMaxPayloadBytes: 10,
+		HandlerHistoryRedactArgs:      []string{"Body"},
+	}, logger)
+
+	args := map[string]string{
+		"body":   `{"token":"secret"}`,
+		"method": "POST",
+		"url":    "https://example.com/webhooks/1",
+	}
+	req := &pb.ReportInvocationRequest{
+		HandlerInvoke: &pb.DispatchHandlerInvoke{
+			HandlerName:  "handler1",
+			InvocationId: "invocation1",
+			Reason:       pb.HandlerInvokeType_WEBHOOK,
+			Args:         args,
+		},
+		StartClientTimestamp: timestamppb.Now(),
+		Message: &pb.ReportInvocationRequest_Result{
+			Result: &pb.InvokeResult{Value: "0123456789abcdef"},
+		},
+	}
+	require.NoError(t, hm.Write(context.Background(), proto.ReportToExecution(req, time.Now())))
+
+	history, err := hm.GetHistory(context.Background(), "handler1", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	execution := history[0]
+	require.Equal(t, pb.HandlerInvokeType_WEBHOOK, execution.Reason)
+	require.Equal(t, "0123456789...[truncated 6 bytes]", execution.Result)
+	require.True(t, execution.ResultTruncated)
+	require.Equal(t, map[string]string{
+		"body":   RedactedValue,
+		"method": "POST",
+		"url":    "https://ex...[truncated 20 bytes]",
+	}, execution.Args)
+
+	// the invocation's args are left as they were
+	require.Equal(t, `{"token":"secret"}`, args["body"])
+}
+
+func TestCapturePayloads_Disabled(t *testing.T) {
+	execution := &pb.HandlerExecution{
+		Result: "result",
+		Args:   map[string]string{"body": "body"},
+	}
+	capturePayloads(config.AgentConfig{}, execution)
+	require.Empty(t, execution.Result)
+	require.Nil(t, execution.Args)
+}
+
+func TestTruncatePayload(t *testing.T) {
+	value, truncated := truncatePayload("short", 10)
+	require.Equal(t, "short", value)
+	require.False(t, truncated)
+
+	// a multi-byte character is not split
+	value, truncated = truncatePayload("ab"+strings.Repeat("é", 3), 3)
+	require.Equal(t, "ab...[truncated 6 bytes]", value)
+	require.True(t, truncated)
+}
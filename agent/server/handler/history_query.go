@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+)
+
+// ErrInvalidCursor is returned when a history query's cursor wasn't returned
+// by an earlier query.
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// HistoryQuery selects a handler's executions.
+type HistoryQuery struct {
+	HandlerName string
+	// Start and End, when set, select executions that started at or after
+	// Start and before End.
+	Start time.Time
+	End   time.Time
+	// Tail, when set, returns only the most recent Tail matching executions,
+	// and a cursor to get the ones before them.
+	Tail   int32
+	Cursor string
+	// Result, when set, selects only successful or only failed executions.
+	Result pb.HandlerRunResult
+	// Reasons, when set, selects only executions invoked for one of them, and
+	// ExcludeErrorCodes leaves out those that failed with one of its codes.
+	Reasons           []pb.HandlerInvokeType
+	ExcludeErrorCodes []string
+	DispatchId        string
+	InvocationId      string
+	IncludeLogs       bool
+}
+
+// HistoryPage is the result of a HistoryQuery, oldest first.
+type HistoryPage struct {
+	Executions []*pb.HandlerExecution
+	// NextCursor gets the page before this one, and is empty when there are
+	// no earlier executions.
+	NextCursor string
+}
+
+func (q HistoryQuery) matches(execution *pb.HandlerExecution) bool {
+	switch q.Result {
+	case pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS:
+		if execution.Error != nil {
+			return false
+		}
+	case pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR:
+		if execution.Error == nil {
+			return false
+		}
+	}
+	if len(q.Reasons) > 0 && !slices.Contains(q.Reasons, execution.Reason) {
+		return false
+	}
+	if execution.Error != nil && slices.Contains(q.ExcludeErrorCodes, execution.Error.Code) {
+		return false
+	}
+	if q.DispatchId != "" && execution.DispatchId != q.DispatchId {
+		return false
+	}
+	if q.InvocationId != "" && execution.InvocationId != q.InvocationId {
+		return false
+	}
+	return true
+}
+
+// historyCandidate is an execution a backend has stored, with what it knows
+// about it without reading it. Candidates are ordered by start, then key,
+// which must be unique for the handler.
+type historyCandidate struct {
+	start time.Time
+	key   string
+	// read returns the execution, or nil if it can't be read and should be
+	// skipped
+	read func() (*pb.HandlerExecution, error)
+}
+
+func compareHistoryCandidates(l, r historyCandidate) int {
+	if c := l.start.Compare(r.start); c != 0 {
+		return c
+	}
+	return strings.Compare(l.key, r.key)
+}
+
+// historyCursor is the position of the oldest execution in a page.
+func historyCursor(c historyCandidate) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%s", c.start.UnixNano(), c.key))
+}
+
+func parseHistoryCursor(cursor string) (historyCandidate, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return historyCandidate{}, ErrInvalidCursor
+	}
+	start, key, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return historyCandidate{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return historyCandidate{}, ErrInvalidCursor
+	}
+	return historyCandidate{start: time.Unix(0, nanos), key: key}, nil
+}
+
+// queryHistory reads the candidates the query selects, newest first, so a
+// page reads only the executions it returns and those its filters skip.
+func queryHistory(candidates []historyCandidate, query HistoryQuery) (*HistoryPage, error) {
+	var before *historyCandidate
+	if query.Cursor != "" {
+		cursor, err := parseHistoryCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		before = &cursor
+	}
+
+	slices.SortFunc(candidates, compareHistoryCandidates)
+
+	page := &HistoryPage{
+		Executions: make([]*pb.HandlerExecution, 0, 16),
+	}
+	var oldest historyCandidate
+	for i := len(candidates) - 1; i >= 0; i-- {
+		candidate := candidates[i]
+		if before != nil && compareHistoryCandidates(candidate, *before) >= 0 {
+			continue
+		}
+		if !query.End.IsZero() && !candidate.start.Before(query.End) {
+			continue
+		}
+		if !query.Start.IsZero() && candidate.start.Before(query.Start) {
+			break
+		}
+
+		execution, err := candidate.read()
+		if err != nil {
+			return nil, err
+		}
+		if execution == nil || !query.matches(execution) {
+			continue
+		}
+		if query.Tail > 0 && len(page.Executions) == int(query.Tail) {
+			page.NextCursor = historyCursor(oldest)
+			break
+		}
+		if !query.IncludeLogs {
+			execution.Logs = nil
+		}
+		page.Executions = append(page.Executions, execution)
+		oldest = candidate
+	}
+
+	slices.Reverse(page.Executions)
+	return page, nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func historyBackends(t *testing.T) map[string]func() config.AgentConfig {
+	return map[string]func() config.AgentConfig{
+		config.HistoryBackendFile: func() config.AgentConfig {
+			return config.AgentConfig{HandlerHistoryPath: t.TempDir()}
+		},
+		config.HistoryBackendEmbedded: func() config.AgentConfig {
+			return embeddedHistoryConfig(t)
+		},
+	}
+}
+
+func TestQueryHistory(t *testing.T) {
+	for backend, newConfig := range historyBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			logger, _ := zap.NewDevelopment()
+			hm := NewHistoryManager(newConfig(), logger)
+			require.NoError(t, hm.Start())
+			defer hm.Close()
+
+			startTime := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+			executions := createHandlerExecutions(startTime, 10, time.Second)
+			for i, e := range executions {
+				if i%3 == 0 {
+					e.Error = &pb.Error{Code: ErrorCodeUnexpected}
+				}
+				if i == 4 {
+					e.DispatchId = "other"
+				}
+				require.NoError(t, hm.Write(context.Background(), e))
+			}
+
+			query := func(q HistoryQuery) []string {
+				q.HandlerName = "HandlerName"
+				page, err := hm.Query(context.Background(), q)
+				require.NoError(t, err)
+				ids := make([]string, 0, len(page.Executions))
+				for _, e := range page.Executions {
+					ids = append(ids, e.InvocationId)
+				}
+				return ids
+			}
+
+			require.Equal(t,
+				[]string{"InvocationId-2", "InvocationId-3", "InvocationId-4"},
+				query(HistoryQuery{Start: startTime.Add(2 * time.Second), End: startTime.Add(5 * time.Second)}),
+			)
+			require.Equal(t,
+				[]string{"InvocationId-0", "InvocationId-3", "InvocationId-6", "InvocationId-9"},
+				query(HistoryQuery{Result: pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR}),
+			)
+			require.Equal(t,
+				[]string{"InvocationId-7", "InvocationId-8"},
+				query(HistoryQuery{Result: pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS, Tail: 2}),
+			)
+			require.Equal(t, []string{"InvocationId-4"}, query(HistoryQuery{DispatchId: "other"}))
+			require.Equal(t, []string{"InvocationId-5"}, query(HistoryQuery{InvocationId: "InvocationId-5"}))
+		})
+	}
+}
+
+func TestQueryHistory_Pagination(t *testing.T) {
+	for backend, newConfig := range historyBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			logger, _ := zap.NewDevelopment()
+			hm := NewHistoryManager(newConfig(), logger)
+			require.NoError(t, hm.Start())
+			defer hm.Close()
+
+			executions := createHandlerExecutions(time.Now().Add(-time.Hour), 10, time.Second)
+			for i, e := range executions {
+				if i%2 == 1 {
+					e.Error = &pb.Error{Code: ErrorCodeUnexpected}
+				}
+				require.NoError(t, hm.Write(context.Background(), e))
+			}
+
+			// pages go back from the most recent failure, each oldest first
+			pages := [][]string{}
+			query := HistoryQuery{
+				HandlerName: "HandlerName",
+				Result:      pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR,
+				Tail:        2,
+			}
+			for {
+				page, err := hm.Query(context.Background(), query)
+				require.NoError(t, err)
+				ids := []string{}
+				for _, e := range page.Executions {
+					ids = append(ids, e.InvocationId)
+				}
+				pages = append(pages, ids)
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			require.Equal(t, [][]string{
+				{"InvocationId-7", "InvocationId-9"},
+				{"InvocationId-3", "InvocationId-5"},
+				{"InvocationId-1"},
+			}, pages)
+
+			query.Cursor = "not a cursor"
+			_, err := hm.Query(context.Background(), query)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
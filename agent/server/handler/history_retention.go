@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+)
+
+// historyRetention holds the retention limits of each handler, from the
+// HandlerRetentionOption it registered with and the agent config, which
+// overrides it.
+type historyRetention struct {
+	minCount   int
+	configured map[string]config.HistoryRetention
+
+	mu         sync.RWMutex
+	registered map[string]config.HistoryRetention
+}
+
+func newHistoryRetention(cfg config.AgentConfig) *historyRetention {
+	return &historyRetention{
+		minCount:   cfg.HandlerHistoryMinCount,
+		configured: cfg.HandlerHistoryRetention,
+		registered: map[string]config.HistoryRetention{},
+	}
+}
+
+// SetRetention sets the retention limits a handler registered with.
+func (r *historyRetention) SetRetention(handlerName string, option *pb.HandlerRetentionOption) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registered[handlerName] = config.HistoryRetention{
+		MaxAge:   time.Duration(option.MaxAgeMs) * time.Millisecond,
+		MaxCount: int(option.MaxCount),
+		MaxBytes: option.MaxBytes,
+		MinCount: int(option.MinCount),
+	}
+}
+
+// limits returns a handler's retention limits.
+func (r *historyRetention) limits(handlerName string) config.HistoryRetention {
+	r.mu.RLock()
+	limits := r.registered[handlerName]
+	r.mu.RUnlock()
+
+	if configured, ok := r.configured[handlerName]; ok {
+		if configured.MaxAge > 0 {
+			limits.MaxAge = configured.MaxAge
+		}
+		if configured.MaxCount > 0 {
+			limits.MaxCount = configured.MaxCount
+		}
+		if configured.MaxBytes > 0 {
+			limits.MaxBytes = configured.MaxBytes
+		}
+		if configured.MinCount > 0 {
+			limits.MinCount = configured.MinCount
+		}
+	}
+	if limits.MinCount == 0 {
+		limits.MinCount = r.minCount
+	}
+	return limits
+}
+
+// storedExecution is an execution in history as cleanup sees it. handlerName
+// is empty for files that aren't executions.
+type storedExecution struct {
+	handlerName string
+	start       time.Time
+	size        int64
+}
+
+// expired returns which executions cleanup removes: those that started
+// before their handler's max age, or minTimestamp if it has none, and those
+// beyond its max count or max bytes, then the oldest that don't fit in
+// maxSizeBytes across all handlers. The min count most recent executions of
+// each handler are always kept.
+func (r *historyRetention) expired(executions []storedExecution, minTimestamp time.Time, maxSizeBytes int64, now time.Time) []bool {
+	// newest first
+	order := make([]int, len(executions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return executions[order[i]].start.After(executions[order[j]].start)
+	})
+
+	expired := make([]bool, len(executions))
+	kept := make([]bool, len(executions))
+	counts := map[string]int{}
+	sizes := map[string]int64{}
+	limits := map[string]config.HistoryRetention{}
+	for _, i := range order {
+		execution := executions[i]
+		name := execution.handlerName
+		limit, ok := limits[name]
+		if !ok {
+			// files that aren't executions have no limits of their own, not
+			// even a min count, so only age and size remove them
+			if name != "" {
+				limit = r.limits(name)
+			}
+			limits[name] = limit
+		}
+
+		rank := counts[name]
+		counts[name]++
+		if rank < limit.MinCount {
+			kept[i] = true
+			continue
+		}
+
+		oldest := minTimestamp
+		if limit.MaxAge > 0 {
+			oldest = now.Add(-limit.MaxAge)
+		}
+		if execution.start.Before(oldest) {
+			expired[i] = true
+			continue
+		}
+		sizes[name] += execution.size
+		if limit.MaxCount > 0 && rank >= limit.MaxCount {
+			expired[i] = true
+		}
+		if limit.MaxBytes > 0 && sizes[name] > limit.MaxBytes {
+			expired[i] = true
+		}
+	}
+
+	remainingSize := maxSizeBytes
+	for _, i := range order {
+		if expired[i] {
+			continue
+		}
+		remainingSize -= executions[i].size
+		if remainingSize < 0 && !kept[i] {
+			expired[i] = true
+		}
+	}
+	return expired
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHistoryRetention(t *testing.T) {
+	now := time.Now()
+	retention := newHistoryRetention(config.AgentConfig{
+		HandlerHistoryMinCount: 2,
+		HandlerHistoryRetention: map[string]config.HistoryRetention{
+			"daily": {MinCount: 3},
+		},
+	})
+	retention.SetRetention("chatty", &pb.HandlerRetentionOption{MaxCount: 5})
+	retention.SetRetention("daily", &pb.HandlerRetentionOption{MaxAgeMs: (24 * time.Hour).Milliseconds(), MinCount: 1})
+
+	executions := []storedExecution{}
+	add := func(handlerName string, n int, interval time.Duration) {
+		for i := 0; i < n; i++ {
+			executions = append(executions, storedExecution{
+				handlerName: handlerName,
+				start:       now.Add(-time.Duration(i) * interval),
+				size:        10,
+			})
+		}
+	}
+	add("chatty", 20, time.Minute)
+	add("daily", 5, 24*time.Hour)
+	add("other", 4, time.Hour)
+	add("", 3, time.Hour)
+
+	remaining := func(expired []bool) map[string]int {
+		counts := map[string]int{}
+		for i, e := range expired {
+			if !e {
+				counts[executions[i].handlerName]++
+			}
+		}
+		return counts
+	}
+
+	// chatty keeps its 5 most recent, daily its 3 as the config overrides its
+	// min count, and other is only limited by the agent's max age, as are
+	// files that aren't executions
+	expired := retention.expired(executions, now.Add(-150*time.Minute), 1<<30, now)
+	require.Equal(t, map[string]int{"chatty": 5, "daily": 3, "other": 3, "": 3}, remaining(expired))
+
+	// files that aren't executions don't get the min count
+	expired = retention.expired(executions, now.Add(-30*time.Minute), 1<<30, now)
+	require.Equal(t, 1, remaining(expired)[""])
+
+	// the most recent of each handler are kept when the size limit is hit
+	expired = retention.expired(executions, now.Add(-time.Hour*24*365), 0, now)
+	require.Equal(t, map[string]int{"chatty": 2, "daily": 3, "other": 2}, remaining(expired))
+}
+
+func TestRegisterHandlerRetention(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger).(*historyManager)
+	mgr := NewHandlerManager(logger, cron.New(), nil, WithHistoryManager(history))
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout, &pb.HandlerOption{
+		Option: &pb.HandlerOption_Retention{
+			Retention: &pb.HandlerRetentionOption{MaxCount: 7, MaxBytes: 1000},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, config.HistoryRetention{MaxCount: 7, MaxBytes: 1000}, history.limits("handler1"))
+}
+
+func TestCompressHistory(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	historyPath := t.TempDir()
+	hm := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: historyPath}, logger).(*historyManager)
+
+	executions := createHandlerExecutions(time.Now().Add(-time.Hour), 5, time.Second)
+	for _, e := range executions {
+		require.NoError(t, hm.Write(context.Background(), e))
+	}
+	files, err := os.ReadDir(historyPath)
+	require.NoError(t, err)
+	info, err := files[0].Info()
+	require.NoError(t, err)
+
+	compressed, err := hm.compressDirectory(historyPath, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 5, compressed)
+
+	files, err = os.ReadDir(historyPath)
+	require.NoError(t, err)
+	require.Len(t, files, 5)
+	for _, file := range files {
+		require.True(t, strings.HasSuffix(file.Name(), ".json.gz"), file.Name())
+	}
+	compressedInfo, err := os.Stat(filepath.Join(historyPath, info.Name()+compressedSuffix))
+	require.NoError(t, err)
+	require.Equal(t, info.ModTime(), compressedInfo.ModTime())
+
+	history, err := hm.GetHistory(context.Background(), "HandlerName", false, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	for i, e := range executions {
+		require.Equal(t, e.InvocationId, history[i].InvocationId)
+	}
+
+	// compressed files are cleaned up by their handler's limits too
+	hm.SetRetention("HandlerName", &pb.HandlerRetentionOption{MaxCount: 2})
+	deleted, err := hm.cleanupDirectory(historyPath, time.Time{}, 1<<30, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, deleted)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+)
+
+// ErrorCodeCancelled is the error code of an invocation cancelled before it
+// completed.
+const ErrorCodeCancelled = "cancelled"
+
+// recentInvocationsLimit is how many completed invocations the manager
+// remembers for Invocations.
+const recentInvocationsLimit = 100
+
+// InvocationStatus describes an invocation that is queued or running, or that
+// recently completed. DispatchedAt is when its latest attempt was sent to a
+// client, and is zero if none was.
+type InvocationStatus struct {
+	Invocation   *pb.DispatchHandlerInvoke
+	QueuedAt     time.Time
+	DispatchedAt time.Time
+	CompletedAt  time.Time
+	Err          error
+}
+
+// Completed returns whether the invocation has completed.
+func (s InvocationStatus) Completed() bool {
+	return !s.CompletedAt.IsZero()
+}
+
+// trackedInvocation is an invocation the manager has queued for dispatch and
+// that hasn't completed.
+type trackedInvocation struct {
+	invoke       Invocable
+	queuedAt     time.Time
+	dispatchedAt time.Time
+}
+
+// track starts tracking an invocation as queued. An invocation held by a
+// concurrency limit is tracked when it is held, and keeps its queued time
+// when it is dispatched.
+func (s *handlerManager) track(invoke Invocable) {
+	msg := invoke.ToDispatchInvoke()
+	s.invocationsLock.Lock()
+	_, tracked := s.invocations[msg.InvocationId]
+	if !tracked {
+		s.invocations[msg.InvocationId] = &trackedInvocation{
+			invoke:   invoke,
+			queuedAt: time.Now(),
+		}
+	}
+	s.invocationsLock.Unlock()
+	if !tracked {
+		s.PublishExecutionEvent(NewExecutionEvent(pb.HandlerExecutionEventType_EXECUTION_EVENT_QUEUED, msg))
+	}
+}
+
+func (s *handlerManager) untrack(invoke Invocable) {
+	msg := invoke.ToDispatchInvoke()
+	_, err := invoke.GetResult()
+
+	s.invocationsLock.Lock()
+	defer s.invocationsLock.Unlock()
+	tracked, ok := s.invocations[msg.InvocationId]
+	if !ok {
+		return
+	}
+	delete(s.invocations, msg.InvocationId)
+
+	s.recentInvocations = append(s.recentInvocations, InvocationStatus{
+		Invocation:   msg,
+		QueuedAt:     tracked.queuedAt,
+		DispatchedAt: tracked.dispatchedAt,
+		CompletedAt:  time.Now(),
+		Err:          err,
+	})
+	if len(s.recentInvocations) > recentInvocationsLimit {
+		s.recentInvocations = s.recentInvocations[len(s.recentInvocations)-recentInvocationsLimit:]
+	}
+}
+
+// Invocations returns the invocations that are queued or running, oldest
+// first, followed by those that recently completed, most recent first.
+func (s *handlerManager) Invocations() []InvocationStatus {
+	s.invocationsLock.Lock()
+	defer s.invocationsLock.Unlock()
+
+	active := make([]InvocationStatus, 0, len(s.invocations))
+	for _, tracked := range s.invocations {
+		active = append(active, InvocationStatus{
+			Invocation:   tracked.invoke.ToDispatchInvoke(),
+			QueuedAt:     tracked.queuedAt,
+			DispatchedAt: tracked.dispatchedAt,
+		})
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].QueuedAt.Before(active[j].QueuedAt)
+	})
+
+	for i := len(s.recentInvocations) - 1; i >= 0; i-- {
+		active = append(active, s.recentInvocations[i])
+	}
+	return active
+}
+
+// markDispatched records that an attempt of an invocation was sent to a client.
+func (s *handlerManager) markDispatched(attempt Invocable) {
+	s.invocationsLock.Lock()
+	defer s.invocationsLock.Unlock()
+	if tracked, ok := s.invocations[attempt.ToDispatchInvoke().OriginalInvocationId]; ok {
+		tracked.dispatchedAt = time.Now()
+	}
+}
+
+// Cancel completes a queued or running invocation with the error code
+// cancelled, which also stops any retries of it. An invocation that was
+// never sent to a client is recorded in history as cancelled; a running one
+// is recorded when its client is told to stop. It returns os.ErrNotExist if
+// no such invocation is queued or running.
+func (s *handlerManager) Cancel(invocationId string) error {
+	s.invocationsLock.Lock()
+	tracked, ok := s.invocations[invocationId]
+	dispatched := ok && !tracked.dispatchedAt.IsZero()
+	s.invocationsLock.Unlock()
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	msg := tracked.invoke.ToDispatchInvoke()
+	err := &InvocationError{
+		Code:    ErrorCodeCancelled,
+		Message: "invocation was cancelled",
+	}
+	if tracked.invoke.Complete("", err) != nil {
+		// it completed in the meantime
+		return nil
+	}
+
+	logger := s.invokeLogger(tracked.invoke.GetEntry(), tracked.invoke)
+	logger.Info("Cancelled invocation", zap.String("invocation-id", invocationId))
+	if !dispatched {
+		s.recordUndispatched(msg, err, logger)
+	}
+	if s.unhold(tracked.invoke) {
+		// a held invocation was never dispatched, so nothing else will
+		// untrack it
+		s.untrack(tracked.invoke)
+		if s.queue != nil && isDurableReason(tracked.invoke.GetReason()) {
+			s.queue.Complete(tracked.invoke, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCancelQueuedInvocation(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	mgr := NewHandlerManager(logger, cron.New(), nil, WithHistoryManager(history))
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	cancelled := NewInvokeHandlerInvoke(entry, "cancelled")
+	require.NoError(t, mgr.Trigger(cancelled))
+	kept := NewInvokeHandlerInvoke(entry, "kept")
+	require.NoError(t, mgr.Trigger(kept))
+
+	id := cancelled.ToDispatchInvoke().InvocationId
+	require.NoError(t, mgr.Cancel(id))
+	_, err = cancelled.GetResult()
+	require.Equal(t, ErrorCodeCancelled, ErrorCode(err))
+
+	// the cancelled invocation is never sent
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, kept, dequeued)
+
+	executions, err := history.GetHistory(context.Background(), "handler1", false, 0)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	require.Equal(t, id, executions[0].InvocationId)
+	require.Equal(t, ErrorCodeCancelled, executions[0].Error.Code)
+
+	require.Eventually(t, func() bool {
+		return errors.Is(mgr.Cancel(id), os.ErrNotExist)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCancelStopsRetries(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	mgr := NewHandlerManager(logger, cron.New(), nil, WithHistoryManager(history))
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 3, InitialBackoffMs: 1}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	invoke := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(invoke))
+	first, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+
+	require.NoError(t, mgr.Cancel(invoke.ToDispatchInvoke().InvocationId))
+	_, err = invoke.GetResult()
+	require.Equal(t, ErrorCodeCancelled, ErrorCode(err))
+
+	// the attempt that was sent is recorded when its client reports it
+	first.Complete("", &InvocationError{Code: ErrorCodeCancelled})
+	next, err := mgr.Dequeue(context.Background(), "1", 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	executions, err := history.GetHistory(context.Background(), "handler1", false, 0)
+	require.NoError(t, err)
+	require.Empty(t, executions)
+}
+
+func TestInvocations(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(first))
+	second := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(second))
+
+	dequeued, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.Same(t, first, dequeued)
+
+	statuses := mgr.Invocations()
+	require.Len(t, statuses, 2)
+	require.Equal(t, first.ToDispatchInvoke().InvocationId, statuses[0].Invocation.InvocationId)
+	require.False(t, statuses[0].DispatchedAt.IsZero())
+	require.Equal(t, second.ToDispatchInvoke().InvocationId, statuses[1].Invocation.InvocationId)
+	require.True(t, statuses[1].DispatchedAt.IsZero())
+
+	require.NoError(t, first.Complete("", &InvocationError{Code: "boom"}))
+	require.Eventually(t, func() bool {
+		statuses = mgr.Invocations()
+		return len(statuses) == 2 && statuses[1].Completed()
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, second.ToDispatchInvoke().InvocationId, statuses[0].Invocation.InvocationId)
+	require.False(t, statuses[0].Completed())
+	require.Equal(t, first.ToDispatchInvoke().InvocationId, statuses[1].Invocation.InvocationId)
+	require.Equal(t, "boom", ErrorCode(statuses[1].Err))
+}
@@ -46,6 +46,7 @@ func NewInvokeHandlerEntry(
 			dispatchId,
 			name,
 			timeout,
+			options...,
 		),
 		logger:  logger,
 		handler: handler,
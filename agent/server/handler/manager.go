@@ -2,12 +2,17 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
 	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/leader"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -21,14 +26,24 @@ type Manager interface {
 	Stop(id string) error
 	Trigger(handler Invocable) error
 	GetByTag(tag string) HandlerEntry
+	ListByTag(tag string) []HandlerEntry
 	Dequeue(ctx context.Context, id string, waitTime time.Duration) (Invocable, error)
+	JoinWorkerPool(dispatchId string, name string, strategy pb.WorkerPoolStrategy)
+	Cancel(invocationId string) error
+	Invocations() []InvocationStatus
+	Drain(ctx context.Context) DrainSummary
+	WatchExecutions(handlerName string) (<-chan *pb.HandlerExecutionEvent, func())
+	PublishExecutionEvent(event *pb.HandlerExecutionEvent)
 	Close() error
 	IsFinished() bool
 }
 
 type handlerManager struct {
 	logger              *zap.Logger
-	dispatchQueues      map[string]chan Invocable
+	dispatchQueues      map[string]*dispatchQueue
+	queuesLock          sync.Mutex
+	queueCapacity       int
+	queueOverflow       string
 	outstandingRequests map[string]Invocable
 	handlers            map[string]HandlerEntry
 	cron                cron.Cron
@@ -37,16 +52,79 @@ type handlerManager struct {
 	invokeCounter       *prometheus.CounterVec
 	queueDepthGauge     *prometheus.GaugeVec
 	handlerLatencyGauge *prometheus.HistogramVec
+	queue               *DurableQueue
+	history             HistoryManager
+	elector             leader.Elector
+	slotsLock           sync.Mutex
+	slots               map[string]*handlerSlots
+	poolsLock           sync.Mutex
+	pools               map[string]*workerPool
+	poolMembers         map[string]*workerPool
+	invocationsLock     sync.Mutex
+	invocations         map[string]*trackedInvocation
+	recentInvocations   []InvocationStatus
+	draining            atomic.Bool
+	drainStarted        chan struct{}
+	drained             atomic.Bool
+	watchersLock        sync.Mutex
+	watchers            map[*executionWatcher]struct{}
 }
 
-func NewHandlerManager(logger *zap.Logger, cron cron.Cron, metrics *prometheus.Registry) Manager {
+type ManagerOption func(*handlerManager)
+
+// WithHistoryManager records invocations the manager completes itself, such as
+// triggers skipped by a concurrency limit, in history.
+func WithHistoryManager(history HistoryManager) ManagerOption {
+	return func(m *handlerManager) {
+		m.history = history
+	}
+}
+
+// WithLeaderElector only triggers RUN_INTERVAL and CRON_SCHEDULE handlers
+// while elector says this agent is the leader.
+func WithLeaderElector(elector leader.Elector) ManagerOption {
+	return func(m *handlerManager) {
+		m.elector = elector
+	}
+}
+
+// WithDispatchQueue bounds each client's queue of invocations waiting to be
+// dispatched at capacity. Once it is full, overflow decides whether triggers
+// are rejected with ErrDispatchQueueFull or the oldest waiting invocation of
+// the same or a lower priority is dropped.
+func WithDispatchQueue(capacity int, overflow string) ManagerOption {
+	return func(m *handlerManager) {
+		m.queueCapacity = capacity
+		if overflow != "" {
+			m.queueOverflow = overflow
+		}
+	}
+}
+
+// WithDurableQueue persists webhook and manual invocations in queue so they
+// survive a client disconnect or an agent restart.
+func WithDurableQueue(queue *DurableQueue) ManagerOption {
+	return func(m *handlerManager) {
+		m.queue = queue
+	}
+}
+
+func NewHandlerManager(logger *zap.Logger, cron cron.Cron, metrics *prometheus.Registry, opts ...ManagerOption) Manager {
 
 	mgr := &handlerManager{
 		logger:              logger.Named("handler-manager"),
 		handlers:            make(map[string]HandlerEntry),
-		dispatchQueues:      make(map[string]chan Invocable),
+		dispatchQueues:      make(map[string]*dispatchQueue),
+		queueCapacity:       defaultDispatchQueueCapacity,
+		queueOverflow:       QueueOverflowReject,
 		cron:                cron,
 		outstandingRequests: make(map[string]Invocable),
+		slots:               make(map[string]*handlerSlots),
+		pools:               make(map[string]*workerPool),
+		poolMembers:         make(map[string]*workerPool),
+		invocations:         make(map[string]*trackedInvocation),
+		watchers:            make(map[*executionWatcher]struct{}),
+		drainStarted:        make(chan struct{}),
 		invokeCounter: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "axon_handler_invokes",
@@ -57,9 +135,9 @@ func NewHandlerManager(logger *zap.Logger, cron cron.Cron, metrics *prometheus.R
 		queueDepthGauge: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "axon_handler_queue_depth",
-				Help: "Number of items in the handler queue",
+				Help: "Number of invocations of the handler waiting to be dispatched, by priority",
 			},
-			[]string{"handler"},
+			[]string{"handler", "priority"},
 		),
 		handlerLatencyGauge: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -71,6 +149,9 @@ func NewHandlerManager(logger *zap.Logger, cron cron.Cron, metrics *prometheus.R
 		),
 		done: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(mgr)
+	}
 	if metrics != nil {
 		metrics.MustRegister(mgr.invokeCounter)
 		metrics.MustRegister(mgr.queueDepthGauge)
@@ -84,6 +165,9 @@ func (s *handlerManager) Close() error {
 		close(s.done)
 		s.done = nil
 	}
+	if s.queue != nil {
+		return s.queue.Close()
+	}
 	return nil
 }
 
@@ -106,6 +190,8 @@ func (s *handlerManager) RegisterHandler(dispatchId string, name string, timeout
 		panic("handler manager has been closed")
 	}
 
+	dispatchId = s.entryDispatchId(dispatchId)
+
 	for _, entry := range s.handlers {
 		if entry.DispatchId() == dispatchId && entry.Name() == name {
 			// ignore re-registering the same handler
@@ -113,11 +199,23 @@ func (s *handlerManager) RegisterHandler(dispatchId string, name string, timeout
 		}
 	}
 
+	if err := s.checkDependencyCycle(name, options); err != nil {
+		return "", err
+	}
+
 	entry := s.createEntry(dispatchId, name, timeout, options...)
 	if entry == nil {
 		return "", fmt.Errorf("handler type not supported: %s", name)
 	}
 	s.handlers[entry.Id()] = entry
+
+	if s.history != nil {
+		for _, option := range options {
+			if retention := option.GetRetention(); retention != nil {
+				s.history.SetRetention(name, retention)
+			}
+		}
+	}
 	return entry.Id(), nil
 }
 
@@ -134,7 +232,7 @@ func (s *handlerManager) createEntry(
 		}
 		switch invoke.Type {
 		case pb.HandlerInvokeType_RUN_NOW, pb.HandlerInvokeType_CRON_SCHEDULE, pb.HandlerInvokeType_RUN_INTERVAL:
-			return newScheduledHandlerEntry(s, s.logger, dispatchId, name, timeout, s.cron, options...)
+			return newScheduledHandlerEntry(s, s.logger, dispatchId, name, timeout, s.cron, s.history, s.elector, options...)
 
 		case pb.HandlerInvokeType_WEBHOOK:
 			return NewWebhookHandlerEntry(s, s.logger, dispatchId, name, timeout, options...)
@@ -143,7 +241,9 @@ func (s *handlerManager) createEntry(
 		}
 	}
 
-	if len(options) == 0 {
+	// handlers with no invoke option (including those with only options such
+	// as a retry policy) are invoked on demand
+	if !hasInvokeOption(options) {
 		return NewInvokeHandlerEntry(s, s.logger, dispatchId, name, timeout, options...)
 	}
 
@@ -151,6 +251,15 @@ func (s *handlerManager) createEntry(
 	return nil
 }
 
+func hasInvokeOption(options []*pb.HandlerOption) bool {
+	for _, option := range options {
+		if option.GetInvoke() != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *handlerManager) UnregisterHandler(id string) {
 	entry, ok := s.handlers[id]
 	if !ok {
@@ -168,6 +277,11 @@ func (s *handlerManager) ClearHandlers(id string) {
 }
 
 func (s *handlerManager) Start(dispatchId string) error {
+	member := dispatchId
+	pool := s.poolOf(member)
+	if pool != nil {
+		dispatchId = pool.dispatchId()
+	}
 	for _, entry := range s.handlers {
 		if entry.DispatchId() == dispatchId {
 			err := entry.Start()
@@ -176,10 +290,48 @@ func (s *handlerManager) Start(dispatchId string) error {
 			}
 		}
 	}
+	if pool != nil {
+		s.connect(pool, member)
+	}
+	s.replay(dispatchId)
 	return nil
 }
 
+// replay re-triggers pending invocations from the durable queue whose handler
+// is active under dispatchId, or under any dispatch session if it is empty.
+func (s *handlerManager) replay(dispatchId string) {
+	if s.queue == nil {
+		return
+	}
+	for _, record := range s.queue.Replayable() {
+		for _, entry := range s.handlers {
+			if entry.Name() != record.HandlerName || !entry.IsActive() {
+				continue
+			}
+			if dispatchId != "" && entry.DispatchId() != dispatchId {
+				continue
+			}
+			s.logger.Info("Replaying queued invocation",
+				zap.String("handler", record.HandlerName),
+				zap.String("invocation-id", record.Id),
+				zap.String("reason", record.Reason),
+			)
+			if err := s.Trigger(record.toInvoke(entry)); err != nil {
+				s.logger.Error("Failed to replay invocation", zap.String("invocation-id", record.Id), zap.Error(err))
+			}
+			break
+		}
+	}
+}
+
 func (s *handlerManager) Stop(dispatchId string) error {
+	if pool := s.poolOf(dispatchId); pool != nil {
+		if s.disconnect(pool, dispatchId) {
+			// the pool's handlers run while any member is connected
+			return nil
+		}
+		dispatchId = pool.dispatchId()
+	}
 	for _, entry := range s.handlers {
 		if entry.DispatchId() == dispatchId {
 			entry.Close()
@@ -195,14 +347,27 @@ func (s *handlerManager) removeHandler(entry HandlerEntry) {
 
 func (s *handlerManager) Dequeue(ctx context.Context, dispatchId string, waitTime time.Duration) (Invocable, error) {
 	queue := s.getDispatchQueue(dispatchId)
-	select {
-	case <-time.After(waitTime):
-		s.checkFinished()
-		return nil, nil
-	case response := <-queue:
+	for {
+		response := queue.pop(ctx, waitTime)
+		if response == nil {
+			s.checkFinished()
+			return nil, nil
+		}
+		// skip invocations that finished while queued, because they were
+		// cancelled or expired, so they aren't sent after their result
+		select {
+		case <-response.Done():
+			continue
+		default:
+		}
+		if s.queue != nil {
+			s.queue.Dispatched(response)
+		}
+		s.markDispatched(response)
+		event := NewExecutionEvent(pb.HandlerExecutionEventType_EXECUTION_EVENT_DISPATCHED, response.ToDispatchInvoke())
+		event.DispatchId = dispatchId
+		s.PublishExecutionEvent(event)
 		return response, nil
-	case <-ctx.Done():
-		return nil, nil
 	}
 }
 
@@ -218,35 +383,104 @@ func (s *handlerManager) Trigger(handler Invocable) error {
 
 	message := handler
 
-	reasonStr := message.GetReason().String()
+	if s.draining.Load() {
+		s.logger.Warn("Rejecting trigger while draining", zap.String("handler", handlerName))
+		return ErrDraining
+	}
+
+	logger := s.invokeLogger(entry, message)
 
 	if !entry.IsActive() {
+		if s.queue != nil && isDurableReason(message.GetReason()) {
+			return s.hold(message, logger)
+		}
 		s.logger.Warn("handler is not active", zap.String("handler", handlerName))
 		return fmt.Errorf("cannot trigger non-started handler: %s", handlerName)
 	}
 
-	logger := s.logger.With(
+	if s.queue != nil && isDurableReason(message.GetReason()) {
+		if err := s.queue.Enqueue(message); err != nil {
+			logger.Error("Failed to queue invocation", zap.Error(err))
+			return err
+		}
+	}
+
+	limit := newConcurrencyLimit(entry.Options())
+	if limit != nil {
+		switch s.acquireSlot(entry.Id(), message, limit) {
+		case slotSkipped:
+			s.skip(message, logger)
+			return nil
+		case slotHeld:
+			logger.Info("Handler is at its concurrency limit, holding trigger until one finishes")
+			s.track(message)
+			return nil
+		}
+	}
+
+	return s.dispatch(entry, message, limit != nil, logger)
+}
+
+// hold keeps an invocation for a handler whose client isn't connected in the
+// durable queue, to be replayed when one starts a dispatch session, and
+// completes it with ErrQueued.
+func (s *handlerManager) hold(message Invocable, logger *zap.Logger) error {
+	if err := s.queue.Hold(message); err != nil {
+		logger.Error("Failed to queue invocation", zap.Error(err))
+		return err
+	}
+	logger.Info("Handler is not connected, queued invocation until it connects")
+	message.Complete("", ErrQueued)
+	return nil
+}
+
+func (s *handlerManager) invokeLogger(entry HandlerEntry, message Invocable) *zap.Logger {
+	return s.logger.With(
 		zap.String("handler-id", entry.Id()),
-		zap.String("handler", handlerName),
-		zap.String("reason", reasonStr),
+		zap.String("handler", entry.Name()),
+		zap.String("reason", message.GetReason().String()),
 	)
+}
+
+// dispatch queues an admitted invocation and tracks it until it completes.
+// For a handler with a concurrency limit (limited) it then releases the
+// invocation's slot, dispatching the held invocation if there is one. If the
+// dispatch queue is full the invocation completes with ErrDispatchQueueFull,
+// which is also returned.
+func (s *handlerManager) dispatch(entry HandlerEntry, message Invocable, limited bool, logger *zap.Logger) error {
+	handlerName := entry.Name()
+	durable := s.queue != nil && isDurableReason(message.GetReason())
 
 	logger.Info("Triggering handler")
-	contextWithTimeout, cancel := context.WithTimeout(context.Background(), message.GetEntry().Timeout())
-	handler.Start(contextWithTimeout)
+	timeout := entry.Timeout()
+	retry := newRetryPolicy(entry.Options())
+	if retry != nil {
+		timeout = retry.totalTimeout(timeout)
+	}
+	contextWithTimeout, cancel := context.WithTimeout(context.Background(), timeout)
+	message.Start(contextWithTimeout)
+	s.track(message)
 
 	entry.OnTrigger(message.GetReason())
 
 	startTime := time.Now()
-	queue := s.getDispatchQueue(entry.DispatchId())
-	queue <- message
-	s.queueDepthGauge.WithLabelValues(handlerName).Set(float64(len(queue)))
+	var queueErr error
+	if retry != nil {
+		go s.dispatchWithRetry(entry.DispatchId(), message, retry, logger)
+	} else if queueErr = s.enqueue(entry.DispatchId(), message, logger); queueErr != nil {
+		message.Complete("", queueErr)
+	}
 
 	go func() {
 		defer cancel()
 		<-message.Done()
+		s.untrack(message)
 		result, err := message.GetResult()
-		s.queueDepthGauge.WithLabelValues(handlerName).Set(float64(len(queue)))
+		if durable && !s.drained.Load() && err != errRetriesStopped && s.queue.Complete(message, err) {
+			// replayed when a client next starts a dispatch session, not
+			// now: a client that isn't receiving would let it expire again
+			logger.Warn("Invocation expired before dispatch, keeping it queued")
+		}
 		s.handlerLatencyGauge.WithLabelValues(handlerName, result).Observe(float64(time.Since(startTime).Milliseconds()))
 		if err != nil {
 			logger.Error("handler error", zap.Error(err))
@@ -254,22 +488,121 @@ func (s *handlerManager) Trigger(handler Invocable) error {
 		} else {
 			logger.Info("Handler completed", zap.Int("result-length", len(result)))
 			s.invokeCounter.WithLabelValues(handlerName, "success").Inc()
+			s.triggerDownstream(message, result)
+		}
+
+		if limited {
+			if next := s.releaseSlot(entry.Id()); next != nil {
+				s.dispatch(entry, next, true, s.invokeLogger(entry, next))
+			}
 		}
 	}()
+	return queueErr
+}
 
+// enqueue pushes invoke onto the dispatch queue of the handlers registered
+// under dispatchId, completing any invocation dropped to make room for it.
+func (s *handlerManager) enqueue(dispatchId string, invoke Invocable, logger *zap.Logger) error {
+	queue, unassign := s.route(dispatchId, invoke)
+	dropped, err := queue.push(invoke)
+	if err != nil {
+		unassign()
+		logger.Warn("Dispatch queue is full, rejecting invocation")
+		return err
+	}
+	if dropped != nil {
+		s.drop(dropped, logger)
+	}
 	return nil
 }
 
-func (s *handlerManager) getDispatchQueue(DispatchId string) chan Invocable {
+// dispatchWithRetry queues attempts of invoke until one succeeds or the retry
+// policy gives up, then completes invoke with the last attempt's result. Each
+// attempt is a separate invocation linked to invoke by its original id, so
+// history shows every attempt. It stops early if invoke itself finishes, e.g.
+// when its caller cancels or its overall timeout passes.
+func (s *handlerManager) dispatchWithRetry(dispatchId string, invoke Invocable, retry *retryPolicy, logger *zap.Logger) {
+	for attempt := 1; ; attempt++ {
+		current := newInvokeAttempt(invoke, int32(attempt))
+		ctx, cancel := context.WithTimeout(context.Background(), current.Timeout)
+		current.Start(ctx)
+		if err := s.enqueue(dispatchId, current, logger); err != nil {
+			current.Complete("", err)
+		}
+
+		select {
+		case <-current.Done():
+		case <-invoke.Done():
+			// a cancelled invocation's queued attempt isn't sent
+			_, err := invoke.GetResult()
+			current.Complete("", err)
+		}
+		cancel()
+
+		result, err := current.GetResult()
+		select {
+		case <-invoke.Done():
+			return
+		default:
+		}
+
+		if !retry.shouldRetry(attempt, err) {
+			invoke.Complete(result, err)
+			return
+		}
+		if s.draining.Load() {
+			s.stopRetries(invoke, attempt, logger)
+			return
+		}
+
+		delay := retry.backoff(attempt)
+		logger.Warn("Handler attempt failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.String("code", ErrorCode(err)),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(delay):
+		case <-invoke.Done():
+			return
+		case <-s.drainStarted:
+			s.stopRetries(invoke, attempt, logger)
+			return
+		}
+	}
+}
+
+// stopRetries completes an invocation that would be retried with
+// errRetriesStopped, because the agent is draining.
+func (s *handlerManager) stopRetries(invoke Invocable, attempt int, logger *zap.Logger) {
+	logger.Warn("Agent is shutting down, not retrying handler", zap.Int("attempt", attempt))
+	invoke.Complete("", errRetriesStopped)
+}
+
+func (s *handlerManager) getDispatchQueue(DispatchId string) *dispatchQueue {
+	s.queuesLock.Lock()
+	defer s.queuesLock.Unlock()
 
 	queue, ok := s.dispatchQueues[DispatchId]
 	if !ok {
-		queue = make(chan Invocable, 100)
+		queue = newDispatchQueue(s.queueCapacity, s.queueOverflow, s.queueDepthGauge)
 		s.dispatchQueues[DispatchId] = queue
 	}
 	return queue
 }
 
+// removeDispatchQueue removes the dispatch queue of DispatchId, returning it,
+// or nil if it has none.
+func (s *handlerManager) removeDispatchQueue(DispatchId string) *dispatchQueue {
+	s.queuesLock.Lock()
+	defer s.queuesLock.Unlock()
+
+	queue := s.dispatchQueues[DispatchId]
+	delete(s.dispatchQueues, DispatchId)
+	return queue
+}
+
 func (s *handlerManager) ListHandlers() []HandlerEntry {
 	var entries []HandlerEntry
 	for _, entry := range s.handlers {
@@ -279,14 +612,105 @@ func (s *handlerManager) ListHandlers() []HandlerEntry {
 }
 
 func (s *handlerManager) GetByTag(tag string) HandlerEntry {
+	entries := s.ListByTag(tag)
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries[0]
+}
+
+// ListByTag returns every active handler with tag, such as all the handlers
+// subscribed to a webhook id, ordered by name. With the durable queue it also
+// returns a registered handler whose client isn't connected, if no client
+// for a handler of that name is, as its invocations are held until one
+// connects.
+func (s *handlerManager) ListByTag(tag string) []HandlerEntry {
+	entries := []HandlerEntry{}
+	active := map[string]bool{}
+	inactive := map[string]HandlerEntry{}
 	for _, entry := range s.handlers {
-		if entry.Tag() == tag && entry.IsActive() {
-			return entry
+		if entry.Tag() != tag {
+			continue
+		}
+		if entry.IsActive() {
+			entries = append(entries, entry)
+			active[entry.Name()] = true
+		} else if s.queue != nil {
+			inactive[entry.Name()] = entry
+		}
+	}
+	for name, entry := range inactive {
+		if !active[name] {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+// Pausable is implemented by handler entries whose schedule can be paused.
+type Pausable interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+}
+
+// Scheduled is implemented by handler entries that run on a schedule.
+type Scheduled interface {
+	NextRun(now time.Time) *time.Time
+	Interval() time.Duration
+}
+
+// ErrNotScheduled is returned when pausing or triggering a handler that
+// doesn't run on a schedule.
+var ErrNotScheduled = errors.New("handler is not a scheduled handler")
+
+// SetPaused pauses or resumes every handler named handlerName, returning
+// os.ErrNotExist if there are none.
+func SetPaused(manager Manager, handlerName string, paused bool) error {
+	found := false
+	for _, entry := range manager.ListHandlers() {
+		if entry.Name() != handlerName {
+			continue
 		}
+		found = true
+		pausable, ok := entry.(Pausable)
+		if !ok {
+			return ErrNotScheduled
+		}
+		if paused {
+			pausable.Pause()
+		} else {
+			pausable.Resume()
+		}
+	}
+	if !found {
+		return os.ErrNotExist
 	}
 	return nil
 }
 
+// TriggerRun queues an out-of-band RUN_NOW invocation of the active
+// scheduled handler named handlerName.
+func TriggerRun(manager Manager, handlerName string) (Invocable, error) {
+	for _, entry := range manager.ListHandlers() {
+		if entry.Name() != handlerName || !entry.IsActive() {
+			continue
+		}
+		if _, ok := entry.(Pausable); !ok {
+			return nil, ErrNotScheduled
+		}
+		invoke := NewScheduledHandlerInvoke(entry, pb.HandlerInvokeType_RUN_NOW)
+		if err := manager.Trigger(invoke); err != nil {
+			return nil, err
+		}
+		return invoke, nil
+	}
+	return nil, os.ErrNotExist
+}
+
 func TriggerInvoke(context context.Context, manager Manager, handlerName string, body string) (string, error) {
 
 	entry := manager.GetByTag(handlerName)
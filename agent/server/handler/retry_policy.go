@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+)
+
+const (
+	defaultRetryInitialBackoff = time.Second
+	defaultRetryMaxBackoff     = time.Minute
+	defaultRetryMultiplier     = 2.0
+)
+
+// retryPolicy decides whether and when a failed invocation is re-dispatched,
+// as declared by a HandlerRetryOption at registration.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	retryOn        []string
+}
+
+// newRetryPolicy returns the retry policy declared in options, or nil if the
+// handler does not retry.
+func newRetryPolicy(options []*pb.HandlerOption) *retryPolicy {
+	for _, option := range options {
+		retry := option.GetRetry()
+		if retry == nil || retry.MaxAttempts <= 1 {
+			continue
+		}
+
+		policy := &retryPolicy{
+			maxAttempts:    int(retry.MaxAttempts),
+			initialBackoff: time.Duration(retry.InitialBackoffMs) * time.Millisecond,
+			maxBackoff:     time.Duration(retry.MaxBackoffMs) * time.Millisecond,
+			multiplier:     retry.BackoffMultiplier,
+			jitter:         math.Min(math.Max(retry.Jitter, 0), 1),
+			retryOn:        retry.RetryOn,
+		}
+		if policy.initialBackoff <= 0 {
+			policy.initialBackoff = defaultRetryInitialBackoff
+		}
+		if policy.maxBackoff <= 0 {
+			policy.maxBackoff = defaultRetryMaxBackoff
+		}
+		if policy.multiplier < 1 {
+			policy.multiplier = defaultRetryMultiplier
+		}
+		return policy
+	}
+	return nil
+}
+
+// shouldRetry reports whether another attempt should follow the given failed one.
+func (p *retryPolicy) shouldRetry(attempt int, err error) bool {
+	if err == nil || attempt >= p.maxAttempts {
+		return false
+	}
+	if len(p.retryOn) == 0 {
+		return true
+	}
+	return slices.Contains(p.retryOn, ErrorCode(err))
+}
+
+// backoff returns how long to wait after the given failed attempt.
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.maxBackoffFor(attempt))
+	if p.jitter > 0 {
+		delay -= delay * p.jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// maxBackoffFor is the backoff after the given attempt before jitter is applied.
+func (p *retryPolicy) maxBackoffFor(attempt int) time.Duration {
+	delay := float64(p.initialBackoff) * math.Pow(p.multiplier, float64(attempt-1))
+	return time.Duration(math.Min(delay, float64(p.maxBackoff)))
+}
+
+// totalTimeout bounds a whole invocation, all attempts and the waits between
+// them included, given the timeout of a single attempt.
+func (p *retryPolicy) totalTimeout(attemptTimeout time.Duration) time.Duration {
+	total := attemptTimeout * time.Duration(p.maxAttempts)
+	for attempt := 1; attempt < p.maxAttempts; attempt++ {
+		total += p.maxBackoffFor(attempt)
+	}
+	return total
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func retryOption(retry *pb.HandlerRetryOption) *pb.HandlerOption {
+	return &pb.HandlerOption{
+		Option: &pb.HandlerOption_Retry{
+			Retry: retry,
+		},
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	require.Nil(t, newRetryPolicy(nil))
+	require.Nil(t, newRetryPolicy([]*pb.HandlerOption{FixtureHandlerOption()}))
+	require.Nil(t, newRetryPolicy([]*pb.HandlerOption{retryOption(&pb.HandlerRetryOption{MaxAttempts: 1})}))
+
+	policy := newRetryPolicy([]*pb.HandlerOption{
+		FixtureHandlerOption(),
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 3}),
+	})
+	require.NotNil(t, policy)
+	require.Equal(t, 3, policy.maxAttempts)
+	require.Equal(t, defaultRetryInitialBackoff, policy.initialBackoff)
+	require.Equal(t, defaultRetryMaxBackoff, policy.maxBackoff)
+	require.Equal(t, defaultRetryMultiplier, policy.multiplier)
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := newRetryPolicy([]*pb.HandlerOption{
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 3, RetryOn: []string{ErrorCodeTimeout}}),
+	})
+
+	timeoutErr := &InvocationError{Code: ErrorCodeTimeout}
+	require.True(t, policy.shouldRetry(1, timeoutErr))
+	require.True(t, policy.shouldRetry(2, timeoutErr))
+	require.False(t, policy.shouldRetry(3, timeoutErr))
+	require.False(t, policy.shouldRetry(1, nil))
+	require.False(t, policy.shouldRetry(1, &InvocationError{Code: ErrorCodeUnexpected}))
+	require.True(t, policy.shouldRetry(1, context.DeadlineExceeded))
+
+	policy.retryOn = nil
+	require.True(t, policy.shouldRetry(1, fmt.Errorf("boom")))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := newRetryPolicy([]*pb.HandlerOption{
+		retryOption(&pb.HandlerRetryOption{
+			MaxAttempts:       5,
+			InitialBackoffMs:  100,
+			MaxBackoffMs:      300,
+			BackoffMultiplier: 2,
+		}),
+	})
+
+	require.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	require.Equal(t, 300*time.Millisecond, policy.backoff(3))
+	require.Equal(t, 5*time.Second+900*time.Millisecond, policy.totalTimeout(time.Second))
+
+	policy.jitter = 0.5
+	for range 20 {
+		delay := policy.backoff(2)
+		require.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		require.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+}
+
+func TestTriggerWithRetry(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 3, InitialBackoffMs: 1}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	entry := mgr.GetByTag("handler1")
+	require.NotNil(t, entry)
+
+	invoke := NewInvokeHandlerInvoke(entry, "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+
+	first, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	firstMsg := first.ToDispatchInvoke()
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, firstMsg.InvocationId)
+	require.Equal(t, int32(1), firstMsg.Attempt)
+	require.Equal(t, "payload", firstMsg.Args["body"])
+
+	first.Complete("", &InvocationError{Code: ErrorCodeTimeout})
+
+	second, err := mgr.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	secondMsg := second.ToDispatchInvoke()
+	require.NotEqual(t, firstMsg.InvocationId, secondMsg.InvocationId)
+	require.Equal(t, firstMsg.InvocationId, secondMsg.OriginalInvocationId)
+	require.Equal(t, int32(2), secondMsg.Attempt)
+
+	select {
+	case <-invoke.Done():
+		require.Fail(t, "invocation completed before its retry finished")
+	default:
+	}
+
+	second.Complete("ok", nil)
+
+	select {
+	case <-invoke.Done():
+	case <-time.After(time.Second):
+		require.Fail(t, "invocation not completed")
+	}
+	result, err := invoke.GetResult()
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+}
+
+func TestTriggerWithRetryExhausted(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := mgr.RegisterHandler("1", "handler1", defaultTimeout,
+		retryOption(&pb.HandlerRetryOption{MaxAttempts: 2, InitialBackoffMs: 1}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start("1"))
+
+	invoke := NewInvokeHandlerInvoke(mgr.GetByTag("handler1"), "payload")
+	require.NoError(t, mgr.Trigger(invoke))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		current, err := mgr.Dequeue(context.Background(), "1", time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, current)
+		require.Equal(t, int32(attempt), current.ToDispatchInvoke().Attempt)
+		current.Complete("", &InvocationError{Code: ErrorCodeUnexpected, Message: "boom"})
+	}
+
+	<-invoke.Done()
+	_, err = invoke.GetResult()
+	require.Error(t, err)
+	require.Equal(t, ErrorCodeUnexpected, ErrorCode(err))
+
+	next, err := mgr.Dequeue(context.Background(), "1", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, next)
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"context"
+	"math/rand/v2"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
 	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/leader"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 // Manager manages a single handler and is responsible for triggering its invocations
@@ -18,8 +23,13 @@ type ScheduledHandlerEntry struct {
 	cronId   string
 	logger   *zap.Logger
 	cron     cron.Cron
+	history  HistoryManager
+	elector  leader.Elector
+	schedule *pb.HandlerScheduleOption
 	done     chan struct{}
 	finished bool
+	paused   atomic.Bool
+	started  atomic.Int64
 }
 
 func NewScheduledHandlerInvoke(entry HandlerEntry, reason pb.HandlerInvokeType) Invocable {
@@ -31,6 +41,27 @@ func NewScheduledHandlerInvoke(entry HandlerEntry, reason pb.HandlerInvokeType)
 	return invoke
 }
 
+// ScheduledTimeArg is the arg a CATCH_UP invocation carries the missed cron
+// time in, formatted as RFC3339.
+const ScheduledTimeArg = "scheduled-time"
+
+const defaultMaxCatchUpRuns = 10
+
+// leaderPollInterval is how often a cron handler checks whether its replica
+// became the leader, to catch up on runs missed before it was.
+const leaderPollInterval = time.Second
+
+func NewCatchUpHandlerInvoke(entry HandlerEntry, scheduled time.Time) Invocable {
+	invoke := NewHandlerInvoke(entry, pb.HandlerInvokeType_CATCH_UP, map[string]string{
+		ScheduledTimeArg: scheduled.Format(time.RFC3339),
+	})
+
+	if invoke.Timeout == 0 {
+		invoke.Timeout = defaultTimeout
+	}
+	return invoke
+}
+
 func newScheduledHandlerEntry(
 	manager Manager,
 	logger *zap.Logger,
@@ -38,6 +69,8 @@ func newScheduledHandlerEntry(
 	name string,
 	timeout time.Duration,
 	cron cron.Cron,
+	history HistoryManager,
+	elector leader.Elector,
 	options ...*pb.HandlerOption,
 ) HandlerEntry {
 	handler := func(context.Context, *pb.DispatchRequest) (*pb.DispatchHandlerInvoke, error) {
@@ -51,19 +84,104 @@ func newScheduledHandlerEntry(
 			timeout,
 			options...,
 		),
-		logger:  logger,
-		handler: handler,
-		manager: manager,
-		cron:    cron,
+		logger:   logger,
+		handler:  handler,
+		manager:  manager,
+		cron:     cron,
+		history:  history,
+		elector:  elector,
+		schedule: scheduleOptions(options),
 	}
 	return entry
 }
 
+// scheduleOptions merges the HandlerScheduleOptions in options into one.
+func scheduleOptions(options []*pb.HandlerOption) *pb.HandlerScheduleOption {
+	merged := &pb.HandlerScheduleOption{}
+	for _, option := range options {
+		if schedule := option.GetSchedule(); schedule != nil {
+			proto.Merge(merged, schedule)
+		}
+	}
+	return merged
+}
+
+// cronSpec returns spec evaluated in the handler's timezone, unless it
+// names its own.
+func cronSpec(spec string, schedule *pb.HandlerScheduleOption) string {
+	if schedule.GetTimezone() == "" || strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		return spec
+	}
+	return "CRON_TZ=" + schedule.Timezone + " " + spec
+}
+
+// trigger queues a scheduled invocation, first waiting a random delay of up
+// to the handler's jitter unless done closes.
+func (h *ScheduledHandlerEntry) trigger(reason pb.HandlerInvokeType, done <-chan struct{}) {
+	jitter := time.Duration(h.schedule.GetJitterMs()) * time.Millisecond
+	if jitter <= 0 {
+		h.fire(reason)
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(rand.N(jitter))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-done:
+			return
+		}
+		select {
+		case <-done:
+		default:
+			h.fire(reason)
+		}
+	}()
+}
+
+// fire queues a scheduled invocation, unless the handler is paused or
+// another agent replica is the leader and runs it instead.
+func (h *ScheduledHandlerEntry) fire(reason pb.HandlerInvokeType) {
+	if h.paused.Load() {
+		h.logger.Debug("Skipping scheduled run, handler is paused", zap.String("handler", h.Name()))
+		return
+	}
+	if !h.isLeader() {
+		h.logger.Debug("Skipping scheduled run, not the leader", zap.String("handler", h.Name()))
+		return
+	}
+	h.manager.Trigger(NewScheduledHandlerInvoke(h, reason))
+}
+
+func (h *ScheduledHandlerEntry) isLeader() bool {
+	return h.elector == nil || h.elector.IsLeader()
+}
+
+// Pause stops the handler's scheduled runs, including pending jittered ones,
+// until Resume. The handler stays registered and can still be triggered.
+func (h *ScheduledHandlerEntry) Pause() {
+	if !h.paused.Swap(true) {
+		h.logger.Info("Pausing handler", zap.String("handler", h.Name()))
+	}
+}
+
+func (h *ScheduledHandlerEntry) Resume() {
+	if h.paused.Swap(false) {
+		h.logger.Info("Resuming handler", zap.String("handler", h.Name()))
+	}
+}
+
+func (h *ScheduledHandlerEntry) IsPaused() bool {
+	return h.paused.Load()
+}
+
 func (h *ScheduledHandlerEntry) Start() error {
 	if h.done != nil {
 		return nil
 	}
 	h.done = make(chan struct{})
+	h.started.Store(time.Now().UnixNano())
 	h.logger.Info("Starting handler", zap.String("handler", h.Name()))
 	fail := false
 
@@ -74,6 +192,14 @@ func (h *ScheduledHandlerEntry) Start() error {
 	}()
 	h.HandlerEntry.Start()
 
+	if timezone := h.schedule.GetTimezone(); timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			h.logger.Error("invalid schedule timezone", zap.String("timezone", timezone), zap.Error(err))
+			fail = true
+			return err
+		}
+	}
+
 	for _, option := range h.Options() {
 		if err := h.applyOption(option); err != nil {
 			fail = true
@@ -90,6 +216,7 @@ func (h *ScheduledHandlerEntry) Close() {
 	h.logger.Info("Stopping handler", zap.String("handler", h.Name()))
 	close(h.done)
 	h.done = nil
+	h.started.Store(0)
 	h.HandlerEntry.Close()
 }
 
@@ -110,53 +237,191 @@ func (h *ScheduledHandlerEntry) applyOption(opt *pb.HandlerOption) error {
 		}
 	case pb.HandlerInvokeType_RUN_INTERVAL:
 
-		duration, err := time.ParseDuration(option.Value)
+		duration, err := parseInterval(option.Value)
 		if err != nil {
-
-			asInt, err2 := strconv.Atoi(option.Value)
-			if err2 == nil {
-				duration = time.Duration(asInt) * time.Second
-			} else {
-				h.logger.Error("failed to parse duration", zap.Error(err))
-				return err
-			}
+			h.logger.Error("failed to parse duration", zap.Error(err))
+			return err
 		}
 
 		h.logger.Info("Registering handler with RUN_INTERVAL", zap.String("handler", h.Name()), zap.Duration("interval", duration))
+		done := h.done
 		go func() {
 			timer := time.NewTicker(duration)
 
 			for {
 				select {
 				case <-timer.C:
-					invoke := NewScheduledHandlerInvoke(h, pb.HandlerInvokeType_RUN_INTERVAL)
-					h.manager.Trigger(invoke)
-				case <-h.done:
+					h.trigger(pb.HandlerInvokeType_RUN_INTERVAL, done)
+				case <-done:
 					return
 				}
 			}
 
 		}()
 	case pb.HandlerInvokeType_CRON_SCHEDULE:
-		h.logger.Info("Registering handler with CRON_SCHEDULE", zap.String("handler", h.Name()), zap.String("schedule", option.Value))
-		id, err := h.cron.Add(option.Value, func() {
-			invoke := NewScheduledHandlerInvoke(h, pb.HandlerInvokeType_CRON_SCHEDULE)
-			h.manager.Trigger(invoke)
+		spec := cronSpec(option.Value, h.schedule)
+		h.logger.Info("Registering handler with CRON_SCHEDULE", zap.String("handler", h.Name()), zap.String("schedule", spec))
+		done := h.done
+		id, err := h.cron.Add(spec, func() {
+			h.trigger(pb.HandlerInvokeType_CRON_SCHEDULE, done)
 		})
 		if err != nil {
 			h.logger.Error("failed to add cron job", zap.Error(err))
 			return err
 		}
 		go func() {
-			<-h.done
+			<-done
 			h.cron.Remove(id)
 		}()
 		h.cronId = id
+		h.catchUp(option.Value)
+		if h.elector != nil {
+			leader.WatchElected(h.elector, leaderPollInterval, done, func() {
+				h.catchUp(option.Value)
+			})
+		}
 	}
 
 	return nil
 }
 
+// catchUp triggers the runs of spec missed since the handler's last recorded
+// scheduled run, as its misfire policy allows. Other runs, such as invokes,
+// and scheduled runs that were skipped or dropped without running, don't
+// count. It is called on start, and again when the replica becomes leader.
+func (h *ScheduledHandlerEntry) catchUp(spec string) {
+	policy := h.schedule.GetMisfirePolicy()
+	if policy == pb.HandlerMisfirePolicy_MISFIRE_SKIP || h.history == nil || h.paused.Load() || !h.isLeader() {
+		return
+	}
+
+	page, err := h.history.Query(context.Background(), HistoryQuery{
+		HandlerName:       h.Name(),
+		Tail:              1,
+		Reasons:           []pb.HandlerInvokeType{pb.HandlerInvokeType_CRON_SCHEDULE, pb.HandlerInvokeType_CATCH_UP},
+		ExcludeErrorCodes: []string{ErrorCodeSkipped, ErrorCodeDropped},
+	})
+	if err != nil {
+		h.logger.Error("failed to read handler history for catch-up", zap.String("handler", h.Name()), zap.Error(err))
+		return
+	}
+	history := page.Executions
+	if len(history) == 0 {
+		// never ran, so there is nothing to catch up on
+		return
+	}
+	last := history[0].GetStartClientTimestamp().AsTime()
+	if history[0].StartClientTimestamp == nil {
+		last = history[0].GetPublishServerTimestamp().AsTime()
+	}
+
+	limit := 1
+	if policy == pb.HandlerMisfirePolicy_MISFIRE_RUN_ALL {
+		limit = defaultMaxCatchUpRuns
+		if h.schedule.GetMaxCatchUpRuns() > 0 {
+			limit = int(h.schedule.MaxCatchUpRuns)
+		}
+	}
+
+	missed, err := missedRuns(cronSpec(spec, h.schedule), last, time.Now(), limit)
+	if err != nil {
+		h.logger.Error("failed to compute missed runs", zap.String("handler", h.Name()), zap.Error(err))
+		return
+	}
+	if len(missed) == 0 {
+		return
+	}
+
+	h.logger.Info("Catching up missed scheduled runs",
+		zap.String("handler", h.Name()),
+		zap.Time("last_run", last),
+		zap.Int("runs", len(missed)),
+	)
+	for _, scheduled := range missed {
+		h.manager.Trigger(NewCatchUpHandlerInvoke(h, scheduled))
+	}
+}
+
+// missedRuns returns the times spec fired after last and up to now, keeping
+// only the most recent limit of them.
+func missedRuns(spec string, last time.Time, now time.Time, limit int) ([]time.Time, error) {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	missed := make([]time.Time, 0, limit)
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		if len(missed) == limit {
+			missed = append(missed[:0], missed[1:]...)
+		}
+		missed = append(missed, next)
+	}
+	return missed, nil
+}
+
+// parseInterval parses a RUN_INTERVAL value, either a duration such as "5m"
+// or a number of seconds.
+func parseInterval(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		asInt, err2 := strconv.Atoi(value)
+		if err2 != nil {
+			return 0, err
+		}
+		duration = time.Duration(asInt) * time.Second
+	}
+	return duration, nil
+}
+
+// Interval returns the interval of a RUN_INTERVAL handler, or 0.
+func (h *ScheduledHandlerEntry) Interval() time.Duration {
+	for _, opt := range h.Options() {
+		if invoke := opt.GetInvoke(); invoke.GetType() == pb.HandlerInvokeType_RUN_INTERVAL {
+			if interval, err := parseInterval(invoke.Value); err == nil {
+				return interval
+			}
+		}
+	}
+	return 0
+}
+
+// NextRun returns when the handler is next due to run after now, or nil if it
+// isn't running, is paused or has no schedule.
+func (h *ScheduledHandlerEntry) NextRun(now time.Time) *time.Time {
+	started := h.started.Load()
+	if started == 0 || h.paused.Load() {
+		return nil
+	}
+
+	var next *time.Time
+	earliest := func(t time.Time) {
+		if !t.IsZero() && (next == nil || t.Before(*next)) {
+			next = &t
+		}
+	}
+	for _, opt := range h.Options() {
+		invoke := opt.GetInvoke()
+		switch invoke.GetType() {
+		case pb.HandlerInvokeType_RUN_INTERVAL:
+			interval, err := parseInterval(invoke.Value)
+			if err != nil || interval <= 0 {
+				continue
+			}
+			// the ticker fires every interval from when the handler started
+			elapsed := now.Sub(time.Unix(0, started))
+			earliest(time.Unix(0, started).Add((elapsed/interval + 1) * interval))
+		case pb.HandlerInvokeType_CRON_SCHEDULE:
+			schedule, err := cron.Parse(cronSpec(invoke.Value, h.schedule))
+			if err != nil {
+				continue
+			}
+			earliest(schedule.Next(now))
+		}
+	}
+	return next
+}
+
 func (h *ScheduledHandlerEntry) isSingleTrigger() bool {
 	options := h.Options()
 	invokeOptions := make([]*pb.HandlerInvokeOption, 0, len(options))
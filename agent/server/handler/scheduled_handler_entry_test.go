@@ -3,20 +3,23 @@ package handler
 import (
 	"context"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
 	"github.com/cortexapps/axon/server/cron"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestApplyOption_RunNow(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	cron := cron.New()
 	manager := NewHandlerManager(logger, cron, nil)
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil).(*ScheduledHandlerEntry)
 
 	option := &pb.HandlerOption{
 		Option: &pb.HandlerOption_Invoke{
@@ -43,7 +46,7 @@ func TestApplyOption_RunInterval(t *testing.T) {
 		},
 	}
 
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, option).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil, option).(*ScheduledHandlerEntry)
 	manager.handlers = append(manager.handlers, entry)
 	err := entry.Start()
 
@@ -67,7 +70,7 @@ func TestApplyOption_RunInterval_InvalidDuration(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	cron := cron.New()
 	manager := NewHandlerManager(logger, cron, nil)
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil).(*ScheduledHandlerEntry)
 
 	option := &pb.HandlerOption{
 		Option: &pb.HandlerOption_Invoke{
@@ -102,7 +105,7 @@ func TestApplyOption_CronSchedule(t *testing.T) {
 	}
 
 	entry := newScheduledHandlerEntry(
-		manager, logger, "1", "handler1", defaultTimeout, cron, option).(*ScheduledHandlerEntry)
+		manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil, option).(*ScheduledHandlerEntry)
 
 	err := entry.Start()
 	require.NoError(t, err)
@@ -130,7 +133,7 @@ func TestApplyOption_CronSchedule_Invalid(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	cron := cron.New()
 	manager := NewHandlerManager(logger, cron, nil)
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil).(*ScheduledHandlerEntry)
 
 	option := &pb.HandlerOption{
 		Option: &pb.HandlerOption_Invoke{
@@ -145,6 +148,293 @@ func TestApplyOption_CronSchedule_Invalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCronSpec(t *testing.T) {
+	require.Equal(t, "0 9 * * *", cronSpec("0 9 * * *", nil))
+	require.Equal(t, "CRON_TZ=Europe/Paris 0 9 * * *", cronSpec("0 9 * * *", &pb.HandlerScheduleOption{Timezone: "Europe/Paris"}))
+	require.Equal(t, "TZ=UTC 0 9 * * *", cronSpec("TZ=UTC 0 9 * * *", &pb.HandlerScheduleOption{Timezone: "Europe/Paris"}))
+}
+
+func TestApplyOption_CronScheduleTimezone(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cron := &fakeCron{
+		handlers: make(map[string]func()),
+	}
+	manager := newFakeManager()
+
+	options := []*pb.HandlerOption{
+		{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{
+					Type:  pb.HandlerInvokeType_CRON_SCHEDULE,
+					Value: "@daily",
+				},
+			},
+		},
+		{
+			Option: &pb.HandlerOption_Schedule{
+				Schedule: &pb.HandlerScheduleOption{Timezone: "America/New_York"},
+			},
+		},
+	}
+
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil, options...).(*ScheduledHandlerEntry)
+	require.NoError(t, entry.Start())
+	defer entry.Close()
+	require.Equal(t, []string{"CRON_TZ=America/New_York @daily"}, cron.specs)
+
+	options[1].GetSchedule().Timezone = "Nowhere/Special"
+	invalid := newScheduledHandlerEntry(manager, logger, "1", "handler2", defaultTimeout, cron, nil, nil, options...).(*ScheduledHandlerEntry)
+	require.Error(t, invalid.Start())
+	require.False(t, invalid.IsActive())
+}
+
+func TestScheduleJitter(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := newFakeManager()
+	option := &pb.HandlerOption{
+		Option: &pb.HandlerOption_Schedule{
+			Schedule: &pb.HandlerScheduleOption{JitterMs: 50},
+		},
+	}
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron.NewNoopCron(), nil, nil, option).(*ScheduledHandlerEntry)
+
+	// a closed handler drops its pending jittered triggers
+	done := make(chan struct{})
+	close(done)
+	for range 10 {
+		entry.trigger(pb.HandlerInvokeType_CRON_SCHEDULE, done)
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.Empty(t, manager.triggered)
+
+	entry.trigger(pb.HandlerInvokeType_CRON_SCHEDULE, make(chan struct{}))
+	require.Eventually(t, func() bool {
+		return len(manager.triggered) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+type fakeElector struct {
+	leader atomic.Bool
+}
+
+func (e *fakeElector) Start() error   { return nil }
+func (e *fakeElector) Close() error   { return nil }
+func (e *fakeElector) IsLeader() bool { return e.leader.Load() }
+
+func TestScheduleOnlyRunsOnLeader(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := newFakeManager()
+	elector := &fakeElector{}
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron.NewNoopCron(), nil, elector).(*ScheduledHandlerEntry)
+
+	entry.trigger(pb.HandlerInvokeType_CRON_SCHEDULE, make(chan struct{}))
+	require.Empty(t, manager.triggered)
+
+	elector.leader.Store(true)
+	entry.trigger(pb.HandlerInvokeType_CRON_SCHEDULE, make(chan struct{}))
+	require.Len(t, manager.triggered, 1)
+}
+
+func TestSchedulePause(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := newFakeManager()
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron.NewNoopCron(), nil, nil).(*ScheduledHandlerEntry)
+
+	entry.Pause()
+	require.True(t, entry.IsPaused())
+	entry.trigger(pb.HandlerInvokeType_RUN_INTERVAL, make(chan struct{}))
+	require.Empty(t, manager.triggered)
+
+	entry.Resume()
+	require.False(t, entry.IsPaused())
+	entry.trigger(pb.HandlerInvokeType_RUN_INTERVAL, make(chan struct{}))
+	require.Len(t, manager.triggered, 1)
+}
+
+func TestNextRun(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := newFakeManager()
+	interval := &pb.HandlerOption{
+		Option: &pb.HandlerOption_Invoke{
+			Invoke: &pb.HandlerInvokeOption{Type: pb.HandlerInvokeType_RUN_INTERVAL, Value: "2h"},
+		},
+	}
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron.NewNoopCron(), nil, nil, interval).(*ScheduledHandlerEntry)
+	require.Equal(t, 2*time.Hour, entry.Interval())
+	require.Nil(t, entry.NextRun(time.Now()))
+
+	require.NoError(t, entry.Start())
+	defer entry.Close()
+
+	// the ticker fires every two hours from when the handler started
+	started := time.Unix(0, entry.started.Load())
+	require.Equal(t, started.Add(2*time.Hour), *entry.NextRun(started.Add(time.Minute)))
+	require.Equal(t, started.Add(4*time.Hour), *entry.NextRun(started.Add(150 * time.Minute)))
+
+	entry.Pause()
+	require.Nil(t, entry.NextRun(started.Add(time.Minute)))
+
+	// with a cron schedule too, whichever fires first is next
+	options := []*pb.HandlerOption{
+		interval,
+		{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{Type: pb.HandlerInvokeType_CRON_SCHEDULE, Value: "@hourly"},
+			},
+		},
+		{
+			Option: &pb.HandlerOption_Schedule{
+				Schedule: &pb.HandlerScheduleOption{Timezone: "UTC"},
+			},
+		},
+	}
+	both := newScheduledHandlerEntry(manager, logger, "1", "handler2", defaultTimeout, cron.NewNoopCron(), nil, nil, options...).(*ScheduledHandlerEntry)
+	require.NoError(t, both.Start())
+	defer both.Close()
+	now := time.Now()
+	require.Equal(t, now.Truncate(time.Hour).Add(time.Hour), *both.NextRun(now))
+}
+
+func TestMissedRuns(t *testing.T) {
+	last := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 1, 13, 15, 0, 0, time.UTC)
+
+	missed, err := missedRuns("0 * * * *", last, now, 10)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+	}, missed)
+
+	// only the most recent runs are kept
+	missed, err = missedRuns("0 * * * *", last, now, 2)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+	}, missed)
+
+	missed, err = missedRuns("0 * * * *", now, now, 10)
+	require.NoError(t, err)
+	require.Empty(t, missed)
+
+	_, err = missedRuns("1 *", last, now, 10)
+	require.Error(t, err)
+}
+
+func TestCatchUpMissedRuns(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	lastRun := time.Now().Truncate(time.Hour).Add(-5*time.Hour + time.Second)
+	require.NoError(t, history.Write(context.Background(), &pb.HandlerExecution{
+		HandlerName:  "handler1",
+		InvocationId: "scheduled",
+		Reason:       pb.HandlerInvokeType_CRON_SCHEDULE,
+		// five hourly runs ago, so the test doesn't depend on the time it runs
+		StartClientTimestamp: timestamppb.New(lastRun),
+	}))
+	// later runs that weren't scheduled, or never ran, don't hide missed runs
+	require.NoError(t, history.Write(context.Background(), &pb.HandlerExecution{
+		HandlerName:          "handler1",
+		InvocationId:         "invoked",
+		Reason:               pb.HandlerInvokeType_INVOKE,
+		StartClientTimestamp: timestamppb.New(lastRun.Add(2 * time.Hour)),
+	}))
+	require.NoError(t, history.Write(context.Background(), &pb.HandlerExecution{
+		HandlerName:          "handler1",
+		InvocationId:         "skipped",
+		Reason:               pb.HandlerInvokeType_CRON_SCHEDULE,
+		StartClientTimestamp: timestamppb.New(lastRun.Add(3 * time.Hour)),
+		Error:                &pb.Error{Code: ErrorCodeSkipped},
+	}))
+
+	options := func(policy pb.HandlerMisfirePolicy, maxRuns int32) []*pb.HandlerOption {
+		return []*pb.HandlerOption{
+			{
+				Option: &pb.HandlerOption_Invoke{
+					Invoke: &pb.HandlerInvokeOption{Type: pb.HandlerInvokeType_CRON_SCHEDULE, Value: "@hourly"},
+				},
+			},
+			{
+				Option: &pb.HandlerOption_Schedule{
+					Schedule: &pb.HandlerScheduleOption{Timezone: "UTC", MisfirePolicy: policy, MaxCatchUpRuns: maxRuns},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name     string
+		policy   pb.HandlerMisfirePolicy
+		maxRuns  int32
+		handler  string
+		expected int
+	}{
+		{name: "skip", policy: pb.HandlerMisfirePolicy_MISFIRE_SKIP, handler: "handler1", expected: 0},
+		{name: "run once", policy: pb.HandlerMisfirePolicy_MISFIRE_RUN_ONCE, handler: "handler1", expected: 1},
+		{name: "run all", policy: pb.HandlerMisfirePolicy_MISFIRE_RUN_ALL, handler: "handler1", expected: 5},
+		{name: "run all capped", policy: pb.HandlerMisfirePolicy_MISFIRE_RUN_ALL, maxRuns: 2, handler: "handler1", expected: 2},
+		{name: "never ran", policy: pb.HandlerMisfirePolicy_MISFIRE_RUN_ALL, handler: "handler2", expected: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			manager := newFakeManager()
+			entry := newScheduledHandlerEntry(manager, logger, "1", c.handler, defaultTimeout, cron.NewNoopCron(), history, nil, options(c.policy, c.maxRuns)...).(*ScheduledHandlerEntry)
+			require.NoError(t, entry.Start())
+			defer entry.Close()
+
+			require.Len(t, manager.triggered, c.expected)
+			previous := ""
+			for _, invoke := range manager.triggered {
+				message := invoke.ToDispatchInvoke()
+				require.Equal(t, pb.HandlerInvokeType_CATCH_UP, message.Reason)
+				scheduled, err := time.Parse(time.RFC3339, message.Args[ScheduledTimeArg])
+				require.NoError(t, err)
+				require.True(t, scheduled.Before(time.Now()))
+				require.Greater(t, message.Args[ScheduledTimeArg], previous)
+				previous = message.Args[ScheduledTimeArg]
+			}
+		})
+	}
+}
+
+func TestCatchUpOnElection(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	history := NewHistoryManager(config.AgentConfig{HandlerHistoryPath: t.TempDir()}, logger)
+	require.NoError(t, history.Write(context.Background(), &pb.HandlerExecution{
+		HandlerName:          "handler1",
+		Reason:               pb.HandlerInvokeType_CRON_SCHEDULE,
+		StartClientTimestamp: timestamppb.New(time.Now().Truncate(time.Hour).Add(-2*time.Hour + time.Second)),
+	}))
+
+	manager := newFakeManager()
+	elector := &fakeElector{}
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron.NewNoopCron(), history, elector,
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{Type: pb.HandlerInvokeType_CRON_SCHEDULE, Value: "@hourly"},
+			},
+		},
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Schedule{
+				Schedule: &pb.HandlerScheduleOption{Timezone: "UTC", MisfirePolicy: pb.HandlerMisfirePolicy_MISFIRE_RUN_ALL},
+			},
+		},
+	).(*ScheduledHandlerEntry)
+	require.NoError(t, entry.Start())
+	defer entry.Close()
+
+	// a standby doesn't catch up until it becomes the leader
+	require.Empty(t, manager.triggered)
+	elector.leader.Store(true)
+	require.Eventually(t, func() bool {
+		return len(manager.triggered) == 2
+	}, 3*leaderPollInterval, 10*time.Millisecond)
+}
+
 func TestIsFinishedOnRunNowOnly(t *testing.T) {
 
 	logger, _ := zap.NewDevelopment()
@@ -159,7 +449,7 @@ func TestIsFinishedOnRunNowOnly(t *testing.T) {
 		},
 	}
 
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, option).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil, option).(*ScheduledHandlerEntry)
 
 	require.False(t, entry.IsFinished())
 
@@ -193,7 +483,7 @@ func TestIsFinishedOnRunNowAndInterval(t *testing.T) {
 		},
 	}
 
-	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, option1, option2).(*ScheduledHandlerEntry)
+	entry := newScheduledHandlerEntry(manager, logger, "1", "handler1", defaultTimeout, cron, nil, nil, option1, option2).(*ScheduledHandlerEntry)
 
 	require.False(t, entry.IsFinished())
 
@@ -237,6 +527,10 @@ func (fhm *fakeManager) GetByTag(t string) HandlerEntry {
 	panic("not implemented") // TODO: Implement
 }
 
+func (fhm *fakeManager) ListByTag(t string) []HandlerEntry {
+	panic("not implemented") // TODO: Implement
+}
+
 func (fhm *fakeManager) ClearHandlers(id string) {
 	panic("not implemented") // TODO: Implement
 }
@@ -257,6 +551,30 @@ func (fhm *fakeManager) Dequeue(ctx context.Context, id string, waitTime time.Du
 	panic("not implemented") // TODO: Implement
 }
 
+func (fhm *fakeManager) JoinWorkerPool(dispatchId string, name string, strategy pb.WorkerPoolStrategy) {
+	panic("not implemented") // TODO: Implement
+}
+
+func (fhm *fakeManager) Cancel(invocationId string) error {
+	panic("not implemented") // TODO: Implement
+}
+
+func (fhm *fakeManager) Invocations() []InvocationStatus {
+	panic("not implemented") // TODO: Implement
+}
+
+func (fhm *fakeManager) Drain(ctx context.Context) DrainSummary {
+	panic("not implemented") // TODO: Implement
+}
+
+func (fhm *fakeManager) WatchExecutions(handlerName string) (<-chan *pb.HandlerExecutionEvent, func()) {
+	panic("not implemented") // TODO: Implement
+}
+
+func (fhm *fakeManager) PublishExecutionEvent(event *pb.HandlerExecutionEvent) {
+	panic("not implemented") // TODO: Implement
+}
+
 func (fhm *fakeManager) Start(id string) error {
 	panic("not implemented") // TODO: Implement
 }
@@ -275,11 +593,13 @@ func (fhm *fakeManager) IsFinished() bool {
 
 type fakeCron struct {
 	handlers map[string]func()
+	specs    []string
 }
 
 func (fc *fakeCron) Add(spec string, cmd func()) (string, error) {
 	id := "1"
 	fc.handlers[id] = cmd
+	fc.specs = append(fc.specs, spec)
 	go cmd()
 	return id, nil
 }
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"net/url"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 // Manager manages a single handler and is responsible for triggering its invocations
@@ -18,14 +21,38 @@ type WebhookHandlerEntry struct {
 	logger    *zap.Logger
 }
 
-func NewWebhookHandlerInvoke(entry HandlerEntry, url *url.URL, payload string, contentType string) Invocable {
+// WebhookRequest is the HTTP request that triggered a webhook. Path is the
+// part of the request path after the webhook id.
+type WebhookRequest struct {
+	URL         *url.URL
+	Method      string
+	Path        string
+	RemoteAddr  string
+	ContentType string
+	Headers     http.Header
+	Body        string
+}
+
+func NewWebhookHandlerInvoke(entry HandlerEntry, request WebhookRequest) Invocable {
+	headers := request.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headersJson, _ := json.Marshal(headers)
+	queryJson, _ := json.Marshal(request.URL.Query())
+
 	invoke := NewHandlerInvoke(
 		entry,
 		pb.HandlerInvokeType_WEBHOOK,
 		map[string]string{
-			"body":         string(payload),
-			"content-type": contentType,
-			"url":          url.String(),
+			"body":         request.Body,
+			"content-type": request.ContentType,
+			"url":          request.URL.String(),
+			"method":       request.Method,
+			"path":         request.Path,
+			"remote-addr":  request.RemoteAddr,
+			"headers":      string(headersJson),
+			"query":        string(queryJson),
 		},
 	)
 
@@ -35,10 +62,6 @@ func NewWebhookHandlerInvoke(entry HandlerEntry, url *url.URL, payload string, c
 	return invoke
 }
 
-/*
-*
-TODO Cleanup. Shouhdn have a required option of the webhook id
-*/
 func NewWebhookHandlerEntry(
 	manager Manager,
 	logger *zap.Logger,
@@ -48,11 +71,17 @@ func NewWebhookHandlerEntry(
 	options ...*pb.HandlerOption,
 ) HandlerEntry {
 
-	if len(options) != 1 {
-		logger.Panic("Webhook handler must have exactly one option")
+	webhookId := ""
+	webhookOptions := 0
+	for _, option := range options {
+		if invoke := option.GetInvoke(); invoke != nil && invoke.Type == pb.HandlerInvokeType_WEBHOOK {
+			webhookId = invoke.Value
+			webhookOptions++
+		}
+	}
+	if webhookOptions != 1 {
+		logger.Panic("Webhook handler must have exactly one webhook option")
 	}
-
-	webhookId := options[0].GetInvoke().Value
 
 	logger.Info("Creating webhook handler", zap.String("webhookId", webhookId))
 	handler := func(context.Context, *pb.DispatchRequest) (*pb.DispatchHandlerInvoke, error) {
@@ -64,6 +93,7 @@ func NewWebhookHandlerEntry(
 			dispatchId,
 			name,
 			timeout,
+			options...,
 		),
 		logger:    logger,
 		handler:   handler,
@@ -76,3 +106,14 @@ func NewWebhookHandlerEntry(
 func (h *WebhookHandlerEntry) Tag() string {
 	return h.webhookId
 }
+
+// WebhookOptions merges the HandlerWebhookOptions in options into one.
+func WebhookOptions(options []*pb.HandlerOption) *pb.HandlerWebhookOption {
+	merged := &pb.HandlerWebhookOption{}
+	for _, option := range options {
+		if webhook := option.GetWebhook(); webhook != nil {
+			proto.Merge(merged, webhook)
+		}
+	}
+	return merged
+}
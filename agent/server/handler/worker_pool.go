@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"strings"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+)
+
+// workerPoolPrefix prefixes the dispatch id a worker pool's handlers are
+// registered under.
+const workerPoolPrefix = "pool:"
+
+// workerPool is a set of clients sharing the handlers registered in it. The
+// handlers are registered once, under the pool's dispatch id, and each of
+// their invocations is assigned to one of the connected members, whose
+// dispatch queue it is pushed onto. Invocations triggered while no member is
+// connected wait in the pool's own queue.
+type workerPool struct {
+	name     string
+	strategy pb.WorkerPoolStrategy
+
+	// members are the connected clients in the order they connected, and
+	// assigned the invocations assigned to each that haven't completed
+	members  []string
+	assigned map[string]map[Invocable]struct{}
+	next     int
+}
+
+func (p *workerPool) dispatchId() string {
+	return workerPoolPrefix + p.name
+}
+
+func (p *workerPool) isConnected(member string) bool {
+	for _, m := range p.members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+// pick chooses the member to assign an invocation to, or returns "" if no
+// member is connected.
+func (p *workerPool) pick() string {
+	if len(p.members) == 0 {
+		return ""
+	}
+	selected := p.next % len(p.members)
+	if p.strategy == pb.WorkerPoolStrategy_WORKER_POOL_LEAST_OUTSTANDING {
+		// ties go to the next member in turn
+		for i := range p.members {
+			candidate := (p.next + i) % len(p.members)
+			if len(p.assigned[p.members[candidate]]) < len(p.assigned[p.members[selected]]) {
+				selected = candidate
+			}
+		}
+	}
+	p.next = selected + 1
+	return p.members[selected]
+}
+
+// JoinWorkerPool makes dispatchId a member of the named worker pool, so the
+// handlers it registers are shared with the pool's other members. The pool's
+// strategy is set by its first member.
+func (s *handlerManager) JoinWorkerPool(dispatchId string, name string, strategy pb.WorkerPoolStrategy) {
+	s.poolsLock.Lock()
+	defer s.poolsLock.Unlock()
+
+	pool, ok := s.pools[name]
+	if !ok {
+		pool = &workerPool{
+			name:     name,
+			strategy: strategy,
+			assigned: make(map[string]map[Invocable]struct{}),
+		}
+		s.pools[name] = pool
+	} else if pool.strategy != strategy {
+		s.logger.Warn("Worker pool already has a different strategy",
+			zap.String("pool", name),
+			zap.String("strategy", pool.strategy.String()),
+		)
+	}
+	s.poolMembers[dispatchId] = pool
+}
+
+// poolOf returns the worker pool dispatchId is a member of, or nil.
+func (s *handlerManager) poolOf(dispatchId string) *workerPool {
+	s.poolsLock.Lock()
+	defer s.poolsLock.Unlock()
+	return s.poolMembers[dispatchId]
+}
+
+// entryDispatchId returns the dispatch id the handlers of dispatchId are
+// registered under, which is its pool's for a worker pool member.
+func (s *handlerManager) entryDispatchId(dispatchId string) string {
+	if pool := s.poolOf(dispatchId); pool != nil {
+		return pool.dispatchId()
+	}
+	return dispatchId
+}
+
+// route returns the queue to push an invocation of a handler registered under
+// dispatchId onto. Invocations of a worker pool's handlers are assigned to
+// one of its members, and tracked until they complete.
+func (s *handlerManager) route(dispatchId string, invoke Invocable) (*dispatchQueue, func()) {
+	if !strings.HasPrefix(dispatchId, workerPoolPrefix) {
+		return s.getDispatchQueue(dispatchId), func() {}
+	}
+
+	s.poolsLock.Lock()
+	pool := s.pools[strings.TrimPrefix(dispatchId, workerPoolPrefix)]
+	member := ""
+	if pool != nil {
+		member = pool.pick()
+	}
+	if member == "" {
+		s.poolsLock.Unlock()
+		return s.getDispatchQueue(dispatchId), func() {}
+	}
+	if pool.assigned[member] == nil {
+		pool.assigned[member] = make(map[Invocable]struct{})
+	}
+	pool.assigned[member][invoke] = struct{}{}
+	// while the member can't disconnect, which removes its queue
+	queue := s.getDispatchQueue(member)
+	s.poolsLock.Unlock()
+
+	unassign := func() {
+		s.poolsLock.Lock()
+		defer s.poolsLock.Unlock()
+		delete(pool.assigned[member], invoke)
+	}
+	go func() {
+		<-invoke.Done()
+		unassign()
+	}()
+	return queue, unassign
+}
+
+// connect adds a member to its pool, and hands it invocations that were
+// waiting for a member to connect.
+func (s *handlerManager) connect(pool *workerPool, member string) {
+	s.poolsLock.Lock()
+	if !pool.isConnected(member) {
+		pool.members = append(pool.members, member)
+	}
+	s.poolsLock.Unlock()
+
+	for _, invoke := range s.getDispatchQueue(pool.dispatchId()).drain() {
+		s.requeue(pool, invoke)
+	}
+}
+
+// disconnect removes a member from its pool and requeues the invocations
+// assigned to it that haven't completed, including those it was sent but
+// hasn't reported. It returns whether members remain connected.
+func (s *handlerManager) disconnect(pool *workerPool, member string) bool {
+	s.poolsLock.Lock()
+	for i, m := range pool.members {
+		if m == member {
+			pool.members = append(pool.members[:i], pool.members[i+1:]...)
+			break
+		}
+	}
+	orphaned := []Invocable{}
+	for invoke := range pool.assigned[member] {
+		orphaned = append(orphaned, invoke)
+	}
+	delete(pool.assigned, member)
+	connected := len(pool.members) > 0
+	s.poolsLock.Unlock()
+
+	// the member's queue holds only invocations assigned to it, which are
+	// requeued below, and a reconnecting client gets a new dispatch id, so
+	// the queue is removed. Draining it takes its invocations off the depth
+	// gauge, which is labelled by handler rather than dispatch id.
+	if queue := s.removeDispatchQueue(member); queue != nil {
+		queue.drain()
+	}
+
+	for _, invoke := range orphaned {
+		select {
+		case <-invoke.Done():
+			continue
+		default:
+		}
+		s.requeue(pool, invoke)
+	}
+	return connected
+}
+
+func (s *handlerManager) requeue(pool *workerPool, invoke Invocable) {
+	logger := s.invokeLogger(invoke.GetEntry(), invoke).With(zap.String("pool", pool.name))
+	logger.Info("Requeuing worker pool invocation")
+	if err := s.enqueue(pool.dispatchId(), invoke, logger); err != nil {
+		invoke.Complete("", err)
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestWorkerPool(t *testing.T, strategy pb.WorkerPoolStrategy, members ...string) Manager {
+	logger, _ := zap.NewDevelopment()
+	mgr := NewHandlerManager(logger, cron.NewNoopCron(), nil)
+
+	ids := map[string]bool{}
+	for _, member := range members {
+		mgr.JoinWorkerPool(member, "workers", strategy)
+		id, err := mgr.RegisterHandler(member, "handler1", defaultTimeout)
+		require.NoError(t, err)
+		ids[id] = true
+		require.NoError(t, mgr.Start(member))
+	}
+	// the members share a single registration
+	require.Len(t, ids, 1)
+	require.Len(t, mgr.ListHandlers(), 1)
+	return mgr
+}
+
+func dequeueAll(t *testing.T, mgr Manager, member string) []Invocable {
+	invocations := []Invocable{}
+	for {
+		invoke, err := mgr.Dequeue(context.Background(), member, 10*time.Millisecond)
+		require.NoError(t, err)
+		if invoke == nil {
+			return invocations
+		}
+		invocations = append(invocations, invoke)
+	}
+}
+
+func TestWorkerPoolRoundRobin(t *testing.T) {
+	mgr := newTestWorkerPool(t, pb.WorkerPoolStrategy_WORKER_POOL_ROUND_ROBIN, "a", "b", "c")
+	entry := mgr.GetByTag("handler1")
+
+	for range 6 {
+		require.NoError(t, mgr.Trigger(NewInvokeHandlerInvoke(entry, "")))
+	}
+	require.Len(t, dequeueAll(t, mgr, "a"), 2)
+	require.Len(t, dequeueAll(t, mgr, "b"), 2)
+	require.Len(t, dequeueAll(t, mgr, "c"), 2)
+}
+
+func TestWorkerPoolLeastOutstanding(t *testing.T) {
+	mgr := newTestWorkerPool(t, pb.WorkerPoolStrategy_WORKER_POOL_LEAST_OUTSTANDING, "a", "b")
+	entry := mgr.GetByTag("handler1")
+
+	first := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(first))
+	second := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(second))
+	require.Len(t, dequeueAll(t, mgr, "a"), 1)
+	require.Len(t, dequeueAll(t, mgr, "b"), 1)
+
+	// it's a's turn, but once b's invocation completes b has the fewest
+	// outstanding
+	require.NoError(t, second.Complete("", nil))
+	require.Eventually(t, func() bool {
+		third := NewInvokeHandlerInvoke(entry, "")
+		require.NoError(t, mgr.Trigger(third))
+		if len(dequeueAll(t, mgr, "b")) == 1 {
+			return true
+		}
+		third.Complete("", nil)
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWorkerPoolRequeueOnDisconnect(t *testing.T) {
+	mgr := newTestWorkerPool(t, pb.WorkerPoolStrategy_WORKER_POOL_ROUND_ROBIN, "a", "b")
+	entry := mgr.GetByTag("handler1")
+
+	sent := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(sent))
+	queued := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(queued))
+	queuedForA := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(queuedForA))
+
+	invoke, err := mgr.Dequeue(context.Background(), "a", time.Second)
+	require.NoError(t, err)
+	require.Same(t, sent, invoke)
+
+	// a's invocations, sent or still queued, go to b when it disconnects
+	require.NoError(t, mgr.Stop("a"))
+	require.True(t, entry.IsActive())
+	requeued := dequeueAll(t, mgr, "b")
+	require.ElementsMatch(t, []Invocable{sent, queued, queuedForA}, requeued)
+	for _, invoke := range requeued {
+		require.NoError(t, invoke.Complete("", nil))
+	}
+
+	// and its dispatch queue is removed rather than left behind
+	manager := mgr.(*handlerManager)
+	manager.queuesLock.Lock()
+	require.NotContains(t, manager.dispatchQueues, "a")
+	manager.queuesLock.Unlock()
+	require.Equal(t, 0.0, queueDepth(t, manager.queueDepthGauge, "handler1", priorityInvoke.String()))
+
+	// with no members left, b's invocation waits for one to connect
+	last := NewInvokeHandlerInvoke(entry, "")
+	require.NoError(t, mgr.Trigger(last))
+	require.NoError(t, mgr.Stop("b"))
+	require.False(t, entry.IsActive())
+	require.NoError(t, mgr.Start("a"))
+	require.Equal(t, []Invocable{last}, dequeueAll(t, mgr, "a"))
+}
@@ -2,22 +2,27 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
 	"github.com/cortexapps/axon/config"
 	"github.com/cortexapps/axon/server/handler"
+	"github.com/cortexapps/axon/server/leader"
 	"github.com/gorilla/mux"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
-const AxonPathRoot = "/__axon"
+const AxonPathRoot = config.AxonPathRoot
 
 type axonHandler struct {
 	io.Closer
@@ -25,6 +30,8 @@ type axonHandler struct {
 	logger         *zap.Logger
 	client         pb.AxonAgentClient
 	handlerManager handler.Manager
+	deadLetters    DeadLetterStore
+	elector        leader.Elector
 }
 
 type AxonHandlerParams struct {
@@ -33,6 +40,8 @@ type AxonHandlerParams struct {
 	Logger         *zap.Logger
 	Config         config.AgentConfig
 	HandlerManager handler.Manager `optional:"true"`
+	DeadLetters    DeadLetterStore `optional:"true"`
+	Elector        leader.Elector  `optional:"true"`
 }
 
 func NewAxonHandler(p AxonHandlerParams) RegisterableHandler {
@@ -41,6 +50,8 @@ func NewAxonHandler(p AxonHandlerParams) RegisterableHandler {
 		config:         p.Config,
 		logger:         p.Logger,
 		handlerManager: p.HandlerManager,
+		deadLetters:    p.DeadLetters,
+		elector:        p.Elector,
 	}
 
 	return handler
@@ -65,8 +76,17 @@ func (h *axonHandler) RegisterRoutes(mux *mux.Router) error {
 	subRouter := mux.PathPrefix(AxonPathRoot).Subrouter()
 
 	subRouter.HandleFunc("/handlers/{handler}/invoke", h.invokeHandler)
+	subRouter.HandleFunc("/handlers/{handler}/pause", h.pauseHandler)
+	subRouter.HandleFunc("/handlers/{handler}/resume", h.resumeHandler)
+	subRouter.HandleFunc("/handlers/{handler}/trigger", h.triggerHandler)
+	subRouter.HandleFunc("/handlers/{handler}/events", h.handlerEvents)
 	subRouter.HandleFunc("/handlers/{handler}", h.getHandler)
 	subRouter.HandleFunc("/handlers", h.listHandlers)
+	subRouter.HandleFunc("/invocations/{id}/cancel", h.cancelInvocation)
+	subRouter.HandleFunc("/invocations", h.listInvocations)
+	subRouter.HandleFunc("/deadletter/{id}/replay", h.replayDeadLetter)
+	subRouter.HandleFunc("/deadletter/{id}", h.deadLetter)
+	subRouter.HandleFunc("/deadletter", h.listDeadLetters)
 	subRouter.HandleFunc("/healthcheck", h.healthcheck)
 	subRouter.HandleFunc("/info", h.info)
 	return nil
@@ -112,12 +132,14 @@ func (h *axonHandler) info(w http.ResponseWriter, r *http.Request) {
 		Handlers    []string `json:"handlers"`
 		InstanceID  string   `json:"instance_id"`
 		BuildVersion string   `json:"build_version"`
+		Leader       bool     `json:"leader"`
 	}{
 		InstanceID:  h.config.InstanceId,
 		Integration: h.config.Integration,
 		Alias:       h.config.IntegrationAlias,
 		Handlers:    []string{},
 		BuildVersion: getBuildVersion(),
+		Leader:       h.elector == nil || h.elector.IsLeader(),
 	}
 
 	handlers, err := h.fetchHandlers(r)
@@ -159,6 +181,11 @@ func (h *axonHandler) listHandlers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("view") == "dag" {
+		h.returnJson(newHandlerGraph(handlers), w)
+		return
+	}
+
 	h.returnJson(handlers, w)
 }
 
@@ -176,14 +203,23 @@ func (h *axonHandler) getHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := client.GetHandlerHistory(r.Context(), &pb.GetHandlerHistoryRequest{
-		HandlerName: handlerName,
-		Tail:        100,
-		IncludeLogs: true,
-	})
+	req, err := newHistoryRequest(handlerName, r.URL.Query(), time.Now())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := client.GetHandlerHistory(r.Context(), req)
+	if status.Code(err) == codes.InvalidArgument {
+		h.writeError(w, http.StatusBadRequest, status.Convert(err).Message())
+		return
+	}
 	if h.returnError(err, w) {
 		return
 	}
+	if result.NextCursor != "" {
+		w.Header().Set(NextCursorHeader, result.NextCursor)
+	}
 	h.returnJson(result.History, w)
 
 }
@@ -222,6 +258,38 @@ func (h *axonHandler) invokeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if handler.ErrorCode(err) == handler.ErrorCodeSkipped {
+		h.logger.Warn("Handler is at its concurrency limit", zap.String("handler", handlerName))
+		h.writeError(w, http.StatusTooManyRequests, fmt.Sprintf("Handler '%s' is at its concurrency limit", handlerName))
+		return
+	}
+
+	if errors.Is(err, handler.ErrQueued) {
+		h.logger.Info("Handler is not connected, invocation queued", zap.String("handler", handlerName))
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "queued"})
+		return
+	}
+
+	if errors.Is(err, handler.ErrDispatchQueueFull) {
+		h.logger.Warn("Dispatch queue is full", zap.String("handler", handlerName))
+		h.writeError(w, http.StatusTooManyRequests, fmt.Sprintf("Handler '%s' dispatch queue is full", handlerName))
+		return
+	}
+
+	if errors.Is(err, handler.ErrQueueFull) {
+		h.logger.Warn("Handler queue is full", zap.String("handler", handlerName))
+		h.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Handler '%s' queue is full", handlerName))
+		return
+	}
+
+	if errors.Is(err, handler.ErrDraining) {
+		h.logger.Warn("Rejecting invocation while draining", zap.String("handler", handlerName))
+		h.writeError(w, http.StatusServiceUnavailable, "Agent is shutting down")
+		return
+	}
+
 	if err != nil {
 
 		h.logger.Error("Handler failed", zap.Error(err))
@@ -237,6 +305,245 @@ func (h *axonHandler) invokeHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *axonHandler) pauseHandler(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+func (h *axonHandler) resumeHandler(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *axonHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.handlerManager == nil {
+		h.logger.Error("No handler manager")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	handlerName := mux.Vars(r)["handler"]
+	err := handler.SetPaused(h.handlerManager, handlerName, paused)
+	if h.writeHandlerControlError(w, handlerName, err) {
+		return
+	}
+
+	status := "resumed"
+	if paused {
+		status = "paused"
+	}
+	h.returnJson(map[string]string{"status": status}, w)
+}
+
+// triggerHandler queues a RUN_NOW invocation of a scheduled handler and
+// returns without waiting for it to run.
+func (h *axonHandler) triggerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.handlerManager == nil {
+		h.logger.Error("No handler manager")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	handlerName := mux.Vars(r)["handler"]
+	invoke, err := handler.TriggerRun(h.handlerManager, handlerName)
+	if h.writeHandlerControlError(w, handlerName, err) {
+		return
+	}
+
+	h.logger.Info("Triggered handler", zap.String("handler", handlerName))
+	h.returnJson(map[string]string{
+		"status":        "ok",
+		"invocation_id": invoke.ToDispatchInvoke().InvocationId,
+	}, w)
+}
+
+// listInvocations returns the queued, in flight and recently completed
+// invocations, only those of one handler with ?handler=NAME.
+func (h *axonHandler) listInvocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	client, err := h.grpcClient()
+	if h.returnError(err, w) {
+		return
+	}
+
+	result, err := client.ListInvocations(r.Context(), &pb.ListInvocationsRequest{
+		HandlerName: r.URL.Query().Get("handler"),
+	})
+	if h.returnError(err, w) {
+		return
+	}
+	h.returnJson(result.Invocations, w)
+}
+
+// cancelInvocation cancels a queued or running invocation, telling the
+// client running it to stop.
+func (h *axonHandler) cancelInvocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	client, err := h.grpcClient()
+	if h.returnError(err, w) {
+		return
+	}
+
+	invocationId := mux.Vars(r)["id"]
+	_, err = client.CancelInvocation(r.Context(), &pb.CancelInvocationRequest{InvocationId: invocationId})
+	if status.Code(err) == codes.NotFound {
+		h.writeError(w, http.StatusNotFound, fmt.Sprintf("Invocation '%s' not found", invocationId))
+		return
+	}
+	if h.returnError(err, w) {
+		return
+	}
+
+	h.logger.Info("Cancelled invocation", zap.String("invocation-id", invocationId))
+	h.returnJson(map[string]string{"status": "cancelled"}, w)
+}
+
+func (h *axonHandler) writeHandlerControlError(w http.ResponseWriter, handlerName string, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, os.ErrNotExist):
+		h.writeError(w, http.StatusNotFound, fmt.Sprintf("Handler '%s' not found", handlerName))
+	case errors.Is(err, handler.ErrNotScheduled):
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Handler '%s' is not a scheduled handler", handlerName))
+	case errors.Is(err, handler.ErrDispatchQueueFull):
+		h.writeError(w, http.StatusTooManyRequests, fmt.Sprintf("Handler '%s' dispatch queue is full", handlerName))
+	case errors.Is(err, handler.ErrQueueFull):
+		h.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Handler '%s' queue is full", handlerName))
+	case errors.Is(err, handler.ErrDraining):
+		h.writeError(w, http.StatusServiceUnavailable, "Agent is shutting down")
+	default:
+		h.logger.Error("Handler control failed", zap.String("handler", handlerName), zap.Error(err))
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+	}
+	return true
+}
+
+func (h *axonHandler) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.deadLetters == nil {
+		h.writeError(w, http.StatusNotFound, "Dead letters are not enabled")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		letters, err := h.deadLetters.List()
+		if h.returnError(err, w) {
+			return
+		}
+		for i, letter := range letters {
+			letters[i] = redactedDeadLetter(h.handlerManager, h.config.WebhookRedactHeaders, letter)
+		}
+		h.returnJson(letters, w)
+	case http.MethodDelete:
+		count, err := h.deadLetters.Purge()
+		if h.returnError(err, w) {
+			return
+		}
+		h.logger.Info("Purged dead letters", zap.Int("count", count))
+		h.returnJson(map[string]int{"purged": count}, w)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *axonHandler) deadLetter(w http.ResponseWriter, r *http.Request) {
+	if h.deadLetters == nil {
+		h.writeError(w, http.StatusNotFound, "Dead letters are not enabled")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	switch r.Method {
+	case http.MethodGet:
+		letter, err := h.deadLetters.Get(id)
+		if errors.Is(err, os.ErrNotExist) {
+			h.writeError(w, http.StatusNotFound, fmt.Sprintf("Dead letter '%s' not found", id))
+			return
+		}
+		if h.returnError(err, w) {
+			return
+		}
+		h.returnJson(redactedDeadLetter(h.handlerManager, h.config.WebhookRedactHeaders, letter), w)
+	case http.MethodDelete:
+		err := h.deadLetters.Delete(id)
+		if errors.Is(err, os.ErrNotExist) {
+			h.writeError(w, http.StatusNotFound, fmt.Sprintf("Dead letter '%s' not found", id))
+			return
+		}
+		if h.returnError(err, w) {
+			return
+		}
+		h.returnJson(map[string]int{"purged": 1}, w)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// replayDeadLetter re-delivers a dead letter to its webhook handler. The dead
+// letter is removed once the delivery is queued; if it fails again it is
+// added back under the new invocation's id.
+func (h *axonHandler) replayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.deadLetters == nil || h.handlerManager == nil {
+		h.writeError(w, http.StatusNotFound, "Dead letters are not enabled")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	letter, err := h.deadLetters.Get(id)
+	if errors.Is(err, os.ErrNotExist) {
+		h.writeError(w, http.StatusNotFound, fmt.Sprintf("Dead letter '%s' not found", id))
+		return
+	}
+	if h.returnError(err, w) {
+		return
+	}
+
+	letter.Replays++
+	invoke, err := deliverWebhook(h.handlerManager, h.deadLetters, h.logger, h.config.WebhookRedactHeaders, letter)
+	if errors.Is(err, os.ErrNotExist) {
+		h.writeError(w, http.StatusNotFound, fmt.Sprintf("Webhook '%s' not found", letter.WebhookId))
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to replay dead letter", zap.String("id", id), zap.Error(err))
+		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Replay failed: %s", err.Error()))
+		return
+	}
+
+	if err := h.deadLetters.Delete(id); err != nil {
+		h.logger.Error("Failed to remove replayed dead letter", zap.String("id", id), zap.Error(err))
+	}
+
+	h.logger.Info("Replayed dead letter", zap.String("id", id), zap.String("webhookId", letter.WebhookId))
+	h.returnJson(map[string]string{
+		"status":        "ok",
+		"invocation_id": invoke.ToDispatchInvoke().InvocationId,
+	}, w)
+}
+
 func (h *axonHandler) writeError(w http.ResponseWriter, status int, message string) {
 	errMap := map[string]string{
 		"error": message,
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -135,3 +136,65 @@ func TestInvokeEndpointErr(t *testing.T) {
 	require.Equal(t, "{\"error\":\"Handler failed: nope didn't work\"}", string(body))
 
 }
+
+func TestHandlerControlEndpoints(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := manager.RegisterHandler("1", "scheduled", time.Minute, &pb.HandlerOption{
+		Option: &pb.HandlerOption_Invoke{
+			Invoke: &pb.HandlerInvokeOption{Type: pb.HandlerInvokeType_CRON_SCHEDULE, Value: "@yearly"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = manager.RegisterHandler("1", "on-demand", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	axonHandler := NewAxonHandler(AxonHandlerParams{
+		Logger:         logger,
+		Config:         config.AgentConfig{},
+		HandlerManager: manager,
+	})
+	mux := mux.NewRouter()
+	axonHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(path string) (int, map[string]string) {
+		resp, err := http.Post(ts.URL+"/__axon/handlers/"+path, "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		result := map[string]string{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+	isPaused := func() bool {
+		return manager.GetByTag("scheduled").(handler.Pausable).IsPaused()
+	}
+
+	status, result := post("scheduled/pause")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "paused", result["status"])
+	require.True(t, isPaused())
+
+	// a paused handler can still be triggered by hand
+	status, result = post("scheduled/trigger")
+	require.Equal(t, http.StatusOK, status)
+	invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	require.Equal(t, result["invocation_id"], invoke.ToDispatchInvoke().InvocationId)
+	require.Equal(t, pb.HandlerInvokeType_RUN_NOW, invoke.ToDispatchInvoke().Reason)
+
+	status, result = post("scheduled/resume")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "resumed", result["status"])
+	require.False(t, isPaused())
+
+	status, _ = post("on-demand/pause")
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = post("missing/trigger")
+	require.Equal(t, http.StatusNotFound, status)
+}
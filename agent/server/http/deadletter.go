@@ -0,0 +1,240 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/handler"
+	"go.uber.org/zap"
+)
+
+// DeadLetter is a webhook delivery whose handler failed or timed out, kept so
+// it can be replayed once the handler is fixed. Headers hold the request's
+// original values so a replay can be redacted the same way as the first
+// delivery; they are only shown redacted.
+type DeadLetter struct {
+	Id           string              `json:"id"`
+	WebhookId    string              `json:"webhook_id"`
+	HandlerName  string              `json:"handler_name"`
+	InvocationId string              `json:"invocation_id"`
+	URL          string              `json:"url"`
+	Method       string              `json:"method,omitempty"`
+	RemoteAddr   string              `json:"remote_addr,omitempty"`
+	ContentType  string              `json:"content_type"`
+	Headers      map[string][]string `json:"headers,omitempty"`
+	Body         string              `json:"body"`
+	ErrorCode    string              `json:"error_code"`
+	Error        string              `json:"error"`
+	FailedAt     time.Time           `json:"failed_at"`
+	Replays      int                 `json:"replays"`
+}
+
+type DeadLetterStore interface {
+	Write(letter *DeadLetter) error
+	List() ([]*DeadLetter, error)
+	Get(id string) (*DeadLetter, error)
+	Delete(id string) error
+	Purge() (int, error)
+}
+
+type deadLetterStore struct {
+	config config.AgentConfig
+	logger *zap.Logger
+}
+
+// NewDeadLetterStore stores dead letters as one JSON file each under the
+// configured dead-letter path.
+func NewDeadLetterStore(config config.AgentConfig, logger *zap.Logger) DeadLetterStore {
+	return &deadLetterStore{
+		config: config,
+		logger: logger.Named("deadletter"),
+	}
+}
+
+var deadLetterIdRegExp = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+func (s *deadLetterStore) path(id string) (string, error) {
+	// ids come from request paths, keep them inside the directory
+	if !deadLetterIdRegExp.MatchString(id) {
+		return "", os.ErrNotExist
+	}
+	return filepath.Join(s.config.WebhookDeadLetterPath, id+".json"), nil
+}
+
+func (s *deadLetterStore) Write(letter *DeadLetter) error {
+	// dead letters hold unredacted headers, keep them private to the agent
+	if err := os.MkdirAll(s.config.WebhookDeadLetterPath, 0700); err != nil {
+		return err
+	}
+	path, err := s.path(letter.Id)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// List returns all dead letters, oldest first.
+func (s *deadLetterStore) List() ([]*DeadLetter, error) {
+	files, err := os.ReadDir(s.config.WebhookDeadLetterPath)
+	if os.IsNotExist(err) {
+		return []*DeadLetter{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]*DeadLetter, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		letter, err := s.Get(file.Name()[:len(file.Name())-len(".json")])
+		if err != nil {
+			s.logger.Warn("Skipping unreadable dead letter", zap.String("file", file.Name()), zap.Error(err))
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+	return letters, nil
+}
+
+func (s *deadLetterStore) Get(id string) (*DeadLetter, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	letter := &DeadLetter{}
+	if err := json.Unmarshal(data, letter); err != nil {
+		return nil, err
+	}
+	return letter, nil
+}
+
+func (s *deadLetterStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (s *deadLetterStore) Purge() (int, error) {
+	letters, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, letter := range letters {
+		if err := s.Delete(letter.Id); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// webhookEntry returns the handler letter is addressed to, or if it doesn't
+// name one, the first handler subscribed to its webhook.
+func webhookEntry(manager handler.Manager, letter *DeadLetter) handler.HandlerEntry {
+	entries := manager.ListByTag(letter.WebhookId)
+	for _, entry := range entries {
+		if letter.HandlerName == "" || entry.Name() == letter.HandlerName {
+			return entry
+		}
+	}
+	return nil
+}
+
+// redactedDeadLetter returns a copy of letter with its headers redacted the
+// way its handler sees them, for showing outside the agent.
+func redactedDeadLetter(manager handler.Manager, redact []string, letter *DeadLetter) *DeadLetter {
+	redacted := *letter
+	var handlerRedact []string
+	if manager != nil {
+		if entry := webhookEntry(manager, letter); entry != nil {
+			handlerRedact = handler.WebhookOptions(entry.Options()).RedactHeaders
+		}
+	}
+	redacted.Headers = redactHeaders(letter.Headers, redact, handlerRedact)
+	return &redacted
+}
+
+// deliverWebhook triggers letter's webhook handler with its headers redacted
+// by redact and the handler's own redact list and, if the invocation fails,
+// writes letter back to the store with the failure and the original headers.
+// It returns the queued invocation without waiting for it.
+func deliverWebhook(manager handler.Manager, store DeadLetterStore, logger *zap.Logger, redact []string, letter *DeadLetter) (handler.Invocable, error) {
+	entry := webhookEntry(manager, letter)
+	if entry == nil {
+		return nil, os.ErrNotExist
+	}
+
+	webhookUrl, err := url.Parse(letter.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	method := letter.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	invoke := handler.NewWebhookHandlerInvoke(entry, handler.WebhookRequest{
+		URL:         webhookUrl,
+		Method:      method,
+		Path:        webhookSubPath(webhookUrl.Path, letter.WebhookId),
+		RemoteAddr:  letter.RemoteAddr,
+		ContentType: letter.ContentType,
+		Headers:     redactHeaders(letter.Headers, redact, handler.WebhookOptions(entry.Options()).RedactHeaders),
+		Body:        letter.Body,
+	})
+	if err := manager.Trigger(invoke); err != nil {
+		return nil, err
+	}
+
+	if store != nil {
+		go func() {
+			<-invoke.Done()
+			_, err := invoke.GetResult()
+			if err == nil || errors.Is(err, handler.ErrQueued) {
+				return
+			}
+			failed := *letter
+			failed.Id = invoke.ToDispatchInvoke().InvocationId
+			failed.HandlerName = entry.Name()
+			failed.InvocationId = failed.Id
+			failed.ErrorCode = handler.ErrorCode(err)
+			failed.Error = err.Error()
+			failed.FailedAt = time.Now()
+			if err := store.Write(&failed); err != nil {
+				logger.Error("Failed to write dead letter", zap.String("webhookId", letter.WebhookId), zap.Error(err))
+				return
+			}
+			logger.Warn("Webhook delivery failed, added to dead letters",
+				zap.String("webhookId", letter.WebhookId),
+				zap.String("id", failed.Id),
+				zap.String("code", failed.ErrorCode),
+			)
+		}()
+	}
+	return invoke, nil
+}
@@ -0,0 +1,275 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/handler"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func webhookOption(webhookId string) *pb.HandlerOption {
+	return &pb.HandlerOption{
+		Option: &pb.HandlerOption_Invoke{
+			Invoke: &pb.HandlerInvokeOption{
+				Type:  pb.HandlerInvokeType_WEBHOOK,
+				Value: webhookId,
+			},
+		},
+	}
+}
+
+func TestDeadLetterStore(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	store := NewDeadLetterStore(config.AgentConfig{WebhookDeadLetterPath: t.TempDir()}, logger)
+
+	letters, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, letters)
+
+	now := time.Now()
+	require.NoError(t, store.Write(&DeadLetter{Id: "b", WebhookId: "hook", Body: "second", FailedAt: now}))
+	require.NoError(t, store.Write(&DeadLetter{Id: "a", WebhookId: "hook", Body: "first", FailedAt: now.Add(-time.Minute)}))
+
+	letters, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	require.Equal(t, "a", letters[0].Id)
+	require.Equal(t, "b", letters[1].Id)
+
+	letter, err := store.Get("b")
+	require.NoError(t, err)
+	require.Equal(t, "second", letter.Body)
+
+	// dead letters keep unredacted headers, so only the agent can read them
+	info, err := os.Stat(filepath.Join(store.(*deadLetterStore).config.WebhookDeadLetterPath, "b.json"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = store.Get("../b")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, store.Delete("a"))
+	_, err = store.Get("a")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	count, err := store.Purge()
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestWebhookFailureIsDeadLettered(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+	store := NewDeadLetterStore(config.AgentConfig{WebhookDeadLetterPath: t.TempDir()}, logger)
+
+	_, err := manager.RegisterHandler("1", "test", time.Minute, webhookOption("my-webhook-id"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	cfg := config.AgentConfig{WebhookRedactHeaders: config.DefaultWebhookRedactHeaders}
+	webhookHandler := NewWebhookHandler(cfg, logger, manager, nil, WithDeadLetterStore(store))
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/webhook/my-webhook-id", strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", "push")
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	headers := http.Header{}
+	require.NoError(t, json.Unmarshal([]byte(invoke.ToDispatchInvoke().Args["headers"]), &headers))
+	require.Equal(t, redactedHeaderValue, headers.Get("Authorization"))
+	invoke.Complete("", &handler.InvocationError{Code: handler.ErrorCodeUnexpected, Message: "boom"})
+
+	var letters []*DeadLetter
+	require.Eventually(t, func() bool {
+		letters, err = store.List()
+		return err == nil && len(letters) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	letter := letters[0]
+	require.Equal(t, invoke.ToDispatchInvoke().InvocationId, letter.Id)
+	require.Equal(t, "my-webhook-id", letter.WebhookId)
+	require.Equal(t, "test", letter.HandlerName)
+	require.Equal(t, "payload", letter.Body)
+	require.Equal(t, "application/json", letter.ContentType)
+	require.Equal(t, "/webhook/my-webhook-id", letter.URL)
+	require.Equal(t, []string{"push"}, letter.Headers["X-Event"])
+	require.Equal(t, []string{"Bearer token"}, letter.Headers["Authorization"])
+	require.Equal(t, handler.ErrorCodeUnexpected, letter.ErrorCode)
+}
+
+func TestWebhookHeldWhileDisconnected(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	queue, err := handler.OpenDurableQueue(t.TempDir(), 0, logger)
+	require.NoError(t, err)
+	manager := handler.NewHandlerManager(logger, cron.New(), nil, handler.WithDurableQueue(queue))
+	defer manager.Close()
+	store := NewDeadLetterStore(config.AgentConfig{WebhookDeadLetterPath: t.TempDir()}, logger)
+
+	_, err = manager.RegisterHandler("1", "test", time.Minute, webhookOption("my-webhook-id"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+	require.NoError(t, manager.Stop("1"))
+
+	webhookHandler := NewWebhookHandler(config.AgentConfig{}, logger, manager, nil, WithDeadLetterStore(store))
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/webhook/my-webhook-id", "application/json", strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 1, queue.Len())
+
+	// a held webhook is not a failed delivery
+	time.Sleep(50 * time.Millisecond)
+	letters, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, letters)
+
+	require.NoError(t, manager.Start("1"))
+	invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	require.Equal(t, "payload", invoke.ToDispatchInvoke().Args["body"])
+}
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+	store := NewDeadLetterStore(config.AgentConfig{WebhookDeadLetterPath: t.TempDir()}, logger)
+
+	_, err := manager.RegisterHandler("1", "test", time.Minute,
+		webhookOption("my-webhook-id"),
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Webhook{
+				Webhook: &pb.HandlerWebhookOption{
+					RedactHeaders: []string{"x-api-key"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	axonHandler := NewAxonHandler(AxonHandlerParams{
+		Logger:         logger,
+		Config:         config.AgentConfig{WebhookRedactHeaders: config.DefaultWebhookRedactHeaders},
+		HandlerManager: manager,
+		DeadLetters:    store,
+	})
+	mux := mux.NewRouter()
+	axonHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	require.NoError(t, store.Write(&DeadLetter{
+		Id:          "letter-1",
+		WebhookId:   "my-webhook-id",
+		URL:         "/webhook/my-webhook-id",
+		ContentType: "application/json",
+		Headers: map[string][]string{
+			"Authorization": {"Bearer token"},
+			"X-Api-Key":     {"key"},
+			"X-Event":       {"push"},
+		},
+		Body:     "payload",
+		FailedAt: time.Now(),
+	}))
+
+	do := func(method string, path string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+"/__axon"+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/deadletter")
+	var letters []*DeadLetter
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&letters))
+	resp.Body.Close()
+	require.Len(t, letters, 1)
+	require.Equal(t, []string{redactedHeaderValue}, letters[0].Headers["Authorization"])
+	require.Equal(t, []string{redactedHeaderValue}, letters[0].Headers["X-Api-Key"])
+	require.Equal(t, []string{"push"}, letters[0].Headers["X-Event"])
+
+	resp = do(http.MethodGet, "/deadletter/letter-1")
+	letter := &DeadLetter{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(letter))
+	resp.Body.Close()
+	require.Equal(t, []string{redactedHeaderValue}, letter.Headers["Authorization"])
+
+	// the stored letter keeps the original values
+	stored, err := store.Get("letter-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bearer token"}, stored.Headers["Authorization"])
+
+	resp = do(http.MethodGet, "/deadletter/missing")
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/deadletter/letter-1/replay")
+	result := map[string]string{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	require.Equal(t, result["invocation_id"], invoke.ToDispatchInvoke().InvocationId)
+	require.Equal(t, "payload", invoke.ToDispatchInvoke().Args["body"])
+
+	// a replay is redacted the same way as the first delivery
+	headers := http.Header{}
+	require.NoError(t, json.Unmarshal([]byte(invoke.ToDispatchInvoke().Args["headers"]), &headers))
+	require.Equal(t, redactedHeaderValue, headers.Get("Authorization"))
+	require.Equal(t, redactedHeaderValue, headers.Get("X-Api-Key"))
+	require.Equal(t, "push", headers.Get("X-Event"))
+
+	_, err = store.Get("letter-1")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// a failed replay is dead-lettered again
+	invoke.Complete("", &handler.InvocationError{Code: handler.ErrorCodeTimeout})
+	require.Eventually(t, func() bool {
+		letter, err := store.Get(result["invocation_id"])
+		return err == nil && letter.Replays == 1
+	}, time.Second, 10*time.Millisecond)
+	replayed, err := store.Get(result["invocation_id"])
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bearer token"}, replayed.Headers["Authorization"])
+
+	resp = do(http.MethodDelete, "/deadletter")
+	purged := map[string]int{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&purged))
+	resp.Body.Close()
+	require.Equal(t, 1, purged["purged"])
+}
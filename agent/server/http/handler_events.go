@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// handlerEvents streams a handler's execution events as server-sent events
+// until the client disconnects. Each event is named for its type, like
+// queued or completed, and its data is the event as JSON. Set logs=false to
+// leave out log lines.
+func (h *axonHandler) handlerEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := &pb.WatchHandlerExecutionsRequest{
+		HandlerName: mux.Vars(r)["handler"],
+		IncludeLogs: true,
+	}
+	if logs := r.URL.Query().Get("logs"); logs != "" {
+		includeLogs, err := strconv.ParseBool(logs)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid logs: %s", logs))
+			return
+		}
+		req.IncludeLogs = includeLogs
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	client, err := h.grpcClient()
+	if h.returnError(err, w) {
+		return
+	}
+	stream, err := client.WatchHandlerExecutions(r.Context(), req)
+	if h.returnError(err, w) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF && r.Context().Err() == nil {
+				h.logger.Warn("Handler event stream ended", zap.String("handler", req.HandlerName), zap.Error(err))
+			}
+			return
+		}
+		if err := writeExecutionEvent(w, event); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeExecutionEvent writes an execution event in the server-sent events
+// format.
+func writeExecutionEvent(w io.Writer, event *pb.HandlerExecutionEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", executionEventName(event.Type), data)
+	return err
+}
+
+// executionEventName is the name of an event type in the server-sent events
+// stream, like timed_out for EXECUTION_EVENT_TIMED_OUT.
+func executionEventName(eventType pb.HandlerExecutionEventType) string {
+	return strings.ToLower(strings.TrimPrefix(eventType.String(), "EXECUTION_EVENT_"))
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type fakeWatchClient struct {
+	pb.AxonAgentClient
+	req    *pb.WatchHandlerExecutionsRequest
+	events []*pb.HandlerExecutionEvent
+}
+
+func (c *fakeWatchClient) WatchHandlerExecutions(ctx context.Context, in *pb.WatchHandlerExecutionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.HandlerExecutionEvent], error) {
+	c.req = in
+	return &fakeWatchStream{events: c.events}, nil
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStreamingClient[pb.HandlerExecutionEvent]
+	events []*pb.HandlerExecutionEvent
+}
+
+func (s *fakeWatchStream) Recv() (*pb.HandlerExecutionEvent, error) {
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func TestHandlerEventsEndpoint(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	client := &fakeWatchClient{
+		events: []*pb.HandlerExecutionEvent{
+			{Type: pb.HandlerExecutionEventType_EXECUTION_EVENT_QUEUED, HandlerName: "my-handler", InvocationId: "1"},
+			{Type: pb.HandlerExecutionEventType_EXECUTION_EVENT_LOG, HandlerName: "my-handler", InvocationId: "1", Log: &pb.Log{Level: "INFO", Message: "hello"}},
+			{Type: pb.HandlerExecutionEventType_EXECUTION_EVENT_TIMED_OUT, HandlerName: "my-handler", InvocationId: "1"},
+		},
+	}
+	handler := NewAxonHandler(AxonHandlerParams{
+		Logger: logger,
+		Config: config.AgentConfig{},
+	})
+	handler.(*axonHandler).client = client
+	mux := mux.NewRouter()
+	handler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/__axon/handlers/my-handler/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Equal(t, "my-handler", client.req.HandlerName)
+	require.True(t, client.req.IncludeLogs)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	messages := strings.Split(strings.TrimSuffix(string(body), "\n\n"), "\n\n")
+	require.Len(t, messages, 3)
+
+	names := make([]string, len(messages))
+	for i, message := range messages {
+		name, data, ok := strings.Cut(message, "\n")
+		require.True(t, ok)
+		names[i] = strings.TrimPrefix(name, "event: ")
+
+		event := &pb.HandlerExecutionEvent{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), event))
+		require.Equal(t, "1", event.InvocationId)
+	}
+	require.Equal(t, []string{"queued", "log", "timed_out"}, names)
+
+	resp, err = http.Get(ts.URL + "/__axon/handlers/my-handler/events?logs=false")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.False(t, client.req.IncludeLogs)
+
+	resp, err = http.Get(ts.URL + "/__axon/handlers/my-handler/events?logs=maybe")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
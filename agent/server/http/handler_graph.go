@@ -0,0 +1,59 @@
+package http
+
+import (
+	"sort"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/server/handler"
+)
+
+// handlerGraph is the DAG of handler dependencies returned by
+// /__axon/handlers?view=dag. Each edge runs from a handler to one that runs
+// after it succeeds.
+type handlerGraph struct {
+	Handlers []string           `json:"handlers"`
+	Edges    []handlerGraphEdge `json:"edges"`
+}
+
+type handlerGraphEdge struct {
+	Upstream   string `json:"upstream"`
+	Downstream string `json:"downstream"`
+	PassResult bool   `json:"pass_result"`
+}
+
+func newHandlerGraph(handlers []*pb.HandlerInfo) *handlerGraph {
+	graph := &handlerGraph{
+		Handlers: []string{},
+		Edges:    []handlerGraphEdge{},
+	}
+
+	// a handler registered by several clients is a single node
+	names := map[string]bool{}
+	edges := map[handlerGraphEdge]bool{}
+	for _, info := range handlers {
+		names[info.Name] = true
+		for _, dependency := range handler.Dependencies(info.Options) {
+			names[dependency.HandlerName] = true
+			edges[handlerGraphEdge{
+				Upstream:   dependency.HandlerName,
+				Downstream: info.Name,
+				PassResult: dependency.PassResult,
+			}] = true
+		}
+	}
+
+	for name := range names {
+		graph.Handlers = append(graph.Handlers, name)
+	}
+	sort.Strings(graph.Handlers)
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Upstream != graph.Edges[j].Upstream {
+			return graph.Edges[i].Upstream < graph.Edges[j].Upstream
+		}
+		return graph.Edges[i].Downstream < graph.Edges[j].Downstream
+	})
+	return graph
+}
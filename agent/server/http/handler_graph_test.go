@@ -0,0 +1,33 @@
+package http
+
+import (
+	"testing"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGraph(t *testing.T) {
+	dependency := func(name string, passResult bool) *pb.HandlerOption {
+		return &pb.HandlerOption{
+			Option: &pb.HandlerOption_Dependency{
+				Dependency: &pb.HandlerDependencyOption{HandlerName: name, PassResult: passResult},
+			},
+		}
+	}
+
+	graph := newHandlerGraph([]*pb.HandlerInfo{
+		{Name: "publish", Options: []*pb.HandlerOption{dependency("fetch", true)}},
+		{Name: "fetch"},
+		{Name: "notify", Options: []*pb.HandlerOption{dependency("publish", false), dependency("fetch", false)}},
+		// the same handler from a second client
+		{Name: "publish", Options: []*pb.HandlerOption{dependency("fetch", true)}},
+	})
+
+	require.Equal(t, []string{"fetch", "notify", "publish"}, graph.Handlers)
+	require.Equal(t, []handlerGraphEdge{
+		{Upstream: "fetch", Downstream: "notify"},
+		{Upstream: "fetch", Downstream: "publish", PassResult: true},
+		{Upstream: "publish", Downstream: "notify"},
+	}, graph.Edges)
+}
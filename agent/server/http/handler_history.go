@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/util"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NextCursorHeader is set on a handler history response that has earlier
+// executions, to the cursor that gets them.
+const NextCursorHeader = "X-Axon-Next-Cursor"
+
+// defaultHistoryTail is how many executions a handler history request
+// returns when it doesn't set tail.
+const defaultHistoryTail = 100
+
+// newHistoryRequest builds a history request from the query params of a
+// /handlers/{handler} request:
+//
+//	since, until   a time, or a duration like 1h before now
+//	tail           the most recent executions to return, 100 by default
+//	cursor         the X-Axon-Next-Cursor of the previous page
+//	result         error or success
+//	dispatch_id    the client the executions were sent to
+//	invocation_id  an invocation
+//	logs           false to leave out logs
+func newHistoryRequest(handlerName string, values url.Values, now time.Time) (*pb.GetHandlerHistoryRequest, error) {
+	req := &pb.GetHandlerHistoryRequest{
+		HandlerName:  handlerName,
+		Tail:         defaultHistoryTail,
+		IncludeLogs:  true,
+		Cursor:       values.Get("cursor"),
+		DispatchId:   values.Get("dispatch_id"),
+		InvocationId: values.Get("invocation_id"),
+	}
+
+	if since := values.Get("since"); since != "" {
+		t, err := util.TimeOrAgoFromString(since, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+		req.StartTime = timestamppb.New(t)
+	}
+	if until := values.Get("until"); until != "" {
+		t, err := util.TimeOrAgoFromString(until, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %w", err)
+		}
+		req.EndTime = timestamppb.New(t)
+	}
+
+	if tail := values.Get("tail"); tail != "" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid tail: %s", tail)
+		}
+		req.Tail = int32(n)
+	}
+
+	switch result := values.Get("result"); result {
+	case "":
+	case "error":
+		req.Result = pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR
+	case "success":
+		req.Result = pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS
+	default:
+		return nil, fmt.Errorf("invalid result: %s", result)
+	}
+
+	if logs := values.Get("logs"); logs != "" {
+		includeLogs, err := strconv.ParseBool(logs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid logs: %s", logs)
+		}
+		req.IncludeLogs = includeLogs
+	}
+	return req, nil
+}
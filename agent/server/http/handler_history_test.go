@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryRequest(t *testing.T) {
+	now := time.Now()
+
+	req, err := newHistoryRequest("handler1", url.Values{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "handler1", req.HandlerName)
+	require.Equal(t, int32(defaultHistoryTail), req.Tail)
+	require.True(t, req.IncludeLogs)
+	require.Nil(t, req.StartTime)
+	require.Nil(t, req.EndTime)
+
+	values, err := url.ParseQuery("since=2h&until=2024-01-02T03:04:05Z&tail=5&cursor=abc&result=error&dispatch_id=d1&invocation_id=i1&logs=false")
+	require.NoError(t, err)
+	req, err = newHistoryRequest("handler1", values, now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-2*time.Hour).UTC(), req.StartTime.AsTime())
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), req.EndTime.AsTime())
+	require.Equal(t, int32(5), req.Tail)
+	require.Equal(t, "abc", req.Cursor)
+	require.Equal(t, pb.HandlerRunResult_HANDLER_RUN_RESULT_ERROR, req.Result)
+	require.Equal(t, "d1", req.DispatchId)
+	require.Equal(t, "i1", req.InvocationId)
+	require.False(t, req.IncludeLogs)
+
+	for _, query := range []string{"since=yesterday", "tail=-1", "result=maybe", "logs=some"} {
+		values, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		_, err = newHistoryRequest("handler1", values, now)
+		require.Error(t, err, query)
+	}
+}
@@ -32,7 +32,7 @@ func TestHandleWebhook(t *testing.T) {
 		},
 	}
 
-	_, err := manager.RegisterHandler("1", "test", 1, option)
+	_, err := manager.RegisterHandler("1", "test", time.Minute, option)
 	require.NoError(t, err)
 
 	err = manager.Start("1")
@@ -69,6 +69,13 @@ func TestHandleWebhook(t *testing.T) {
 		"body":         "payload",
 		"content-type": "application/json",
 		"url":          "/webhook/my-webhook-id",
+		"method":       http.MethodPost,
+		"path":         "",
+		"query":        "{}",
 	}
-	require.Equal(t, expected, handlerInvocation.ToDispatchInvoke().Args)
+	args := handlerInvocation.ToDispatchInvoke().Args
+	for key, value := range expected {
+		require.Equal(t, value, args[key], key)
+	}
+	require.Contains(t, args["headers"], `"Content-Type":["application/json"]`)
 }
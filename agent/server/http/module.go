@@ -2,28 +2,68 @@ package http
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cortexapps/axon/config"
 	"github.com/cortexapps/axon/server/cron"
 	"github.com/cortexapps/axon/server/handler"
+	"github.com/cortexapps/axon/server/leader"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
 var Module = fx.Module("handler",
-	fx.Provide(handler.NewHandlerManager),
+	fx.Provide(newHandlerManager),
 	fx.Provide(cron.New),
+	fx.Provide(NewDeadLetterStore),
+	fx.Provide(newWebhookDedupStore),
 	fx.Invoke(createWebhookHttpServer),
 )
 
-func createWebhookHttpServer(lifecycle fx.Lifecycle, config config.AgentConfig, logger *zap.Logger, handlerManager handler.Manager, registry *prometheus.Registry) Server {
+func newHandlerManager(config config.AgentConfig, logger *zap.Logger, cron cron.Cron, registry *prometheus.Registry, history handler.HistoryManager, elector leader.Elector) (handler.Manager, error) {
+	switch config.DispatchQueueOverflow {
+	case "", handler.QueueOverflowReject, handler.QueueOverflowDropOldest:
+	default:
+		return nil, fmt.Errorf("unknown dispatch queue overflow policy %q", config.DispatchQueueOverflow)
+	}
+
+	opts := []handler.ManagerOption{
+		handler.WithHistoryManager(history),
+		handler.WithLeaderElector(elector),
+		handler.WithDispatchQueue(config.DispatchQueueCapacity, config.DispatchQueueOverflow),
+	}
+	if config.HandlerQueueDurable {
+		queue, err := handler.OpenDurableQueue(config.HandlerQueuePath, config.HandlerQueueMaxBacklog, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Durable handler queue enabled", zap.String("path", config.HandlerQueuePath))
+		opts = append(opts, handler.WithDurableQueue(queue))
+	}
+	return handler.NewHandlerManager(logger, cron, registry, opts...), nil
+}
+
+func newWebhookDedupStore(lifecycle fx.Lifecycle, config config.AgentConfig, logger *zap.Logger) (*WebhookDedupStore, error) {
+	store, err := OpenWebhookDedupStore(config.WebhookDedupPath, logger)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return store.Close()
+		},
+	})
+	return store, nil
+}
+
+func createWebhookHttpServer(lifecycle fx.Lifecycle, config config.AgentConfig, logger *zap.Logger, handlerManager handler.Manager, registry *prometheus.Registry, deadLetters DeadLetterStore, dedup *WebhookDedupStore) Server {
 
 	params := HttpServerParams{
 		Logger:   logger,
 		Registry: registry,
 		Handlers: []RegisterableHandler{
-			NewWebhookHandler(config, logger, handlerManager, registry),
+			NewWebhookHandler(config, logger, handlerManager, registry, WithDeadLetterStore(deadLetters), WithDedupStore(dedup)),
 		},
 		Config: config,
 	}
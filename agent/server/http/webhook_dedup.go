@@ -0,0 +1,225 @@
+package http
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookDedupFileName    = "keys.jsonl"
+	webhookDedupCompactSize = 1000
+
+	defaultWebhookDedupWindow = time.Hour
+)
+
+// dedupRecord is a line in the dedup log. A record with a zero Expires
+// removes its key.
+type dedupRecord struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires"`
+}
+
+// WebhookDedupStore remembers the idempotency keys of recent webhook
+// deliveries, persisted to an append-only log so duplicates are still
+// caught after the agent restarts.
+type WebhookDedupStore struct {
+	path   string
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	keys    map[string]time.Time
+	written int
+}
+
+// OpenWebhookDedupStore opens, or creates, the dedup log in dir, keeping the
+// keys that haven't expired.
+func OpenWebhookDedupStore(dir string, logger *zap.Logger) (*WebhookDedupStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dedup directory: %w", err)
+	}
+
+	s := &WebhookDedupStore{
+		path:   filepath.Join(dir, webhookDedupFileName),
+		logger: logger.Named("webhook-dedup"),
+		now:    time.Now,
+		keys:   make(map[string]time.Time),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *WebhookDedupStore) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open dedup file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &dedupRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			// a crash mid-write can leave a partial last line
+			s.logger.Warn("Skipping invalid dedup record", zap.Error(err))
+			continue
+		}
+		if record.Expires.IsZero() {
+			delete(s.keys, record.Key)
+			continue
+		}
+		s.keys[record.Key] = record.Expires
+	}
+	return scanner.Err()
+}
+
+// compact drops expired keys and rewrites the log with the rest. Callers
+// hold s.mu.
+func (s *WebhookDedupStore) compact() error {
+	now := s.now()
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create dedup file: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	for key, expires := range s.keys {
+		if !expires.After(now) {
+			delete(s.keys, key)
+			continue
+		}
+		if err := writeDedupRecord(writer, &dedupRecord{Key: key, Expires: expires}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace dedup file: %w", err)
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dedup file: %w", err)
+	}
+	s.written = 0
+	return nil
+}
+
+func writeDedupRecord(writer interface{ Write([]byte) (int, error) }, record *dedupRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(line, '\n'))
+	return err
+}
+
+// append writes a record, compacting the log once enough records have been
+// written since the last compaction. Callers hold s.mu.
+func (s *WebhookDedupStore) append(record *dedupRecord) error {
+	if s.file == nil {
+		return fmt.Errorf("dedup store is closed")
+	}
+	if err := writeDedupRecord(s.file, record); err != nil {
+		return err
+	}
+	s.written++
+	if s.written >= webhookDedupCompactSize {
+		return s.compact()
+	}
+	return nil
+}
+
+// Seen records key for window and returns true if it was already recorded
+// and hasn't expired.
+func (s *WebhookDedupStore) Seen(key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expires, ok := s.keys[key]; ok && expires.After(now) {
+		return true, nil
+	}
+	expires := now.Add(window)
+	s.keys[key] = expires
+	return false, s.append(&dedupRecord{Key: key, Expires: expires})
+}
+
+// Forget removes key, so a delivery that couldn't be queued is accepted
+// when it is retried.
+func (s *WebhookDedupStore) Forget(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key]; !ok {
+		return nil
+	}
+	delete(s.keys, key)
+	return s.append(&dedupRecord{Key: key})
+}
+
+func (s *WebhookDedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// webhookDedupKey returns the idempotency key of a request for dedup, or ""
+// if the request has none.
+func webhookDedupKey(dedup *pb.WebhookDedup, header http.Header, body []byte) string {
+	if dedup.GetHeader() != "" {
+		if value := header.Get(dedup.Header); value != "" {
+			return "header:" + value
+		}
+	}
+	if dedup.GetHashBody() {
+		sum := sha256.Sum256(body)
+		return "body:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
+
+func webhookDedupWindow(dedup *pb.WebhookDedup) time.Duration {
+	if dedup.GetWindowSeconds() > 0 {
+		return time.Duration(dedup.WindowSeconds) * time.Second
+	}
+	return defaultWebhookDedupWindow
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/handler"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWebhookDedupStore(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	dir := t.TempDir()
+	now := time.Now()
+
+	store, err := OpenWebhookDedupStore(dir, logger)
+	require.NoError(t, err)
+	store.now = func() time.Time { return now }
+
+	seen, err := store.Seen("a", time.Minute)
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	seen, err = store.Seen("a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	_, err = store.Seen("b", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Forget("b"))
+
+	_, err = store.Seen("c", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// keys survive a restart, forgotten and expired ones don't
+	store, err = OpenWebhookDedupStore(dir, logger)
+	require.NoError(t, err)
+	defer store.Close()
+	store.now = func() time.Time { return now.Add(2 * time.Second) }
+
+	seen, err = store.Seen("a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	seen, err = store.Seen("b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	seen, err = store.Seen("c", time.Minute)
+	require.NoError(t, err)
+	require.False(t, seen)
+}
+
+func TestWebhookDedupKey(t *testing.T) {
+	header := http.Header{"X-Github-Delivery": {"1234"}}
+
+	require.Empty(t, webhookDedupKey(nil, header, []byte("body")))
+	require.Equal(t, "header:1234", webhookDedupKey(&pb.WebhookDedup{Header: "X-GitHub-Delivery"}, header, []byte("body")))
+	require.Empty(t, webhookDedupKey(&pb.WebhookDedup{Header: "X-Request-Id"}, header, []byte("body")))
+
+	hashed := webhookDedupKey(&pb.WebhookDedup{Header: "X-Request-Id", HashBody: true}, header, []byte("body"))
+	require.True(t, strings.HasPrefix(hashed, "body:"))
+	require.Equal(t, hashed, webhookDedupKey(&pb.WebhookDedup{HashBody: true}, nil, []byte("body")))
+	require.NotEqual(t, hashed, webhookDedupKey(&pb.WebhookDedup{HashBody: true}, nil, []byte("other")))
+
+	require.Equal(t, defaultWebhookDedupWindow, webhookDedupWindow(nil))
+	require.Equal(t, time.Minute, webhookDedupWindow(&pb.WebhookDedup{WindowSeconds: 60}))
+}
+
+func TestWebhookDuplicatesAreSkipped(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+	store, err := OpenWebhookDedupStore(t.TempDir(), logger)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = manager.RegisterHandler("1", "test", time.Minute,
+		webhookOption("github"),
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Webhook{
+				Webhook: &pb.HandlerWebhookOption{
+					Dedup: &pb.WebhookDedup{Header: "X-GitHub-Delivery"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	webhookHandler := NewWebhookHandler(config.AgentConfig{}, logger, manager, nil, WithDedupStore(store))
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(delivery string) string {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/webhook/github", strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := map[string]any{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result["status"].(string)
+	}
+
+	require.Equal(t, "ok", post("delivery-1"))
+	require.Equal(t, "duplicate", post("delivery-1"))
+	require.Equal(t, "ok", post("delivery-2"))
+
+	for range 2 {
+		invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, invoke)
+	}
+	invoke, err := manager.Dequeue(context.Background(), "1", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, invoke)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+)
+
+// webhookFilterRequest evaluates WebhookFilters against a request, parsing
+// its body at most once however many handlers filter on it.
+type webhookFilterRequest struct {
+	method string
+	header http.Header
+	body   []byte
+
+	parsed bool
+	json   any
+}
+
+func newWebhookFilterRequest(r *http.Request, body []byte) *webhookFilterRequest {
+	return &webhookFilterRequest{
+		method: r.Method,
+		header: r.Header,
+		body:   body,
+	}
+}
+
+// Matches returns true if the request matches all of filters.
+func (f *webhookFilterRequest) Matches(filters []*pb.WebhookFilter) bool {
+	for _, filter := range filters {
+		if f.matches(filter) == filter.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *webhookFilterRequest) matches(filter *pb.WebhookFilter) bool {
+	value, ok := f.lookup(filter)
+	if !ok {
+		return false
+	}
+	if len(filter.Values) == 0 {
+		return true
+	}
+	if filter.Source == pb.WebhookFilterSource_WEBHOOK_FILTER_METHOD {
+		return slices.ContainsFunc(filter.Values, func(v string) bool {
+			return strings.EqualFold(v, value)
+		})
+	}
+	return slices.Contains(filter.Values, value)
+}
+
+func (f *webhookFilterRequest) lookup(filter *pb.WebhookFilter) (string, bool) {
+	switch filter.Source {
+	case pb.WebhookFilterSource_WEBHOOK_FILTER_METHOD:
+		return f.method, true
+	case pb.WebhookFilterSource_WEBHOOK_FILTER_HEADER:
+		values := f.header.Values(filter.Key)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	case pb.WebhookFilterSource_WEBHOOK_FILTER_JSON_BODY:
+		return f.lookupJson(filter.Key)
+	}
+	return "", false
+}
+
+// lookupJson returns the value at a dot-separated path into the JSON body.
+// Strings are returned as is, anything else as its JSON encoding.
+func (f *webhookFilterRequest) lookupJson(path string) (string, bool) {
+	if !f.parsed {
+		f.parsed = true
+		decoder := json.NewDecoder(bytes.NewReader(f.body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&f.json); err != nil {
+			f.json = nil
+		}
+	}
+	if f.json == nil {
+		return "", false
+	}
+
+	current := f.json
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path != "" {
+		for _, part := range strings.Split(path, ".") {
+			switch node := current.(type) {
+			case map[string]any:
+				next, ok := node[part]
+				if !ok {
+					return "", false
+				}
+				current = next
+			case []any:
+				index, err := strconv.Atoi(part)
+				if err != nil || index < 0 || index >= len(node) {
+					return "", false
+				}
+				current = node[index]
+			default:
+				return "", false
+			}
+		}
+	}
+
+	if str, ok := current.(string); ok {
+		return str, true
+	}
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookFilters(t *testing.T) {
+	body := `{"action":"opened","number":42,"draft":false,"pull_request":{"base":{"ref":"main"},"labels":[{"name":"bug"}]}}`
+	r, err := http.NewRequest(http.MethodPost, "/webhook/github", strings.NewReader(body))
+	require.NoError(t, err)
+	r.Header.Set("X-GitHub-Event", "pull_request")
+
+	header := func(key string, values ...string) *pb.WebhookFilter {
+		return &pb.WebhookFilter{Source: pb.WebhookFilterSource_WEBHOOK_FILTER_HEADER, Key: key, Values: values}
+	}
+	jsonBody := func(key string, values ...string) *pb.WebhookFilter {
+		return &pb.WebhookFilter{Source: pb.WebhookFilterSource_WEBHOOK_FILTER_JSON_BODY, Key: key, Values: values}
+	}
+	negate := func(filter *pb.WebhookFilter) *pb.WebhookFilter {
+		filter.Negate = true
+		return filter
+	}
+
+	cases := []struct {
+		name    string
+		filters []*pb.WebhookFilter
+		matches bool
+	}{
+		{"no filters", nil, true},
+		{"header equals", []*pb.WebhookFilter{header("X-Github-Event", "pull_request")}, true},
+		{"header in", []*pb.WebhookFilter{header("X-GitHub-Event", "push", "pull_request")}, true},
+		{"header not in", []*pb.WebhookFilter{header("X-GitHub-Event", "push", "issues")}, false},
+		{"header present", []*pb.WebhookFilter{header("X-GitHub-Event")}, true},
+		{"header missing", []*pb.WebhookFilter{header("X-GitHub-Delivery")}, false},
+		{"negated header", []*pb.WebhookFilter{negate(header("X-GitHub-Event", "push"))}, true},
+		{"method", []*pb.WebhookFilter{{Source: pb.WebhookFilterSource_WEBHOOK_FILTER_METHOD, Values: []string{"post"}}}, true},
+		{"json string", []*pb.WebhookFilter{jsonBody("action", "opened", "reopened")}, true},
+		{"json nested", []*pb.WebhookFilter{jsonBody("$.pull_request.base.ref", "main")}, true},
+		{"json array index", []*pb.WebhookFilter{jsonBody("pull_request.labels.0.name", "bug")}, true},
+		{"json number", []*pb.WebhookFilter{jsonBody("number", "42")}, true},
+		{"json bool", []*pb.WebhookFilter{jsonBody("draft", "true")}, false},
+		{"json missing", []*pb.WebhookFilter{jsonBody("pull_request.head.ref")}, false},
+		{"negated json missing", []*pb.WebhookFilter{negate(jsonBody("pull_request.head.ref"))}, true},
+		{"all must match", []*pb.WebhookFilter{header("X-GitHub-Event", "pull_request"), jsonBody("action", "closed")}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := newWebhookFilterRequest(r, []byte(body))
+			require.Equal(t, c.matches, request.Matches(c.filters))
+		})
+	}
+
+	request := newWebhookFilterRequest(r, []byte("not json"))
+	require.False(t, request.Matches([]*pb.WebhookFilter{jsonBody("action")}))
+}
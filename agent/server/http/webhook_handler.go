@@ -1,30 +1,58 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
 	"github.com/cortexapps/axon/config"
 	"github.com/cortexapps/axon/server/handler"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 const webhookPathRoot = "/webhook/"
 
 type webhookHandler struct {
 	io.Closer
-	config          config.AgentConfig
-	logger          *zap.Logger
-	handlerManager  handler.Manager
-	webhookReceived *prometheus.CounterVec
+	config            config.AgentConfig
+	logger            *zap.Logger
+	handlerManager    handler.Manager
+	webhookReceived   *prometheus.CounterVec
+	webhookFiltered   *prometheus.CounterVec
+	webhookDuplicates *prometheus.CounterVec
+	deadLetters       DeadLetterStore
+	dedup             *WebhookDedupStore
+	verifier          *webhookVerifier
 }
 
-func NewWebhookHandler(config config.AgentConfig, logger *zap.Logger, handlerManager handler.Manager, registry *prometheus.Registry) RegisterableHandler {
+type WebhookHandlerOption func(*webhookHandler)
+
+// WithDeadLetterStore keeps deliveries whose handler fails in store so they
+// can be replayed.
+func WithDeadLetterStore(store DeadLetterStore) WebhookHandlerOption {
+	return func(h *webhookHandler) {
+		h.deadLetters = store
+	}
+}
+
+// WithDedupStore remembers the idempotency keys of deliveries to handlers
+// with a WebhookDedup option in store, so duplicates aren't delivered again.
+func WithDedupStore(store *WebhookDedupStore) WebhookHandlerOption {
+	return func(h *webhookHandler) {
+		h.dedup = store
+	}
+}
+
+func NewWebhookHandler(config config.AgentConfig, logger *zap.Logger, handlerManager handler.Manager, registry *prometheus.Registry, opts ...WebhookHandlerOption) RegisterableHandler {
 
 	handler := &webhookHandler{
 		config:         config,
@@ -37,9 +65,29 @@ func NewWebhookHandler(config config.AgentConfig, logger *zap.Logger, handlerMan
 			},
 			[]string{"webhookId", "status"},
 		),
+		webhookFiltered: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "axon_webhook_filtered",
+				Help: "Number of webhooks not delivered to a handler because of its filters",
+			},
+			[]string{"webhookId", "handler"},
+		),
+		webhookDuplicates: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "axon_webhook_duplicates",
+				Help: "Number of repeated webhook deliveries not delivered to a handler again",
+			},
+			[]string{"webhookId", "handler"},
+		),
+		verifier: newWebhookVerifier(config, logger),
+	}
+	for _, opt := range opts {
+		opt(handler)
 	}
 	if registry != nil {
 		registry.MustRegister(handler.webhookReceived)
+		registry.MustRegister(handler.webhookFiltered)
+		registry.MustRegister(handler.webhookDuplicates)
 	}
 	return handler
 }
@@ -82,8 +130,8 @@ func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	webhookId = pathParts[0]
 
-	entry := h.handlerManager.GetByTag(webhookId)
-	if entry == nil {
+	entries := h.handlerManager.ListByTag(webhookId)
+	if len(entries) == 0 {
 		h.logger.Error("Webhook not found", zap.String("webhookId", webhookId))
 		writeStatus(http.StatusNotFound)
 		return
@@ -98,30 +146,274 @@ func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.handlerManager.Trigger(handler.NewWebhookHandlerInvoke(entry, r.URL, string(bodyBytes), contentType))
+	// every handler subscribed to the webhook verifies and filters the
+	// request by its own options
+	type subscriber struct {
+		entry    handler.HandlerEntry
+		options  *pb.HandlerWebhookOption
+		dedupKey string
+	}
+	filterRequest := newWebhookFilterRequest(r, bodyBytes)
+	verified := false
+	matched := []subscriber{}
+	for _, entry := range entries {
+		options := handler.WebhookOptions(entry.Options())
+		if err := h.verifier.Verify(options.Verification, r.Header, bodyBytes); err != nil {
+			if errors.Is(err, errWebhookUnverified) {
+				h.logger.Warn("Rejecting unverified webhook",
+					zap.String("webhookId", webhookId),
+					zap.String("handler", entry.Name()),
+					zap.Error(err),
+				)
+				continue
+			}
+			h.logger.Error("Failed to verify webhook", zap.String("webhookId", webhookId), zap.Error(err))
+			writeStatus(http.StatusInternalServerError)
+			return
+		}
+		verified = true
 
-	if err != nil {
-		h.logger.Error("Failed to trigger webhook", zap.Error(err))
+		if !filterRequest.Matches(options.Filters) {
+			h.webhookFiltered.WithLabelValues(webhookId, entry.Name()).Inc()
+			continue
+		}
+		matched = append(matched, subscriber{entry: entry, options: options})
+	}
+
+	// drop handlers that have already had this delivery
+	duplicates := 0
+	unseen := matched[:0]
+	for _, sub := range matched {
+		key := webhookDedupKey(sub.options.Dedup, r.Header, bodyBytes)
+		if key != "" && h.dedup != nil {
+			sub.dedupKey = webhookId + "/" + sub.entry.Name() + "/" + key
+			seen, err := h.dedup.Seen(sub.dedupKey, webhookDedupWindow(sub.options.Dedup))
+			if err != nil {
+				h.logger.Error("Failed to record webhook idempotency key", zap.String("webhookId", webhookId), zap.Error(err))
+			}
+			if seen {
+				h.logger.Info("Skipping duplicate webhook",
+					zap.String("webhookId", webhookId),
+					zap.String("handler", sub.entry.Name()),
+				)
+				h.webhookDuplicates.WithLabelValues(webhookId, sub.entry.Name()).Inc()
+				duplicates++
+				continue
+			}
+		}
+		unseen = append(unseen, sub)
+	}
+	matched = unseen
+
+	if !verified {
+		writeStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if len(matched) == 0 && duplicates > 0 {
+		h.writeResponse(w, map[string]any{
+			"status":    "duplicate",
+			"webhookId": webhookId,
+		})
+		return
+	}
+
+	if len(matched) == 0 {
+		h.logger.Info("Webhook filtered out", zap.String("webhookId", webhookId))
+		h.writeResponse(w, map[string]any{
+			"status":    "filtered",
+			"webhookId": webhookId,
+		})
+		return
+	}
+
+	var synchronous *subscriber
+	var synchronousInvoke handler.Invocable
+	var deliverErr error
+	delivered := 0
+	for _, sub := range matched {
+		invoke, err := deliverWebhook(h.handlerManager, h.deadLetters, h.logger, h.config.WebhookRedactHeaders, &DeadLetter{
+			WebhookId:   webhookId,
+			HandlerName: sub.entry.Name(),
+			URL:         r.URL.String(),
+			Method:      r.Method,
+			RemoteAddr:  r.RemoteAddr,
+			ContentType: contentType,
+			Headers:     r.Header.Clone(),
+			Body:        string(bodyBytes),
+		})
+		if err != nil {
+			h.logger.Error("Failed to trigger webhook",
+				zap.String("webhookId", webhookId),
+				zap.String("handler", sub.entry.Name()),
+				zap.Error(err),
+			)
+			deliverErr = err
+			if sub.dedupKey != "" {
+				if err := h.dedup.Forget(sub.dedupKey); err != nil {
+					h.logger.Error("Failed to forget webhook idempotency key", zap.String("webhookId", webhookId), zap.Error(err))
+				}
+			}
+			continue
+		}
+		delivered++
+		if sub.options.Synchronous && synchronous == nil {
+			synchronous = &sub
+			synchronousInvoke = invoke
+		}
+	}
+
+	if delivered == 0 {
+		if errors.Is(deliverErr, handler.ErrDispatchQueueFull) {
+			h.logger.Warn("Webhook dispatch queue is full", zap.String("webhookId", webhookId))
+			writeStatus(http.StatusTooManyRequests)
+			return
+		}
+		if errors.Is(deliverErr, handler.ErrQueueFull) {
+			h.logger.Warn("Webhook queue is full", zap.String("webhookId", webhookId))
+			writeStatus(http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(deliverErr, handler.ErrDraining) {
+			h.logger.Warn("Rejecting webhook while draining", zap.String("webhookId", webhookId))
+			writeStatus(http.StatusServiceUnavailable)
+			return
+		}
 		writeStatus(http.StatusInternalServerError)
 		return
 	}
 
-	response := map[string]any{
+	if synchronous != nil {
+		timeout := time.Duration(synchronous.options.ResponseTimeoutMs) * time.Millisecond
+		if timeout <= 0 {
+			timeout = synchronous.entry.Timeout()
+		}
+		if timeout <= 0 {
+			timeout = defaultWebhookResponseTimeout
+		}
+		response := awaitWebhookResponse(r.Context(), synchronousInvoke, timeout)
+		for name, value := range response.Headers {
+			w.Header().Set(name, value)
+		}
+		writeStatus(int(response.StatusCode))
+		if _, err := w.Write([]byte(response.Body)); err != nil {
+			h.logger.Error("Failed to write response", zap.Error(err))
+			return
+		}
+		h.logger.Info("Webhook processed synchronously",
+			zap.String("webhookId", webhookId),
+			zap.Int32("status", response.StatusCode),
+		)
+		return
+	}
+
+	h.writeResponse(w, map[string]any{
 		"status":    "ok",
 		"webhookId": webhookId,
-	}
+	})
+	h.logger.Info("Webhook processed successfully", zap.String("webhookId", webhookId), zap.Int("handlers", delivered))
+}
+
+func (h *webhookHandler) writeResponse(w http.ResponseWriter, response map[string]any) {
 	jsonResponse, err := json.Marshal(response)
 	if err != nil {
 		h.logger.Error("Failed to marshal response", zap.Error(err))
-		writeStatus(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(jsonResponse)
-	if err != nil {
+	if _, err := w.Write(jsonResponse); err != nil {
 		h.logger.Error("Failed to write response", zap.Error(err))
-		writeStatus(http.StatusInternalServerError)
-		return
 	}
-	h.logger.Info("Webhook processed successfully", zap.String("webhookId", webhookId))
+}
+
+// webhookSubPath returns the part of a webhook request path after the
+// webhook id, e.g. "a/b" for /webhook/{id}/a/b.
+func webhookSubPath(path string, webhookId string) string {
+	path = strings.TrimPrefix(path, webhookPathRoot+webhookId)
+	return strings.TrimPrefix(path, "/")
+}
+
+const redactedHeaderValue = "[REDACTED]"
+
+// redactHeaders returns a copy of headers with the values of the named
+// headers replaced.
+func redactHeaders(headers http.Header, names ...[]string) http.Header {
+	redacted := headers.Clone()
+	if redacted == nil {
+		redacted = http.Header{}
+	}
+	for _, list := range names {
+		for _, name := range list {
+			name = http.CanonicalHeaderKey(name)
+			if _, ok := redacted[name]; ok {
+				redacted[name] = []string{redactedHeaderValue}
+			}
+		}
+	}
+	return redacted
+}
+
+// defaultWebhookResponseTimeout bounds a synchronous webhook whose handler
+// has no timeout.
+const defaultWebhookResponseTimeout = 30 * time.Second
+
+// awaitWebhookResponse waits up to timeout for a synchronous webhook's
+// invocation and returns the response for its caller. The invocation keeps
+// running if the wait times out.
+func awaitWebhookResponse(ctx context.Context, invoke handler.Invocable, timeout time.Duration) *pb.WebhookResponse {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-invoke.Done():
+	case <-timer.C:
+		return webhookErrorResponse(http.StatusGatewayTimeout, "handler did not respond in time")
+	case <-ctx.Done():
+		return webhookErrorResponse(http.StatusGatewayTimeout, "request cancelled")
+	}
+
+	result, err := invoke.GetResult()
+	switch {
+	case err == nil:
+		return parseWebhookResponse(result)
+	case handler.ErrorCode(err) == handler.ErrorCodeTimeout:
+		return webhookErrorResponse(http.StatusGatewayTimeout, err.Error())
+	case handler.ErrorCode(err) == handler.ErrorCodeSkipped:
+		return webhookErrorResponse(http.StatusTooManyRequests, err.Error())
+	case handler.ErrorCode(err) == handler.ErrorCodeQueued:
+		return &pb.WebhookResponse{
+			StatusCode: http.StatusAccepted,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"status":"queued"}`,
+		}
+	}
+	return webhookErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Handler failed: %s", err.Error()))
+}
+
+// parseWebhookResponse reads a handler result as a WebhookResponse when it is
+// one with a status code. Any other result, such as {}, is returned as the
+// body of a 200.
+func parseWebhookResponse(result string) *pb.WebhookResponse {
+	response := &pb.WebhookResponse{}
+	if result != "" && protojson.Unmarshal([]byte(result), response) == nil && response.StatusCode != 0 {
+		return response
+	}
+	response = &pb.WebhookResponse{
+		StatusCode: http.StatusOK,
+		Body:       result,
+	}
+	if json.Valid([]byte(result)) {
+		response.Headers = map[string]string{"Content-Type": "application/json"}
+	}
+	return response
+}
+
+func webhookErrorResponse(status int, message string) *pb.WebhookResponse {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	return &pb.WebhookResponse{
+		StatusCode: int32(status),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
 }
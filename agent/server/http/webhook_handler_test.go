@@ -0,0 +1,260 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/handler"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func synchronousWebhookOption(responseTimeout time.Duration) *pb.HandlerOption {
+	return &pb.HandlerOption{
+		Option: &pb.HandlerOption_Webhook{
+			Webhook: &pb.HandlerWebhookOption{
+				Synchronous:       true,
+				ResponseTimeoutMs: int32(responseTimeout.Milliseconds()),
+			},
+		},
+	}
+}
+
+func TestSynchronousWebhook(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := manager.RegisterHandler("1", "test", time.Minute,
+		webhookOption("my-webhook-id"),
+		synchronousWebhookOption(200*time.Millisecond),
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	webhookHandler := NewWebhookHandler(config.AgentConfig{}, logger, manager, nil)
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func() <-chan *http.Response {
+		responses := make(chan *http.Response, 1)
+		go func() {
+			resp, err := http.Post(ts.URL+"/webhook/my-webhook-id", "application/json", strings.NewReader(`{"challenge":"abc"}`))
+			require.NoError(t, err)
+			responses <- resp
+		}()
+		return responses
+	}
+
+	cases := []struct {
+		name        string
+		result      string
+		err         error
+		status      int
+		header      string
+		body        string
+		contentType string
+	}{
+		{
+			name:   "webhook response",
+			result: `{"statusCode":201,"headers":{"X-Challenge":"abc"},"body":"abc"}`,
+			status: http.StatusCreated,
+			header: "abc",
+			body:   "abc",
+		},
+		{
+			name:        "plain result",
+			result:      `{"challenge":"abc"}`,
+			status:      http.StatusOK,
+			body:        `{"challenge":"abc"}`,
+			contentType: "application/json",
+		},
+		{
+			name:        "empty object",
+			result:      `{}`,
+			status:      http.StatusOK,
+			body:        `{}`,
+			contentType: "application/json",
+		},
+		{
+			name:        "no status code",
+			result:      `{"body":"abc"}`,
+			status:      http.StatusOK,
+			body:        `{"body":"abc"}`,
+			contentType: "application/json",
+		},
+		{
+			name:   "handler error",
+			err:    &handler.InvocationError{Code: handler.ErrorCodeUnexpected, Message: "boom"},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "timeout",
+			status: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			responses := post()
+			invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+			require.NoError(t, err)
+			require.NotNil(t, invoke)
+			if c.result != "" || c.err != nil {
+				invoke.Complete(c.result, c.err)
+			}
+
+			resp := <-responses
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, c.status, resp.StatusCode)
+			if c.header != "" {
+				require.Equal(t, c.header, resp.Header.Get("X-Challenge"))
+			}
+			if c.body != "" {
+				require.Equal(t, c.body, string(body))
+			}
+			if c.contentType != "" {
+				require.Equal(t, c.contentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestWebhookRequestArgs(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := manager.RegisterHandler("1", "test", time.Minute,
+		webhookOption("my-webhook-id"),
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Webhook{
+				Webhook: &pb.HandlerWebhookOption{
+					RedactHeaders: []string{"x-api-key"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	cfg := config.AgentConfig{WebhookRedactHeaders: config.DefaultWebhookRedactHeaders}
+	webhookHandler := NewWebhookHandler(cfg, logger, manager, nil)
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/webhook/my-webhook-id/repos/axon?ref=main&tag=a&tag=b", strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Api-Key", "key")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	invoke, err := manager.Dequeue(context.Background(), "1", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, invoke)
+	args := invoke.ToDispatchInvoke().Args
+
+	require.Equal(t, "payload", args["body"])
+	require.Equal(t, "text/plain", args["content-type"])
+	require.Equal(t, http.MethodPut, args["method"])
+	require.Equal(t, "repos/axon", args["path"])
+	require.NotEmpty(t, args["remote-addr"])
+
+	headers := http.Header{}
+	require.NoError(t, json.Unmarshal([]byte(args["headers"]), &headers))
+	require.Equal(t, "push", headers.Get("X-GitHub-Event"))
+	require.Equal(t, redactedHeaderValue, headers.Get("Authorization"))
+	require.Equal(t, redactedHeaderValue, headers.Get("X-Api-Key"))
+
+	query := map[string][]string{}
+	require.NoError(t, json.Unmarshal([]byte(args["query"]), &query))
+	require.Equal(t, []string{"main"}, query["ref"])
+	require.Equal(t, []string{"a", "b"}, query["tag"])
+}
+
+func TestWebhookSubPath(t *testing.T) {
+	require.Equal(t, "", webhookSubPath("/webhook/my-webhook-id", "my-webhook-id"))
+	require.Equal(t, "", webhookSubPath("/webhook/my-webhook-id/", "my-webhook-id"))
+	require.Equal(t, "a/b", webhookSubPath("/webhook/my-webhook-id/a/b", "my-webhook-id"))
+}
+
+func TestWebhookFanOut(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	eventFilter := func(events ...string) *pb.HandlerOption {
+		return &pb.HandlerOption{
+			Option: &pb.HandlerOption_Webhook{
+				Webhook: &pb.HandlerWebhookOption{
+					Filters: []*pb.WebhookFilter{
+						{Source: pb.WebhookFilterSource_WEBHOOK_FILTER_HEADER, Key: "X-GitHub-Event", Values: events},
+					},
+				},
+			},
+		}
+	}
+	_, err := manager.RegisterHandler("1", "pushes", time.Minute, webhookOption("github"), eventFilter("push"))
+	require.NoError(t, err)
+	_, err = manager.RegisterHandler("1", "all-changes", time.Minute, webhookOption("github"), eventFilter("push", "pull_request"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	webhookHandler := NewWebhookHandler(config.AgentConfig{}, logger, manager, nil)
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(event string) map[string]any {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/webhook/github", strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("X-GitHub-Event", event)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := map[string]any{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result
+	}
+
+	dequeued := func() []string {
+		names := []string{}
+		for {
+			invoke, err := manager.Dequeue(context.Background(), "1", 50*time.Millisecond)
+			require.NoError(t, err)
+			if invoke == nil {
+				return names
+			}
+			names = append(names, invoke.GetEntry().Name())
+		}
+	}
+
+	require.Equal(t, "ok", post("push")["status"])
+	require.ElementsMatch(t, []string{"pushes", "all-changes"}, dequeued())
+
+	require.Equal(t, "ok", post("pull_request")["status"])
+	require.Equal(t, []string{"all-changes"}, dequeued())
+
+	require.Equal(t, "filtered", post("issues")["status"])
+	require.Empty(t, dequeued())
+}
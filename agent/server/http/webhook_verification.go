@@ -0,0 +1,171 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/snykbroker/acceptfile"
+	"go.uber.org/zap"
+)
+
+// errWebhookUnverified is returned when a request does not carry a valid
+// signature, as opposed to the verification itself failing.
+var errWebhookUnverified = errors.New("webhook signature verification failed")
+
+const (
+	defaultSlackTimestampTolerance = 300 * time.Second
+
+	// pluginSecretTTL is how long a secret read from a plugin is reused, so
+	// that the plugin isn't run for every request but rotated secrets are
+	// still picked up.
+	pluginSecretTTL = 5 * time.Minute
+)
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// webhookVerifier checks webhook requests against the WebhookVerification
+// of their handler.
+type webhookVerifier struct {
+	config        config.AgentConfig
+	logger        *zap.Logger
+	now           func() time.Time
+	lock          sync.Mutex
+	pluginSecrets map[string]cachedSecret
+}
+
+func newWebhookVerifier(config config.AgentConfig, logger *zap.Logger) *webhookVerifier {
+	return &webhookVerifier{
+		config:        config,
+		logger:        logger,
+		now:           time.Now,
+		pluginSecrets: map[string]cachedSecret{},
+	}
+}
+
+// Verify returns nil if spec is empty or the request is signed with its
+// secret, and an error wrapping errWebhookUnverified if it isn't.
+func (v *webhookVerifier) Verify(spec *pb.WebhookVerification, header http.Header, body []byte) error {
+	if spec.GetType() == pb.WebhookVerificationType_WEBHOOK_VERIFICATION_NONE {
+		return nil
+	}
+
+	secret, err := v.secret(spec)
+	if err != nil {
+		return err
+	}
+
+	headerName := func(defaultName string) string {
+		if spec.Header != "" {
+			return spec.Header
+		}
+		return defaultName
+	}
+
+	switch spec.Type {
+	case pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB:
+		return verifyHexHMAC(secret, body, header.Get(headerName("X-Hub-Signature-256")), "sha256=")
+	case pb.WebhookVerificationType_WEBHOOK_VERIFICATION_BITBUCKET:
+		return verifyHexHMAC(secret, body, header.Get(headerName("X-Hub-Signature")), "sha256=")
+	case pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITLAB:
+		token := header.Get(headerName("X-Gitlab-Token"))
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return fmt.Errorf("%w: token does not match", errWebhookUnverified)
+		}
+		return nil
+	case pb.WebhookVerificationType_WEBHOOK_VERIFICATION_SLACK:
+		timestamp := header.Get("X-Slack-Request-Timestamp")
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timestamp %q", errWebhookUnverified, timestamp)
+		}
+		tolerance := defaultSlackTimestampTolerance
+		if spec.TimestampToleranceSeconds > 0 {
+			tolerance = time.Duration(spec.TimestampToleranceSeconds) * time.Second
+		}
+		age := v.now().Sub(time.Unix(seconds, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp is %v old", errWebhookUnverified, age.Round(time.Second))
+		}
+		signed := append([]byte("v0:"+timestamp+":"), body...)
+		return verifyHexHMAC(secret, signed, header.Get(headerName("X-Slack-Signature")), "v0=")
+	case pb.WebhookVerificationType_WEBHOOK_VERIFICATION_HMAC_SHA256:
+		return verifyHexHMAC(secret, body, header.Get(headerName("X-Signature")), spec.Prefix)
+	}
+	return fmt.Errorf("unsupported webhook verification type %v", spec.Type)
+}
+
+func verifyHexHMAC(secret string, body []byte, signature string, prefix string) error {
+	if signature == "" {
+		return fmt.Errorf("%w: missing signature", errWebhookUnverified)
+	}
+	if !strings.HasPrefix(signature, prefix) {
+		return fmt.Errorf("%w: signature must start with %q", errWebhookUnverified, prefix)
+	}
+	actual, err := hex.DecodeString(signature[len(prefix):])
+	if err != nil {
+		return fmt.Errorf("%w: signature is not hex encoded", errWebhookUnverified)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(actual, mac.Sum(nil)) {
+		return fmt.Errorf("%w: signature does not match", errWebhookUnverified)
+	}
+	return nil
+}
+
+// secret resolves the shared secret of spec from its environment variable
+// or plugin.
+func (v *webhookVerifier) secret(spec *pb.WebhookVerification) (string, error) {
+	if spec.SecretEnv != "" {
+		secret := os.Getenv(spec.SecretEnv)
+		if secret == "" {
+			return "", fmt.Errorf("webhook secret environment variable %s is not set", spec.SecretEnv)
+		}
+		return secret, nil
+	}
+
+	if spec.SecretPlugin == "" {
+		return "", errors.New("webhook verification has no secret_env or secret_plugin")
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if cached, ok := v.pluginSecrets[spec.SecretPlugin]; ok && v.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	plugin, err := acceptfile.FindPlugin(spec.SecretPlugin, v.config.PluginDirs, v.logger)
+	if err != nil {
+		return "", err
+	}
+	output, err := plugin.Execute()
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSpace(output)
+	if secret == "" {
+		return "", fmt.Errorf("webhook secret plugin %s returned no output", spec.SecretPlugin)
+	}
+	v.pluginSecrets[spec.SecretPlugin] = cachedSecret{
+		value:   secret,
+		expires: v.now().Add(pluginSecretTTL),
+	}
+	return secret, nil
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
+	"github.com/cortexapps/axon/config"
+	"github.com/cortexapps/axon/server/cron"
+	"github.com/cortexapps/axon/server/handler"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func sign(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookVerifier(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	verifier := newWebhookVerifier(config.AgentConfig{}, logger)
+	now := time.Unix(1700000000, 0)
+	verifier.now = func() time.Time { return now }
+
+	body := `{"action":"opened"}`
+	slackTimestamp := strconv.FormatInt(now.Unix(), 10)
+
+	cases := []struct {
+		name     string
+		spec     *pb.WebhookVerification
+		header   http.Header
+		verified bool
+	}{
+		{
+			name:     "none",
+			spec:     nil,
+			verified: true,
+		},
+		{
+			name:     "github",
+			spec:     &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB, SecretEnv: "WEBHOOK_SECRET"},
+			header:   http.Header{"X-Hub-Signature-256": {"sha256=" + sign("s3cret", body)}},
+			verified: true,
+		},
+		{
+			name:   "github wrong secret",
+			spec:   &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB, SecretEnv: "WEBHOOK_SECRET"},
+			header: http.Header{"X-Hub-Signature-256": {"sha256=" + sign("other", body)}},
+		},
+		{
+			name: "github missing signature",
+			spec: &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB, SecretEnv: "WEBHOOK_SECRET"},
+		},
+		{
+			name:     "bitbucket",
+			spec:     &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_BITBUCKET, SecretEnv: "WEBHOOK_SECRET"},
+			header:   http.Header{"X-Hub-Signature": {"sha256=" + sign("s3cret", body)}},
+			verified: true,
+		},
+		{
+			name:     "gitlab",
+			spec:     &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITLAB, SecretEnv: "WEBHOOK_SECRET"},
+			header:   http.Header{"X-Gitlab-Token": {"s3cret"}},
+			verified: true,
+		},
+		{
+			name:   "gitlab wrong token",
+			spec:   &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITLAB, SecretEnv: "WEBHOOK_SECRET"},
+			header: http.Header{"X-Gitlab-Token": {"s3cre"}},
+		},
+		{
+			name: "slack",
+			spec: &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_SLACK, SecretEnv: "WEBHOOK_SECRET"},
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {slackTimestamp},
+				"X-Slack-Signature":         {"v0=" + sign("s3cret", "v0:"+slackTimestamp+":"+body)},
+			},
+			verified: true,
+		},
+		{
+			name: "slack stale timestamp",
+			spec: &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_SLACK, SecretEnv: "WEBHOOK_SECRET", TimestampToleranceSeconds: 60},
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {strconv.FormatInt(now.Unix()-61, 10)},
+				"X-Slack-Signature":         {"v0=" + sign("s3cret", "v0:"+strconv.FormatInt(now.Unix()-61, 10)+":"+body)},
+			},
+		},
+		{
+			name:     "hmac",
+			spec:     &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_HMAC_SHA256, SecretEnv: "WEBHOOK_SECRET", Header: "X-My-Signature", Prefix: "hmac "},
+			header:   http.Header{"X-My-Signature": {"hmac " + sign("s3cret", body)}},
+			verified: true,
+		},
+		{
+			name:   "hmac missing prefix",
+			spec:   &pb.WebhookVerification{Type: pb.WebhookVerificationType_WEBHOOK_VERIFICATION_HMAC_SHA256, SecretEnv: "WEBHOOK_SECRET", Header: "X-My-Signature", Prefix: "hmac "},
+			header: http.Header{"X-My-Signature": {sign("s3cret", body)}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			header := c.header
+			if header == nil {
+				header = http.Header{}
+			}
+			err := verifier.Verify(c.spec, header, []byte(body))
+			if c.verified {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, errWebhookUnverified)
+			}
+		})
+	}
+}
+
+func TestWebhookVerifierSecretPlugin(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	pluginDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "webhook-secret"), []byte("#!/bin/sh\necho s3cret\n"), 0755))
+
+	verifier := newWebhookVerifier(config.AgentConfig{PluginDirs: []string{pluginDir}}, logger)
+	spec := &pb.WebhookVerification{
+		Type:         pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITLAB,
+		SecretPlugin: "webhook-secret",
+	}
+	require.NoError(t, verifier.Verify(spec, http.Header{"X-Gitlab-Token": {"s3cret"}}, nil))
+
+	spec.SecretPlugin = "missing"
+	err := verifier.Verify(spec, http.Header{"X-Gitlab-Token": {"s3cret"}}, nil)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errWebhookUnverified)
+}
+
+func TestWebhookRejectsUnverified(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	_, err := manager.RegisterHandler("1", "test", time.Minute,
+		webhookOption("my-webhook-id"),
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Webhook{
+				Webhook: &pb.HandlerWebhookOption{
+					Verification: &pb.WebhookVerification{
+						Type:      pb.WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB,
+						SecretEnv: "WEBHOOK_SECRET",
+					},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start("1"))
+
+	registry := prometheus.NewRegistry()
+	webhookHandler := NewWebhookHandler(config.AgentConfig{}, logger, manager, registry)
+	mux := mux.NewRouter()
+	webhookHandler.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(signature string) int {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/webhook/my-webhook-id", strings.NewReader("payload"))
+		require.NoError(t, err)
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusUnauthorized, post(""))
+	require.Equal(t, http.StatusUnauthorized, post("sha256="+sign("wrong", "payload")))
+	require.Equal(t, http.StatusOK, post("sha256="+sign("s3cret", "payload")))
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	rejected := float64(0)
+	for _, family := range metrics {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if family.GetName() == "axon_webhook_received" && label.GetName() == "status" && label.GetValue() == "401" {
+					rejected += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	require.Equal(t, float64(2), rejected)
+}
@@ -0,0 +1,116 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cortexapps/axon/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Elector decides which of several agent replicas runs scheduled handlers,
+// so that they aren't run once per replica.
+type Elector interface {
+	Start() error
+	Close() error
+	IsLeader() bool
+}
+
+const (
+	ElectionNone = ""
+	ElectionFile = "file"
+	ElectionHttp = "http"
+)
+
+// minTTL is the shortest LEADER_ELECTION_TTL, since the http lease is asked
+// for in whole seconds.
+const minTTL = time.Second
+
+type alwaysLeader struct{}
+
+// NewAlwaysLeader returns an Elector for an agent running without replicas,
+// which is always the leader.
+func NewAlwaysLeader() Elector {
+	return alwaysLeader{}
+}
+
+func (alwaysLeader) Start() error   { return nil }
+func (alwaysLeader) Close() error   { return nil }
+func (alwaysLeader) IsLeader() bool { return true }
+
+// NewElector returns the Elector selected by the LEADER_ELECTION config,
+// started and stopped with the app, and reports its leadership as the
+// axon_leader gauge.
+func NewElector(lifecycle fx.Lifecycle, config config.AgentConfig, logger *zap.Logger, registry *prometheus.Registry) (Elector, error) {
+	logger = logger.Named("leader")
+
+	if config.LeaderElection != ElectionNone && config.LeaderElection != "none" && config.LeaderElectionTTL < minTTL {
+		return nil, fmt.Errorf("LEADER_ELECTION_TTL must be at least %v, got %v", minTTL, config.LeaderElectionTTL)
+	}
+
+	var elector Elector
+	switch config.LeaderElection {
+	case ElectionNone, "none":
+		elector = NewAlwaysLeader()
+	case ElectionFile:
+		elector = NewFileLockElector(config.LeaderElectionLockPath, config.InstanceId, config.LeaderElectionTTL, logger)
+	case ElectionHttp:
+		if config.LeaderElectionUrl == "" {
+			return nil, fmt.Errorf("LEADER_ELECTION_URL is required for http leader election")
+		}
+		elector = NewHttpLeaseElector(config.LeaderElectionUrl, config.InstanceId, config.LeaderElectionTTL, logger)
+	default:
+		return nil, fmt.Errorf("unknown leader election type %q", config.LeaderElection)
+	}
+
+	if registry != nil {
+		registry.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name: "axon_leader",
+				Help: "1 if this agent is the leader that runs scheduled handlers, 0 if not",
+			},
+			func() float64 {
+				if elector.IsLeader() {
+					return 1
+				}
+				return 0
+			},
+		))
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return elector.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			return elector.Close()
+		},
+	})
+	return elector, nil
+}
+
+// WatchElected calls onElected each time elector becomes the leader after not
+// being it, until done closes. Leadership is polled every interval, since a
+// lease can lapse without an event.
+func WatchElected(elector Elector, interval time.Duration, done <-chan struct{}, onElected func()) {
+	leading := elector.IsLeader()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				elected := elector.IsLeader()
+				if elected && !leading {
+					onElected()
+				}
+				leading = elected
+			case <-done:
+				return
+			}
+		}
+	}()
+}
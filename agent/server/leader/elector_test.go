@@ -0,0 +1,68 @@
+package leader
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cortexapps/axon/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func TestNewElector_TTL(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.AgentConfig{
+		LeaderElection:         ElectionFile,
+		LeaderElectionLockPath: t.TempDir() + "/leader.lock",
+	}
+
+	// a TTL under a second would panic in the renew ticker or ask for a
+	// zero second lease
+	for _, ttl := range []time.Duration{0, -time.Second, 500 * time.Millisecond} {
+		cfg.LeaderElectionTTL = ttl
+		_, err := NewElector(fxtest.NewLifecycle(t), cfg, logger, nil)
+		require.Error(t, err, ttl)
+	}
+
+	cfg.LeaderElectionTTL = time.Second
+	elector, err := NewElector(fxtest.NewLifecycle(t), cfg, logger, nil)
+	require.NoError(t, err)
+	require.NotNil(t, elector)
+
+	// the TTL isn't used without leader election
+	cfg.LeaderElection = ElectionNone
+	cfg.LeaderElectionTTL = 0
+	_, err = NewElector(fxtest.NewLifecycle(t), cfg, logger, nil)
+	require.NoError(t, err)
+}
+
+type fakeElector struct {
+	alwaysLeader
+	leader atomic.Bool
+}
+
+func (e *fakeElector) IsLeader() bool { return e.leader.Load() }
+
+func TestWatchElected(t *testing.T) {
+	elector := &fakeElector{}
+	done := make(chan struct{})
+	defer close(done)
+
+	var elected atomic.Int32
+	WatchElected(elector, 10*time.Millisecond, done, func() {
+		elected.Add(1)
+	})
+
+	// called once per election, not while it stays leader
+	elector.leader.Store(true)
+	require.Eventually(t, func() bool { return elected.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), elected.Load())
+
+	elector.leader.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	elector.leader.Store(true)
+	require.Eventually(t, func() bool { return elected.Load() == 2 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,125 @@
+package leader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fileLockElector elects the replica holding an exclusive lock on a file in
+// a volume shared by all replicas. The lock is released when the leader
+// closes or its process exits, after which a standby takes it within one
+// retry interval.
+type fileLockElector struct {
+	path     string
+	identity string
+	interval time.Duration
+	logger   *zap.Logger
+
+	lock   sync.Mutex
+	file   *os.File
+	done   chan struct{}
+	leader atomic.Bool
+}
+
+func NewFileLockElector(path string, identity string, interval time.Duration, logger *zap.Logger) Elector {
+	return &fileLockElector{
+		path:     path,
+		identity: identity,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (e *fileLockElector) Start() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.done != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return fmt.Errorf("failed to create leader lock directory: %w", err)
+	}
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open leader lock file: %w", err)
+	}
+	e.file = file
+	e.done = make(chan struct{})
+
+	if e.tryLock() {
+		return nil
+	}
+
+	e.logger.Info("Waiting for leader lock", zap.String("path", e.path))
+	done := e.done
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.lock.Lock()
+				locked := e.done == done && e.tryLock()
+				e.lock.Unlock()
+				if locked {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// tryLock takes the lock if it is free. Callers hold e.lock.
+func (e *fileLockElector) tryLock() bool {
+	err := syscall.Flock(int(e.file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false
+	}
+	if err != nil {
+		e.logger.Error("failed to lock leader lock file", zap.String("path", e.path), zap.Error(err))
+		return false
+	}
+
+	// record the holder for anyone looking at the volume
+	if err := e.file.Truncate(0); err == nil {
+		e.file.WriteAt([]byte(e.identity+"\n"), 0)
+	}
+	e.leader.Store(true)
+	e.logger.Info("Became leader", zap.String("path", e.path), zap.String("identity", e.identity))
+	return true
+}
+
+func (e *fileLockElector) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.done == nil {
+		return nil
+	}
+	close(e.done)
+	e.done = nil
+
+	if e.leader.Swap(false) {
+		e.logger.Info("Releasing leadership", zap.String("path", e.path))
+		e.file.Truncate(0)
+	}
+	// closing the file releases the lock
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+func (e *fileLockElector) IsLeader() bool {
+	return e.leader.Load()
+}
@@ -0,0 +1,44 @@
+package leader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFileLockElector(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	path := filepath.Join(t.TempDir(), "leases", "leader.lock")
+
+	first := NewFileLockElector(path, "first", 10*time.Millisecond, logger)
+	second := NewFileLockElector(path, "second", 10*time.Millisecond, logger)
+
+	require.NoError(t, first.Start())
+	defer first.Close()
+	require.True(t, first.IsLeader())
+
+	require.NoError(t, second.Start())
+	defer second.Close()
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, second.IsLeader())
+
+	holder, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "first\n", string(holder))
+
+	// a standby takes over once the leader goes away
+	require.NoError(t, first.Close())
+	require.False(t, first.IsLeader())
+	require.Eventually(t, second.IsLeader, time.Second, 10*time.Millisecond)
+}
+
+func TestAlwaysLeader(t *testing.T) {
+	elector := NewAlwaysLeader()
+	require.NoError(t, elector.Start())
+	require.True(t, elector.IsLeader())
+	require.NoError(t, elector.Close())
+}
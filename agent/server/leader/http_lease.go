@@ -0,0 +1,162 @@
+package leader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LeaseRequest is the body the HTTP lease elector sends to its lease URL.
+//
+// A POST asks for the lease, or renews it, for ttl_seconds. The server
+// answers 200 if holder now has the lease and 409 if another holder does.
+// A DELETE gives up the lease if holder has it.
+type LeaseRequest struct {
+	Holder     string `json:"holder"`
+	TTLSeconds int    `json:"ttl_seconds"`
+}
+
+// httpLeaseElector elects the replica holding a time limited lease from an
+// HTTP lease server. The lease is renewed every third of its TTL, and the
+// replica stops considering itself leader once the lease runs out without a
+// renewal, timed from when the renewal was sent.
+type httpLeaseElector struct {
+	url      string
+	identity string
+	ttl      time.Duration
+	logger   *zap.Logger
+	client   *http.Client
+	now      func() time.Time
+
+	lock    sync.Mutex
+	done    chan struct{}
+	leading bool
+	expires atomic.Int64
+}
+
+func NewHttpLeaseElector(url string, identity string, ttl time.Duration, logger *zap.Logger) Elector {
+	return &httpLeaseElector{
+		url:      url,
+		identity: identity,
+		ttl:      ttl,
+		logger:   logger,
+		client:   &http.Client{Timeout: ttl / 3},
+		now:      time.Now,
+	}
+}
+
+func (e *httpLeaseElector) Start() error {
+	e.lock.Lock()
+	if e.done != nil {
+		e.lock.Unlock()
+		return nil
+	}
+	e.done = make(chan struct{})
+	done := e.done
+	e.lock.Unlock()
+
+	e.acquire()
+	go func() {
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.acquire()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// acquire asks for or renews the lease.
+func (e *httpLeaseElector) acquire() {
+	sent := e.now()
+	status, err := e.request(http.MethodPost)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.done == nil {
+		return
+	}
+
+	switch {
+	case err != nil:
+		e.logger.Warn("Failed to renew leader lease", zap.String("url", e.url), zap.Error(err))
+	case status == http.StatusOK:
+		e.expires.Store(sent.Add(e.ttl).UnixNano())
+	case status == http.StatusConflict:
+		e.expires.Store(0)
+	default:
+		e.logger.Warn("Unexpected leader lease response", zap.String("url", e.url), zap.Int("status", status))
+	}
+
+	leading := e.IsLeader()
+	if leading != e.leading {
+		if leading {
+			e.logger.Info("Became leader", zap.String("url", e.url), zap.String("identity", e.identity))
+		} else {
+			e.logger.Info("Lost leadership", zap.String("url", e.url), zap.String("identity", e.identity))
+		}
+		e.leading = leading
+	}
+}
+
+func (e *httpLeaseElector) request(method string) (int, error) {
+	body, err := json.Marshal(&LeaseRequest{
+		Holder:     e.identity,
+		TTLSeconds: int(e.ttl.Seconds()),
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(method, e.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid leader lease url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (e *httpLeaseElector) Close() error {
+	e.lock.Lock()
+	if e.done == nil {
+		e.lock.Unlock()
+		return nil
+	}
+	close(e.done)
+	e.done = nil
+	leading := e.IsLeader()
+	e.leading = false
+	e.expires.Store(0)
+	e.lock.Unlock()
+
+	if !leading {
+		return nil
+	}
+	e.logger.Info("Releasing leader lease", zap.String("url", e.url))
+	if _, err := e.request(http.MethodDelete); err != nil {
+		// the lease runs out on its own
+		e.logger.Warn("Failed to release leader lease", zap.String("url", e.url), zap.Error(err))
+	}
+	return nil
+}
+
+func (e *httpLeaseElector) IsLeader() bool {
+	return e.now().UnixNano() < e.expires.Load()
+}
@@ -0,0 +1,85 @@
+package leader
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// leaseServer is a stand-in for a lease server, granting a single lease.
+type leaseServer struct {
+	lock    sync.Mutex
+	holder  string
+	expires time.Time
+	down    bool
+}
+
+func (s *leaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	req := &LeaseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if s.holder != "" && s.holder != req.Holder && time.Now().Before(s.expires) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.holder = req.Holder
+		s.expires = time.Now().Add(time.Duration(req.TTLSeconds) * time.Second)
+	case http.MethodDelete:
+		if s.holder == req.Holder {
+			s.holder = ""
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestHttpLeaseElector(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	server := &leaseServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	first := NewHttpLeaseElector(ts.URL, "first", 3*time.Second, logger)
+	second := NewHttpLeaseElector(ts.URL, "second", 3*time.Second, logger).(*httpLeaseElector)
+
+	require.NoError(t, first.Start())
+	defer first.Close()
+	require.True(t, first.IsLeader())
+
+	require.NoError(t, second.Start())
+	defer second.Close()
+	require.False(t, second.IsLeader())
+
+	// closing releases the lease, so the standby takes it on its next renewal
+	require.NoError(t, first.Close())
+	require.False(t, first.IsLeader())
+	second.acquire()
+	require.True(t, second.IsLeader())
+
+	// leadership lapses once the lease can't be renewed
+	server.lock.Lock()
+	server.down = true
+	server.lock.Unlock()
+	now := time.Now()
+	second.now = func() time.Time { return now.Add(4 * time.Second) }
+	second.acquire()
+	require.False(t, second.IsLeader())
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/cortexapps/axon/server/api"
 	"github.com/cortexapps/axon/server/handler"
 	cortexHttp "github.com/cortexapps/axon/server/http"
+	"github.com/cortexapps/axon/server/leader"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -19,7 +20,9 @@ type MainHttpServerParams struct {
 	Logger         *zap.Logger
 	Registry       *prometheus.Registry
 	Transport      *http.Transport
-	HandlerManager handler.Manager `optional:"true"`
+	HandlerManager handler.Manager            `optional:"true"`
+	DeadLetters    cortexHttp.DeadLetterStore `optional:"true"`
+	Elector        leader.Elector             `optional:"true"`
 }
 
 func NewMainHttpServer(p MainHttpServerParams) cortexHttp.Server {
@@ -40,6 +43,8 @@ func NewMainHttpServer(p MainHttpServerParams) cortexHttp.Server {
 		Logger:         p.Logger,
 		Config:         p.Config,
 		HandlerManager: p.HandlerManager,
+		DeadLetters:    p.DeadLetters,
+		Elector:        p.Elector,
 	}
 	axonHandler := cortexHttp.NewAxonHandler(params)
 	httpServer.RegisterHandler(axonHandler)
@@ -2,14 +2,14 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
-	"os/signal"
+	"sort"
 	"sync"
-	"syscall"
 	"time"
 
 	pb "github.com/cortexapps/axon/.generated/proto/github.com/cortexapps/axon"
@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -43,11 +44,29 @@ type AxonAgent struct {
 	inflightLock        sync.RWMutex
 	outstandingRequests map[string]inflightRequest
 	historyManager      handler.HistoryManager
+	// watchesDone is closed on shutdown to end WatchHandlerExecutions streams
+	watchesDone     chan struct{}
+	stopWatchesOnce sync.Once
 }
 
 type inflightRequest struct {
-	invocable handler.Invocable
-	sentAt    time.Time
+	invocable  handler.Invocable
+	sentAt     time.Time
+	dispatchId string
+	stream     *dispatchStream
+}
+
+// dispatchStream serializes the messages sent on a dispatch stream, which
+// come from both its invocation loop and cancellations.
+type dispatchStream struct {
+	pb.AxonAgent_DispatchServer
+	sendLock sync.Mutex
+}
+
+func (d *dispatchStream) Send(msg *pb.DispatchMessage) error {
+	d.sendLock.Lock()
+	defer d.sendLock.Unlock()
+	return d.AxonAgent_DispatchServer.Send(msg)
 }
 
 type Params struct {
@@ -55,20 +74,26 @@ type Params struct {
 	Lifecycle fx.Lifecycle `optional:"true"`
 	Logger    *zap.Logger
 	Config    config.AgentConfig
-	Manager   handler.Manager `optional:"true"`
+	Manager   handler.Manager        `optional:"true"`
+	History   handler.HistoryManager `optional:"true"`
 }
 
 func NewAxonAgent(
 	p Params,
 ) *AxonAgent {
 	logger := p.Logger.Named("axon-server")
+	historyManager := p.History
+	if historyManager == nil {
+		historyManager = handler.NewHistoryManager(p.Config, logger)
+	}
 	agent := &AxonAgent{
 		config:              p.Config,
 		logger:              logger,
 		Manager:             p.Manager,
 		cortexApiServer:     api.NewCortexApiServer(logger, p.Config),
 		outstandingRequests: make(map[string]inflightRequest),
-		historyManager:      handler.NewHistoryManager(p.Config, logger),
+		historyManager:      historyManager,
+		watchesDone:         make(chan struct{}),
 	}
 
 	if p.Lifecycle != nil {
@@ -91,6 +116,10 @@ func (s *AxonAgent) RegisterHandler(ctx context.Context, req *pb.RegisterHandler
 		return nil, fmt.Errorf("handler manager is not initialized")
 	}
 
+	if pool := req.GetWorkerPool(); pool.GetName() != "" {
+		s.Manager.JoinWorkerPool(req.DispatchId, pool.Name, pool.Strategy)
+	}
+
 	id, err := s.Manager.RegisterHandler(req.DispatchId, req.HandlerName, time.Duration(req.TimeoutMs)*time.Millisecond, req.Options...)
 	return &pb.RegisterHandlerResponse{Id: id}, err
 }
@@ -149,7 +178,8 @@ func (s *AxonAgent) sendComplete(stream pb.AxonAgent_DispatchServer) error {
 	return nil
 }
 
-func (s *AxonAgent) sendInvocations(dispatchId string, stream pb.AxonAgent_DispatchServer) error {
+func (s *AxonAgent) sendInvocations(dispatchId string, server pb.AxonAgent_DispatchServer) error {
+	stream := &dispatchStream{AxonAgent_DispatchServer: server}
 
 	s.logger.Info("starting handler set", zap.String("dispatch-id", dispatchId))
 	err := s.Manager.Start(dispatchId)
@@ -201,15 +231,21 @@ func (s *AxonAgent) sendInvocations(dispatchId string, stream pb.AxonAgent_Dispa
 			}
 
 			s.setOutstandingRequest(msg.InvocationId, &inflightRequest{
-				invocable: invoke,
-				sentAt:    now,
+				invocable:  invoke,
+				sentAt:     now,
+				dispatchId: dispatchId,
+				stream:     stream,
 			})
 			go func(requestId string) {
 				<-time.After(time.Duration(msg.TimeoutMs) * time.Millisecond)
-				s.ReportInvocation(context.Background(), &pb.ReportInvocationRequest{
-					HandlerInvoke: msg,
-					Message:       &pb.ReportInvocationRequest_Error{Error: &pb.Error{Code: "timeout"}},
-				})
+				s.inflightLock.RLock()
+				current, ok := s.outstandingRequests[requestId]
+				s.inflightLock.RUnlock()
+				if !ok || !current.sentAt.Equal(now) {
+					// reported, or requeued from a worker pool client that disconnected and sent again
+					return
+				}
+				s.cancelRequest(requestId, pb.CancelReason_CANCEL_REASON_TIMEOUT)
 			}(msg.InvocationId)
 		}
 	}()
@@ -227,6 +263,60 @@ func (s *AxonAgent) setOutstandingRequest(id string, req *inflightRequest) {
 	s.outstandingRequests[id] = *req
 }
 
+// cancelRequest tells the client running an outstanding invocation to stop,
+// and completes it with the error code for the reason. It returns false if
+// the invocation isn't outstanding.
+func (s *AxonAgent) cancelRequest(requestId string, reason pb.CancelReason) bool {
+	s.inflightLock.RLock()
+	ifr, ok := s.outstandingRequests[requestId]
+	s.inflightLock.RUnlock()
+	if !ok {
+		return false
+	}
+
+	msg := ifr.invocable.ToDispatchInvoke()
+	if ifr.stream != nil {
+		err := ifr.stream.Send(&pb.DispatchMessage{
+			Type: pb.DispatchMessageType_DISPATCH_MESSAGE_CANCEL,
+			Message: &pb.DispatchMessage_Cancel{Cancel: &pb.DispatchCancel{
+				InvocationId: requestId,
+				Reason:       reason,
+			}},
+		})
+		if err != nil {
+			s.logger.Warn("failed to send cancellation to client", zap.String("invocation-id", requestId), zap.Error(err))
+		}
+	}
+
+	invocationErr := &pb.Error{Code: handler.ErrorCodeCancelled, Message: "invocation was cancelled"}
+	switch reason {
+	case pb.CancelReason_CANCEL_REASON_TIMEOUT:
+		invocationErr = &pb.Error{Code: handler.ErrorCodeTimeout}
+	case pb.CancelReason_CANCEL_REASON_SHUTDOWN:
+		invocationErr.Message = "agent is shutting down"
+	}
+	s.ReportInvocation(context.Background(), &pb.ReportInvocationRequest{
+		HandlerInvoke: msg,
+		Message:       &pb.ReportInvocationRequest_Error{Error: invocationErr},
+	})
+	return true
+}
+
+// cancelOutstanding cancels every invocation sent to a client that hasn't
+// been reported.
+func (s *AxonAgent) cancelOutstanding(reason pb.CancelReason) {
+	s.inflightLock.RLock()
+	ids := make([]string, 0, len(s.outstandingRequests))
+	for id := range s.outstandingRequests {
+		ids = append(ids, id)
+	}
+	s.inflightLock.RUnlock()
+
+	for _, id := range ids {
+		s.cancelRequest(id, reason)
+	}
+}
+
 // ReportInvocation is called by the client to report the result of an invocation, which will
 // log the result of an invocation into the history path.
 func (s *AxonAgent) ReportInvocation(ctx context.Context, req *pb.ReportInvocationRequest) (*pb.ReportInvocationResponse, error) {
@@ -242,7 +332,10 @@ func (s *AxonAgent) ReportInvocation(ctx context.Context, req *pb.ReportInvocati
 	var requestErr error
 
 	if req.GetError() != nil {
-		requestErr = fmt.Errorf("invocation error: %s", req.GetError().GetMessage())
+		requestErr = &handler.InvocationError{
+			Code:    req.GetError().GetCode(),
+			Message: req.GetError().GetMessage(),
+		}
 	}
 	requestResult := ""
 
@@ -256,6 +349,11 @@ func (s *AxonAgent) ReportInvocation(ctx context.Context, req *pb.ReportInvocati
 	if err != nil {
 		s.logger.Error("failed to write history file", zap.Error(err))
 	}
+	if s.Manager != nil {
+		for _, event := range handler.NewCompletionEvents(execution) {
+			s.Manager.PublishExecutionEvent(event)
+		}
+	}
 	return &pb.ReportInvocationResponse{}, nil
 }
 
@@ -270,37 +368,314 @@ func (s *AxonAgent) ListHandlers(ctx context.Context, req *pb.ListHandlersReques
 	resp := &pb.ListHandlersResponse{
 		Handlers: make([]*pb.HandlerInfo, 0),
 	}
-	for _, handler := range handlers {
+	now := time.Now()
+	// handlers registered by several clients share a history
+	summaries := map[string]handler.RunSummary{}
+	for _, entry := range handlers {
 
 		var lastInvoked *timestamppb.Timestamp = nil
-		if handler.LastInvoked() != nil {
-			lastInvoked = timestamppb.New(*handler.LastInvoked())
+		if entry.LastInvoked() != nil {
+			lastInvoked = timestamppb.New(*entry.LastInvoked())
 		}
 
-		resp.Handlers = append(resp.Handlers, &pb.HandlerInfo{
-			Id:                         handler.Id(),
-			Name:                       handler.Name(),
-			DispatchId:                 handler.DispatchId(),
-			Options:                    handler.Options(),
-			IsActive:                   handler.IsActive(),
+		info := &pb.HandlerInfo{
+			Id:                         entry.Id(),
+			Name:                       entry.Name(),
+			DispatchId:                 entry.DispatchId(),
+			Options:                    entry.Options(),
+			IsActive:                   entry.IsActive(),
 			LastInvokedClientTimestamp: lastInvoked,
-		})
+		}
+		if pausable, ok := entry.(handler.Pausable); ok {
+			info.IsPaused = pausable.IsPaused()
+		}
+		if scheduled, ok := entry.(handler.Scheduled); ok {
+			if next := scheduled.NextRun(now); next != nil {
+				info.NextRunTimestamp = timestamppb.New(*next)
+			}
+			info.IntervalMs = scheduled.Interval().Milliseconds()
+		}
+
+		summary, ok := summaries[entry.Name()]
+		if !ok && s.historyManager != nil {
+			var err error
+			summary, err = handler.SummarizeHandlerRuns(ctx, s.historyManager, entry.Name())
+			if err != nil {
+				s.logger.Error("failed to read handler history", zap.String("handler", entry.Name()), zap.Error(err))
+			}
+			summaries[entry.Name()] = summary
+		}
+		info.LastResult = summary.LastResult
+		info.LastDurationMs = summary.LastDurationMs
+		info.LastError = summary.LastError
+		info.ConsecutiveFailures = summary.ConsecutiveFailures
+		info.ConsecutiveFailuresAtLeast = summary.ConsecutiveFailuresAtLeast
+
+		resp.Handlers = append(resp.Handlers, info)
+	}
+	return resp, nil
+}
+
+// PauseHandler stops the scheduled runs of a handler until it is resumed
+func (s *AxonAgent) PauseHandler(ctx context.Context, req *pb.PauseHandlerRequest) (*pb.PauseHandlerResponse, error) {
+	if s.Manager == nil {
+		return nil, fmt.Errorf("handler manager is not initialized")
+	}
+	if err := handler.SetPaused(s.Manager, req.HandlerName, true); err != nil {
+		return nil, handlerControlError(req.HandlerName, err)
+	}
+	return &pb.PauseHandlerResponse{}, nil
+}
+
+// ResumeHandler restarts the scheduled runs of a paused handler
+func (s *AxonAgent) ResumeHandler(ctx context.Context, req *pb.ResumeHandlerRequest) (*pb.ResumeHandlerResponse, error) {
+	if s.Manager == nil {
+		return nil, fmt.Errorf("handler manager is not initialized")
+	}
+	if err := handler.SetPaused(s.Manager, req.HandlerName, false); err != nil {
+		return nil, handlerControlError(req.HandlerName, err)
+	}
+	return &pb.ResumeHandlerResponse{}, nil
+}
+
+// TriggerHandler queues a run of a scheduled handler now
+func (s *AxonAgent) TriggerHandler(ctx context.Context, req *pb.TriggerHandlerRequest) (*pb.TriggerHandlerResponse, error) {
+	if s.Manager == nil {
+		return nil, fmt.Errorf("handler manager is not initialized")
+	}
+	invoke, err := handler.TriggerRun(s.Manager, req.HandlerName)
+	if err != nil {
+		return nil, handlerControlError(req.HandlerName, err)
+	}
+	return &pb.TriggerHandlerResponse{InvocationId: invoke.ToDispatchInvoke().InvocationId}, nil
+}
+
+func handlerControlError(handlerName string, err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return status.Errorf(codes.NotFound, "handler %s not found", handlerName)
+	case errors.Is(err, handler.ErrNotScheduled):
+		return status.Errorf(codes.FailedPrecondition, "handler %s is not a scheduled handler", handlerName)
+	case errors.Is(err, handler.ErrDispatchQueueFull), errors.Is(err, handler.ErrQueueFull):
+		return status.Errorf(codes.ResourceExhausted, "handler %s queue is full: %v", handlerName, err)
+	case errors.Is(err, handler.ErrDraining):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return err
+}
+
+// CancelInvocation cancels a queued or running invocation, along with any
+// attempt of it a retry policy has sent
+func (s *AxonAgent) CancelInvocation(ctx context.Context, req *pb.CancelInvocationRequest) (*pb.CancelInvocationResponse, error) {
+	if s.Manager == nil {
+		return nil, fmt.Errorf("handler manager is not initialized")
+	}
+
+	// cancel the invocation first so a retry policy doesn't retry the
+	// attempts cancelled below
+	err := s.Manager.Cancel(req.InvocationId)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	found := err == nil
+
+	s.inflightLock.RLock()
+	ids := []string{}
+	for id, ifr := range s.outstandingRequests {
+		if id == req.InvocationId || ifr.invocable.ToDispatchInvoke().OriginalInvocationId == req.InvocationId {
+			ids = append(ids, id)
+		}
+	}
+	s.inflightLock.RUnlock()
+
+	for _, id := range ids {
+		if s.cancelRequest(id, pb.CancelReason_CANCEL_REASON_REQUESTED) {
+			found = true
+		}
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "invocation %s not found", req.InvocationId)
+	}
+	return &pb.CancelInvocationResponse{}, nil
+}
+
+// ListInvocations returns the invocations waiting to be sent to a client, those
+// sent and not yet reported, and those that recently completed
+func (s *AxonAgent) ListInvocations(ctx context.Context, req *pb.ListInvocationsRequest) (*pb.ListInvocationsResponse, error) {
+	resp := &pb.ListInvocationsResponse{
+		Invocations: make([]*pb.InvocationInfo, 0),
+	}
+	if s.Manager == nil {
+		return resp, nil
+	}
+
+	now := time.Now()
+	statuses := s.Manager.Invocations()
+	queuedAt := map[string]time.Time{}
+	for _, status := range statuses {
+		if !status.Completed() {
+			queuedAt[status.Invocation.InvocationId] = status.QueuedAt
+		}
+	}
+
+	s.inflightLock.RLock()
+	inflight := make([]inflightRequest, 0, len(s.outstandingRequests))
+	for _, ifr := range s.outstandingRequests {
+		inflight = append(inflight, ifr)
+	}
+	s.inflightLock.RUnlock()
+	sort.Slice(inflight, func(i, j int) bool {
+		return inflight[i].sentAt.Before(inflight[j].sentAt)
+	})
+
+	// an invocation is in flight while any attempt of it is
+	sent := map[string]bool{}
+	for _, ifr := range inflight {
+		msg := ifr.invocable.ToDispatchInvoke()
+		sent[msg.OriginalInvocationId] = true
+		if req.HandlerName != "" && msg.HandlerName != req.HandlerName {
+			continue
+		}
+		info := newInvocationInfo(msg, pb.InvocationState_INVOCATION_STATE_IN_FLIGHT)
+		info.DispatchId = ifr.dispatchId
+		if queued, ok := queuedAt[msg.OriginalInvocationId]; ok {
+			info.QueuedTimestamp = timestamppb.New(queued)
+		}
+		info.SentTimestamp = timestamppb.New(ifr.sentAt)
+		info.ElapsedMs = now.Sub(ifr.sentAt).Milliseconds()
+		resp.Invocations = append(resp.Invocations, info)
+	}
+
+	for _, status := range statuses {
+		msg := status.Invocation
+		if req.HandlerName != "" && msg.HandlerName != req.HandlerName {
+			continue
+		}
+		if !status.Completed() {
+			if sent[msg.InvocationId] {
+				continue
+			}
+			info := newInvocationInfo(msg, pb.InvocationState_INVOCATION_STATE_QUEUED)
+			info.QueuedTimestamp = timestamppb.New(status.QueuedAt)
+			info.ElapsedMs = now.Sub(status.QueuedAt).Milliseconds()
+			resp.Invocations = append(resp.Invocations, info)
+			continue
+		}
+
+		info := newInvocationInfo(msg, pb.InvocationState_INVOCATION_STATE_COMPLETED)
+		info.QueuedTimestamp = timestamppb.New(status.QueuedAt)
+		if !status.DispatchedAt.IsZero() {
+			info.SentTimestamp = timestamppb.New(status.DispatchedAt)
+		}
+		info.CompletedTimestamp = timestamppb.New(status.CompletedAt)
+		info.ElapsedMs = status.CompletedAt.Sub(status.QueuedAt).Milliseconds()
+		if status.Err != nil {
+			info.Error = &pb.Error{
+				Code:    handler.ErrorCode(status.Err),
+				Message: status.Err.Error(),
+			}
+		}
+		resp.Invocations = append(resp.Invocations, info)
 	}
 	return resp, nil
 }
 
+func newInvocationInfo(msg *pb.DispatchHandlerInvoke, state pb.InvocationState) *pb.InvocationInfo {
+	argsSize := 0
+	for name, value := range msg.Args {
+		argsSize += len(name) + len(value)
+	}
+	return &pb.InvocationInfo{
+		InvocationId:         msg.InvocationId,
+		OriginalInvocationId: msg.OriginalInvocationId,
+		HandlerName:          msg.HandlerName,
+		HandlerId:            msg.HandlerId,
+		DispatchId:           msg.DispatchId,
+		Reason:               msg.Reason,
+		Attempt:              msg.Attempt,
+		State:                state,
+		ArgsSize:             int32(argsSize),
+	}
+}
+
 // GetHandlerHistory returns the history of a handler
 func (s *AxonAgent) GetHandlerHistory(ctx context.Context, req *pb.GetHandlerHistoryRequest) (*pb.GetHandlerHistoryResponse, error) {
-	history, err := s.historyManager.GetHistory(ctx, req.HandlerName, req.IncludeLogs, req.Tail)
+	query := handler.HistoryQuery{
+		HandlerName:  req.HandlerName,
+		Tail:         req.Tail,
+		Cursor:       req.Cursor,
+		Result:       req.Result,
+		DispatchId:   req.DispatchId,
+		InvocationId: req.InvocationId,
+		IncludeLogs:  req.IncludeLogs,
+	}
+	if req.StartTime != nil {
+		query.Start = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		query.End = req.EndTime.AsTime()
+	}
+	page, err := s.historyManager.Query(ctx, query)
+	if errors.Is(err, handler.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
 	resp := &pb.GetHandlerHistoryResponse{
-		History: history,
+		History:    page.Executions,
+		NextCursor: page.NextCursor,
 	}
 	return resp, err
 }
 
+// WatchHandlerExecutions streams the execution events of a handler, or of
+// every handler if none is named, as they happen, until the client goes away
+// or the agent shuts down.
+func (s *AxonAgent) WatchHandlerExecutions(req *pb.WatchHandlerExecutionsRequest, stream pb.AxonAgent_WatchHandlerExecutionsServer) error {
+	if s.Manager == nil {
+		return status.Error(codes.Unavailable, "handler manager is not running")
+	}
+
+	events, stop := s.Manager.WatchExecutions(req.HandlerName)
+	defer stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.watchesDone:
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !req.IncludeLogs {
+				if event.Type == pb.HandlerExecutionEventType_EXECUTION_EVENT_LOG {
+					continue
+				}
+				if event.Execution != nil && len(event.Execution.Logs) > 0 {
+					// events are shared with other watchers, so copy rather
+					// than clear the logs
+					event = protobuf.Clone(event).(*pb.HandlerExecutionEvent)
+					event.Execution.Logs = nil
+				}
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stopWatches ends the WatchHandlerExecutions streams, which would otherwise
+// hold up a graceful stop.
+func (s *AxonAgent) stopWatches() {
+	s.stopWatchesOnce.Do(func() {
+		close(s.watchesDone)
+	})
+}
+
 // Start starts the gRPC server and listens for incoming requests
 func (s *AxonAgent) Start(ctx context.Context) error {
 
@@ -325,10 +700,6 @@ func (s *AxonAgent) Start(ctx context.Context) error {
 	// Register reflection service on gRPC server.
 	reflection.Register(s.grpcServer)
 
-	// Set up a channel to listen for interrupt signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
 	// Run the server in a goroutine
 	go func() {
 		log.Printf("server listening at %v", lis.Addr())
@@ -337,19 +708,53 @@ func (s *AxonAgent) Start(ctx context.Context) error {
 		}
 	}()
 
-	go func() {
-		// Block until we receive an interrupt signal
-		<-sigChan
-		log.Println("Received interrupt signal. Shutting down server...")
-
-		s.grpcServer.GracefulStop()
-	}()
+	return nil
+}
 
+// Stop drains invocations, then stops the gRPC server. Clients keep their
+// dispatch streams open, so it doesn't wait for them to finish.
+func (s *AxonAgent) Stop(ctx context.Context) error {
+	s.logger.Info("Shutting down server")
+	s.drain()
+	s.stopWatches()
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
 	return nil
 }
 
+// drain stops accepting invocations and waits up to the shutdown drain timeout
+// for those queued and running to complete, then tells the clients running
+// any that haven't to stop.
+func (s *AxonAgent) drain() {
+	if s.Manager == nil {
+		s.cancelOutstanding(pb.CancelReason_CANCEL_REASON_SHUTDOWN)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownDrainTimeout)
+	defer cancel()
+	summary := s.Manager.Drain(ctx)
+
+	s.inflightLock.RLock()
+	inFlight := len(s.outstandingRequests)
+	s.inflightLock.RUnlock()
+	s.cancelOutstanding(pb.CancelReason_CANCEL_REASON_SHUTDOWN)
+
+	s.logger.Info("Drained invocations",
+		zap.Int("completed", summary.Completed),
+		zap.Int("cancelled", summary.Cancelled),
+		zap.Int("persisted", summary.Persisted),
+		zap.Int("in-flight", inFlight),
+	)
+}
+
 // Close stops the gRPC server
 func (s *AxonAgent) Close() {
+	s.stopWatches()
+	if s.historyManager != nil {
+		s.cancelOutstanding(pb.CancelReason_CANCEL_REASON_SHUTDOWN)
+	}
 	if s.grpcServer != nil {
 		s.grpcServer.Stop()
 		s.grpcServer = nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -17,6 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx/fxtest"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGRPCServer_RegisterHandlerAndDispatch(t *testing.T) {
@@ -116,6 +119,9 @@ func TestGRPCServer_RegisterHandlerAndDispatch(t *testing.T) {
 	handlers, err := client.ListHandlers(ctx)
 	require.NoError(t, err)
 	require.Len(t, handlers, 1)
+	require.Equal(t, int64(1), handlers[0].IntervalMs)
+	require.Equal(t, pb.HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS, handlers[0].LastResult)
+	require.Equal(t, int32(0), handlers[0].ConsecutiveFailures)
 
 	// get handler history
 	history, err := client.GetHandlerHistory(ctx, handlers[0].Name)
@@ -327,3 +333,265 @@ func getRandomPort() int {
 	ln.Close()
 	return port
 }
+
+func TestGRPCServer_CancelInvocation(t *testing.T) {
+
+	port := getRandomPort()
+
+	config := config.AgentConfig{
+		GrpcPort:           port,
+		CortexApiBaseUrl:   "http://localhost",
+		CortexApiToken:     "test-token",
+		DequeueWaitTime:    1 * time.Second,
+		HandlerHistoryPath: t.TempDir(),
+	}
+
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	agent := NewAxonAgent(Params{
+		Logger:  logger,
+		Config:  config,
+		Manager: manager,
+	})
+	defer agent.Close()
+
+	go func() {
+		if err := agent.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	cancelled := make(chan *pb.DispatchCancel, 1)
+
+	client := NewTestAxonClient(t, int32(port), func(msg *pb.DispatchMessage) (clientResult, error) {
+		switch msg.Type {
+		case pb.DispatchMessageType_DISPATCH_MESSAGE_INVOKE:
+			started <- msg.GetInvoke().InvocationId
+			<-release
+			return clientResult{result: "too late"}, nil
+		case pb.DispatchMessageType_DISPATCH_MESSAGE_CANCEL:
+			cancelled <- msg.GetCancel()
+			return clientResult{done: true}, nil
+		}
+		return clientResult{}, nil
+	})
+	defer client.Close()
+
+	_, err := client.RegisterHandler(ctx, "handler123",
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{
+					Type: pb.HandlerInvokeType_INVOKE,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	go func() {
+		client.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := handler.TriggerInvoke(ctx, manager, "handler123", "")
+		errs <- err
+	}()
+
+	invocationId := <-started
+	invocations, err := client.ListInvocations(ctx, "handler123")
+	require.NoError(t, err)
+	require.Len(t, invocations, 1)
+	require.Equal(t, invocationId, invocations[0].InvocationId)
+	require.Equal(t, pb.InvocationState_INVOCATION_STATE_IN_FLIGHT, invocations[0].State)
+	require.Equal(t, client.dispatchId, invocations[0].DispatchId)
+	require.NotNil(t, invocations[0].SentTimestamp)
+
+	require.NoError(t, client.CancelInvocation(ctx, invocationId))
+	require.Equal(t, handler.ErrorCodeCancelled, handler.ErrorCode(<-errs))
+
+	require.Eventually(t, func() bool {
+		invocations, err = client.ListInvocations(ctx, "")
+		require.NoError(t, err)
+		return len(invocations) == 1 && invocations[0].State == pb.InvocationState_INVOCATION_STATE_COMPLETED
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, handler.ErrorCodeCancelled, invocations[0].Error.Code)
+
+	invocations, err = client.ListInvocations(ctx, "other")
+	require.NoError(t, err)
+	require.Empty(t, invocations)
+	close(release)
+
+	msg := <-cancelled
+	require.Equal(t, invocationId, msg.InvocationId)
+	require.Equal(t, pb.CancelReason_CANCEL_REASON_REQUESTED, msg.Reason)
+
+	history, err := client.GetHandlerHistory(ctx, "handler123")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, handler.ErrorCodeCancelled, history[0].Error.Code)
+
+	err = client.CancelInvocation(ctx, invocationId)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCServer_WatchHandlerExecutions(t *testing.T) {
+
+	port := getRandomPort()
+
+	config := config.AgentConfig{
+		GrpcPort:           port,
+		CortexApiBaseUrl:   "http://localhost",
+		CortexApiToken:     "test-token",
+		DequeueWaitTime:    1 * time.Second,
+		HandlerHistoryPath: t.TempDir(),
+	}
+
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	agent := NewAxonAgent(Params{
+		Logger:  logger,
+		Config:  config,
+		Manager: manager,
+	})
+	defer agent.Close()
+
+	go func() {
+		if err := agent.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	client := NewTestAxonClient(t, int32(port), func(msg *pb.DispatchMessage) (clientResult, error) {
+		return clientResult{result: "ok"}, nil
+	})
+	defer client.Close()
+
+	_, err := client.RegisterHandler(ctx, "handler123",
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{
+					Type: pb.HandlerInvokeType_INVOKE,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	go func() {
+		client.Run(ctx)
+	}()
+
+	stream, err := client.client.WatchHandlerExecutions(ctx, &pb.WatchHandlerExecutionsRequest{HandlerName: "handler123"})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	result, err := handler.TriggerInvoke(ctx, manager, "handler123", "")
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+
+	var invocationId string
+	for _, eventType := range []pb.HandlerExecutionEventType{
+		pb.HandlerExecutionEventType_EXECUTION_EVENT_QUEUED,
+		pb.HandlerExecutionEventType_EXECUTION_EVENT_DISPATCHED,
+		pb.HandlerExecutionEventType_EXECUTION_EVENT_COMPLETED,
+	} {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, eventType, event.Type)
+		require.Equal(t, "handler123", event.HandlerName)
+		if invocationId == "" {
+			invocationId = event.InvocationId
+		}
+		require.Equal(t, invocationId, event.InvocationId)
+	}
+
+	// the stream ends when the agent shuts down
+	agent.stopWatches()
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestGRPCServer_StopDrains(t *testing.T) {
+
+	port := getRandomPort()
+
+	config := config.AgentConfig{
+		GrpcPort:             port,
+		CortexApiBaseUrl:     "http://localhost",
+		CortexApiToken:       "test-token",
+		DequeueWaitTime:      100 * time.Millisecond,
+		HandlerHistoryPath:   t.TempDir(),
+		ShutdownDrainTimeout: 5 * time.Second,
+	}
+
+	logger, _ := zap.NewDevelopment()
+	manager := handler.NewHandlerManager(logger, cron.New(), nil)
+
+	agent := NewAxonAgent(Params{
+		Logger:  logger,
+		Config:  config,
+		Manager: manager,
+	})
+	defer agent.Close()
+	require.NoError(t, agent.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	client := NewTestAxonClient(t, int32(port), func(msg *pb.DispatchMessage) (clientResult, error) {
+		if msg.Type == pb.DispatchMessageType_DISPATCH_MESSAGE_INVOKE {
+			started <- struct{}{}
+			time.Sleep(200 * time.Millisecond)
+			return clientResult{result: "finished"}, nil
+		}
+		return clientResult{}, nil
+	})
+	defer client.Close()
+
+	_, err := client.RegisterHandler(ctx, "handler123",
+		&pb.HandlerOption{
+			Option: &pb.HandlerOption_Invoke{
+				Invoke: &pb.HandlerInvokeOption{
+					Type: pb.HandlerInvokeType_INVOKE,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	go func() {
+		client.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	results := make(chan string, 1)
+	go func() {
+		result, _ := handler.TriggerInvoke(ctx, manager, "handler123", "")
+		results <- result
+	}()
+	<-started
+
+	// Stop waits for the running invocation to be reported
+	require.NoError(t, agent.Stop(ctx))
+	select {
+	case result := <-results:
+		require.Equal(t, "finished", result)
+	default:
+		require.Fail(t, "stopped before the invocation was reported")
+	}
+}
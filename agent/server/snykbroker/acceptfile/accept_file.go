@@ -7,7 +7,6 @@ import (
 	"os"
 
 	"github.com/cortexapps/axon/config"
-	axonHttp "github.com/cortexapps/axon/server/http"
 	"go.uber.org/zap"
 )
 
@@ -113,7 +112,7 @@ func (a *AcceptFile) addAxonRoute(renderContext RenderContext) error {
 
 	entry := acceptFileRule{
 		Method: "any",
-		Path:   fmt.Sprintf("%s/*", axonHttp.AxonPathRoot),
+		Path:   fmt.Sprintf("%s/*", config.AxonPathRoot),
 		Origin: a.config.HttpBaseUrl(),
 	}
 
@@ -145,6 +145,19 @@ func (c *testAxonClient) ListHandlers(ctx context.Context) ([]*pb.HandlerInfo, e
 	return resp.Handlers, nil
 }
 
+func (c *testAxonClient) CancelInvocation(ctx context.Context, invocationId string) error {
+	_, err := c.client.CancelInvocation(ctx, &pb.CancelInvocationRequest{InvocationId: invocationId})
+	return err
+}
+
+func (c *testAxonClient) ListInvocations(ctx context.Context, handlerName string) ([]*pb.InvocationInfo, error) {
+	resp, err := c.client.ListInvocations(ctx, &pb.ListInvocationsRequest{HandlerName: handlerName})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Invocations, nil
+}
+
 func (c *testAxonClient) GetHandlerHistory(ctx context.Context, name string) ([]*pb.HandlerExecution, error) {
 	resp, err := c.client.GetHandlerHistory(ctx, &pb.GetHandlerHistoryRequest{HandlerName: name})
 	if err != nil {
@@ -44,3 +44,12 @@ func TimeFromString(timeStr string) (time.Time, error) {
 func TimeToString(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
+
+// TimeOrAgoFromString parses timeStr as a time TimeFromString accepts, or as
+// a duration, like 90m, before now.
+func TimeOrAgoFromString(timeStr string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(timeStr); err == nil {
+		return now.Add(-d), nil
+	}
+	return TimeFromString(timeStr)
+}
@@ -59,3 +59,18 @@ func TestTimeFromString_InvalidFormat(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to parse time")
 }
+
+func TestTimeOrAgoFromString(t *testing.T) {
+	now := time.Now()
+
+	parsedTime, err := TimeOrAgoFromString("90m", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-90*time.Minute), parsedTime)
+
+	parsedTime, err = TimeOrAgoFromString("2023-10-10T10:10:10Z", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 10, 10, 10, 10, 10, 0, time.UTC), parsedTime)
+
+	_, err = TimeOrAgoFromString("yesterday", now)
+	require.Error(t, err)
+}
@@ -22,6 +22,55 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type WorkerPoolStrategy int32
+
+const (
+	// WORKER_POOL_ROUND_ROBIN sends invocations to each client in turn
+	WorkerPoolStrategy_WORKER_POOL_ROUND_ROBIN WorkerPoolStrategy = 0
+	// WORKER_POOL_LEAST_OUTSTANDING sends each invocation to the client with
+	// the fewest invocations it hasn't reported yet
+	WorkerPoolStrategy_WORKER_POOL_LEAST_OUTSTANDING WorkerPoolStrategy = 1
+)
+
+// Enum value maps for WorkerPoolStrategy.
+var (
+	WorkerPoolStrategy_name = map[int32]string{
+		0: "WORKER_POOL_ROUND_ROBIN",
+		1: "WORKER_POOL_LEAST_OUTSTANDING",
+	}
+	WorkerPoolStrategy_value = map[string]int32{
+		"WORKER_POOL_ROUND_ROBIN":       0,
+		"WORKER_POOL_LEAST_OUTSTANDING": 1,
+	}
+)
+
+func (x WorkerPoolStrategy) Enum() *WorkerPoolStrategy {
+	p := new(WorkerPoolStrategy)
+	*p = x
+	return p
+}
+
+func (x WorkerPoolStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WorkerPoolStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[0].Descriptor()
+}
+
+func (WorkerPoolStrategy) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[0]
+}
+
+func (x WorkerPoolStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WorkerPoolStrategy.Descriptor instead.
+func (WorkerPoolStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{0}
+}
+
 type HandlerInvokeType int32
 
 const (
@@ -30,6 +79,11 @@ const (
 	HandlerInvokeType_CRON_SCHEDULE HandlerInvokeType = 2
 	HandlerInvokeType_RUN_INTERVAL  HandlerInvokeType = 3
 	HandlerInvokeType_WEBHOOK       HandlerInvokeType = 4
+	// CATCH_UP re-runs a CRON_SCHEDULE time missed while the agent was down,
+	// which is passed in the scheduled-time arg
+	HandlerInvokeType_CATCH_UP HandlerInvokeType = 5
+	// UPSTREAM runs a handler after a handler it depends on succeeded
+	HandlerInvokeType_UPSTREAM HandlerInvokeType = 6
 )
 
 // Enum value maps for HandlerInvokeType.
@@ -40,6 +94,8 @@ var (
 		2: "CRON_SCHEDULE",
 		3: "RUN_INTERVAL",
 		4: "WEBHOOK",
+		5: "CATCH_UP",
+		6: "UPSTREAM",
 	}
 	HandlerInvokeType_value = map[string]int32{
 		"INVOKE":        0,
@@ -47,6 +103,8 @@ var (
 		"CRON_SCHEDULE": 2,
 		"RUN_INTERVAL":  3,
 		"WEBHOOK":       4,
+		"CATCH_UP":      5,
+		"UPSTREAM":      6,
 	}
 )
 
@@ -61,11 +119,11 @@ func (x HandlerInvokeType) String() string {
 }
 
 func (HandlerInvokeType) Descriptor() protoreflect.EnumDescriptor {
-	return file_cortex_axon_agent_proto_enumTypes[0].Descriptor()
+	return file_cortex_axon_agent_proto_enumTypes[1].Descriptor()
 }
 
 func (HandlerInvokeType) Type() protoreflect.EnumType {
-	return &file_cortex_axon_agent_proto_enumTypes[0]
+	return &file_cortex_axon_agent_proto_enumTypes[1]
 }
 
 func (x HandlerInvokeType) Number() protoreflect.EnumNumber {
@@ -74,7 +132,307 @@ func (x HandlerInvokeType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HandlerInvokeType.Descriptor instead.
 func (HandlerInvokeType) EnumDescriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{0}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{1}
+}
+
+type HandlerOverflowPolicy int32
+
+const (
+	HandlerOverflowPolicy_OVERFLOW_SKIP      HandlerOverflowPolicy = 0
+	HandlerOverflowPolicy_OVERFLOW_QUEUE_ONE HandlerOverflowPolicy = 1
+)
+
+// Enum value maps for HandlerOverflowPolicy.
+var (
+	HandlerOverflowPolicy_name = map[int32]string{
+		0: "OVERFLOW_SKIP",
+		1: "OVERFLOW_QUEUE_ONE",
+	}
+	HandlerOverflowPolicy_value = map[string]int32{
+		"OVERFLOW_SKIP":      0,
+		"OVERFLOW_QUEUE_ONE": 1,
+	}
+)
+
+func (x HandlerOverflowPolicy) Enum() *HandlerOverflowPolicy {
+	p := new(HandlerOverflowPolicy)
+	*p = x
+	return p
+}
+
+func (x HandlerOverflowPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HandlerOverflowPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[2].Descriptor()
+}
+
+func (HandlerOverflowPolicy) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[2]
+}
+
+func (x HandlerOverflowPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HandlerOverflowPolicy.Descriptor instead.
+func (HandlerOverflowPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{2}
+}
+
+type HandlerMisfirePolicy int32
+
+const (
+	HandlerMisfirePolicy_MISFIRE_SKIP     HandlerMisfirePolicy = 0
+	HandlerMisfirePolicy_MISFIRE_RUN_ONCE HandlerMisfirePolicy = 1
+	HandlerMisfirePolicy_MISFIRE_RUN_ALL  HandlerMisfirePolicy = 2
+)
+
+// Enum value maps for HandlerMisfirePolicy.
+var (
+	HandlerMisfirePolicy_name = map[int32]string{
+		0: "MISFIRE_SKIP",
+		1: "MISFIRE_RUN_ONCE",
+		2: "MISFIRE_RUN_ALL",
+	}
+	HandlerMisfirePolicy_value = map[string]int32{
+		"MISFIRE_SKIP":     0,
+		"MISFIRE_RUN_ONCE": 1,
+		"MISFIRE_RUN_ALL":  2,
+	}
+)
+
+func (x HandlerMisfirePolicy) Enum() *HandlerMisfirePolicy {
+	p := new(HandlerMisfirePolicy)
+	*p = x
+	return p
+}
+
+func (x HandlerMisfirePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HandlerMisfirePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[3].Descriptor()
+}
+
+func (HandlerMisfirePolicy) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[3]
+}
+
+func (x HandlerMisfirePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HandlerMisfirePolicy.Descriptor instead.
+func (HandlerMisfirePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{3}
+}
+
+type WebhookVerificationType int32
+
+const (
+	WebhookVerificationType_WEBHOOK_VERIFICATION_NONE        WebhookVerificationType = 0
+	WebhookVerificationType_WEBHOOK_VERIFICATION_GITHUB      WebhookVerificationType = 1
+	WebhookVerificationType_WEBHOOK_VERIFICATION_GITLAB      WebhookVerificationType = 2
+	WebhookVerificationType_WEBHOOK_VERIFICATION_BITBUCKET   WebhookVerificationType = 3
+	WebhookVerificationType_WEBHOOK_VERIFICATION_SLACK       WebhookVerificationType = 4
+	WebhookVerificationType_WEBHOOK_VERIFICATION_HMAC_SHA256 WebhookVerificationType = 5
+)
+
+// Enum value maps for WebhookVerificationType.
+var (
+	WebhookVerificationType_name = map[int32]string{
+		0: "WEBHOOK_VERIFICATION_NONE",
+		1: "WEBHOOK_VERIFICATION_GITHUB",
+		2: "WEBHOOK_VERIFICATION_GITLAB",
+		3: "WEBHOOK_VERIFICATION_BITBUCKET",
+		4: "WEBHOOK_VERIFICATION_SLACK",
+		5: "WEBHOOK_VERIFICATION_HMAC_SHA256",
+	}
+	WebhookVerificationType_value = map[string]int32{
+		"WEBHOOK_VERIFICATION_NONE":        0,
+		"WEBHOOK_VERIFICATION_GITHUB":      1,
+		"WEBHOOK_VERIFICATION_GITLAB":      2,
+		"WEBHOOK_VERIFICATION_BITBUCKET":   3,
+		"WEBHOOK_VERIFICATION_SLACK":       4,
+		"WEBHOOK_VERIFICATION_HMAC_SHA256": 5,
+	}
+)
+
+func (x WebhookVerificationType) Enum() *WebhookVerificationType {
+	p := new(WebhookVerificationType)
+	*p = x
+	return p
+}
+
+func (x WebhookVerificationType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WebhookVerificationType) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[4].Descriptor()
+}
+
+func (WebhookVerificationType) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[4]
+}
+
+func (x WebhookVerificationType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WebhookVerificationType.Descriptor instead.
+func (WebhookVerificationType) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{4}
+}
+
+type WebhookFilterSource int32
+
+const (
+	WebhookFilterSource_WEBHOOK_FILTER_HEADER    WebhookFilterSource = 0
+	WebhookFilterSource_WEBHOOK_FILTER_JSON_BODY WebhookFilterSource = 1
+	WebhookFilterSource_WEBHOOK_FILTER_METHOD    WebhookFilterSource = 2
+)
+
+// Enum value maps for WebhookFilterSource.
+var (
+	WebhookFilterSource_name = map[int32]string{
+		0: "WEBHOOK_FILTER_HEADER",
+		1: "WEBHOOK_FILTER_JSON_BODY",
+		2: "WEBHOOK_FILTER_METHOD",
+	}
+	WebhookFilterSource_value = map[string]int32{
+		"WEBHOOK_FILTER_HEADER":    0,
+		"WEBHOOK_FILTER_JSON_BODY": 1,
+		"WEBHOOK_FILTER_METHOD":    2,
+	}
+)
+
+func (x WebhookFilterSource) Enum() *WebhookFilterSource {
+	p := new(WebhookFilterSource)
+	*p = x
+	return p
+}
+
+func (x WebhookFilterSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WebhookFilterSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[5].Descriptor()
+}
+
+func (WebhookFilterSource) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[5]
+}
+
+func (x WebhookFilterSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WebhookFilterSource.Descriptor instead.
+func (WebhookFilterSource) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{5}
+}
+
+type HandlerRunResult int32
+
+const (
+	HandlerRunResult_HANDLER_RUN_RESULT_NONE    HandlerRunResult = 0
+	HandlerRunResult_HANDLER_RUN_RESULT_SUCCESS HandlerRunResult = 1
+	HandlerRunResult_HANDLER_RUN_RESULT_ERROR   HandlerRunResult = 2
+)
+
+// Enum value maps for HandlerRunResult.
+var (
+	HandlerRunResult_name = map[int32]string{
+		0: "HANDLER_RUN_RESULT_NONE",
+		1: "HANDLER_RUN_RESULT_SUCCESS",
+		2: "HANDLER_RUN_RESULT_ERROR",
+	}
+	HandlerRunResult_value = map[string]int32{
+		"HANDLER_RUN_RESULT_NONE":    0,
+		"HANDLER_RUN_RESULT_SUCCESS": 1,
+		"HANDLER_RUN_RESULT_ERROR":   2,
+	}
+)
+
+func (x HandlerRunResult) Enum() *HandlerRunResult {
+	p := new(HandlerRunResult)
+	*p = x
+	return p
+}
+
+func (x HandlerRunResult) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HandlerRunResult) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[6].Descriptor()
+}
+
+func (HandlerRunResult) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[6]
+}
+
+func (x HandlerRunResult) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HandlerRunResult.Descriptor instead.
+func (HandlerRunResult) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{6}
+}
+
+type InvocationState int32
+
+const (
+	InvocationState_INVOCATION_STATE_QUEUED    InvocationState = 0
+	InvocationState_INVOCATION_STATE_IN_FLIGHT InvocationState = 1
+	InvocationState_INVOCATION_STATE_COMPLETED InvocationState = 2
+)
+
+// Enum value maps for InvocationState.
+var (
+	InvocationState_name = map[int32]string{
+		0: "INVOCATION_STATE_QUEUED",
+		1: "INVOCATION_STATE_IN_FLIGHT",
+		2: "INVOCATION_STATE_COMPLETED",
+	}
+	InvocationState_value = map[string]int32{
+		"INVOCATION_STATE_QUEUED":    0,
+		"INVOCATION_STATE_IN_FLIGHT": 1,
+		"INVOCATION_STATE_COMPLETED": 2,
+	}
+)
+
+func (x InvocationState) Enum() *InvocationState {
+	p := new(InvocationState)
+	*p = x
+	return p
+}
+
+func (x InvocationState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InvocationState) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[7].Descriptor()
+}
+
+func (InvocationState) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[7]
+}
+
+func (x InvocationState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InvocationState.Descriptor instead.
+func (InvocationState) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{7}
 }
 
 type DispatchMessageType int32
@@ -83,6 +441,9 @@ const (
 	DispatchMessageType_DISPATCH_MESSAGE_TYPE_NONE      DispatchMessageType = 0
 	DispatchMessageType_DISPATCH_MESSAGE_INVOKE         DispatchMessageType = 1
 	DispatchMessageType_DISPATCH_MESSAGE_WORK_COMPLETED DispatchMessageType = 2
+	// DISPATCH_MESSAGE_CANCEL tells the client to stop running an invocation,
+	// whose result the agent no longer waits for
+	DispatchMessageType_DISPATCH_MESSAGE_CANCEL DispatchMessageType = 3
 )
 
 // Enum value maps for DispatchMessageType.
@@ -91,11 +452,13 @@ var (
 		0: "DISPATCH_MESSAGE_TYPE_NONE",
 		1: "DISPATCH_MESSAGE_INVOKE",
 		2: "DISPATCH_MESSAGE_WORK_COMPLETED",
+		3: "DISPATCH_MESSAGE_CANCEL",
 	}
 	DispatchMessageType_value = map[string]int32{
 		"DISPATCH_MESSAGE_TYPE_NONE":      0,
 		"DISPATCH_MESSAGE_INVOKE":         1,
 		"DISPATCH_MESSAGE_WORK_COMPLETED": 2,
+		"DISPATCH_MESSAGE_CANCEL":         3,
 	}
 )
 
@@ -110,11 +473,11 @@ func (x DispatchMessageType) String() string {
 }
 
 func (DispatchMessageType) Descriptor() protoreflect.EnumDescriptor {
-	return file_cortex_axon_agent_proto_enumTypes[1].Descriptor()
+	return file_cortex_axon_agent_proto_enumTypes[8].Descriptor()
 }
 
 func (DispatchMessageType) Type() protoreflect.EnumType {
-	return &file_cortex_axon_agent_proto_enumTypes[1]
+	return &file_cortex_axon_agent_proto_enumTypes[8]
 }
 
 func (x DispatchMessageType) Number() protoreflect.EnumNumber {
@@ -123,15 +486,138 @@ func (x DispatchMessageType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use DispatchMessageType.Descriptor instead.
 func (DispatchMessageType) EnumDescriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{1}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{8}
+}
+
+type CancelReason int32
+
+const (
+	CancelReason_CANCEL_REASON_NONE      CancelReason = 0
+	CancelReason_CANCEL_REASON_TIMEOUT   CancelReason = 1
+	CancelReason_CANCEL_REASON_REQUESTED CancelReason = 2
+	CancelReason_CANCEL_REASON_SHUTDOWN  CancelReason = 3
+)
+
+// Enum value maps for CancelReason.
+var (
+	CancelReason_name = map[int32]string{
+		0: "CANCEL_REASON_NONE",
+		1: "CANCEL_REASON_TIMEOUT",
+		2: "CANCEL_REASON_REQUESTED",
+		3: "CANCEL_REASON_SHUTDOWN",
+	}
+	CancelReason_value = map[string]int32{
+		"CANCEL_REASON_NONE":      0,
+		"CANCEL_REASON_TIMEOUT":   1,
+		"CANCEL_REASON_REQUESTED": 2,
+		"CANCEL_REASON_SHUTDOWN":  3,
+	}
+)
+
+func (x CancelReason) Enum() *CancelReason {
+	p := new(CancelReason)
+	*p = x
+	return p
+}
+
+func (x CancelReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CancelReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[9].Descriptor()
+}
+
+func (CancelReason) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[9]
+}
+
+func (x CancelReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CancelReason.Descriptor instead.
+func (CancelReason) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{9}
+}
+
+type HandlerExecutionEventType int32
+
+const (
+	HandlerExecutionEventType_EXECUTION_EVENT_NONE HandlerExecutionEventType = 0
+	// EXECUTION_EVENT_QUEUED is sent when an invocation is queued for dispatch
+	HandlerExecutionEventType_EXECUTION_EVENT_QUEUED HandlerExecutionEventType = 1
+	// EXECUTION_EVENT_DISPATCHED is sent when an attempt is sent to the client
+	// named by dispatch_id
+	HandlerExecutionEventType_EXECUTION_EVENT_DISPATCHED HandlerExecutionEventType = 2
+	// EXECUTION_EVENT_LOG is sent for each log line an attempt reports, before
+	// its completion event
+	HandlerExecutionEventType_EXECUTION_EVENT_LOG HandlerExecutionEventType = 3
+	// EXECUTION_EVENT_COMPLETED, EXECUTION_EVENT_TIMED_OUT and
+	// EXECUTION_EVENT_CANCELLED are sent with the execution recorded in history
+	HandlerExecutionEventType_EXECUTION_EVENT_COMPLETED HandlerExecutionEventType = 4
+	HandlerExecutionEventType_EXECUTION_EVENT_TIMED_OUT HandlerExecutionEventType = 5
+	HandlerExecutionEventType_EXECUTION_EVENT_CANCELLED HandlerExecutionEventType = 6
+)
+
+// Enum value maps for HandlerExecutionEventType.
+var (
+	HandlerExecutionEventType_name = map[int32]string{
+		0: "EXECUTION_EVENT_NONE",
+		1: "EXECUTION_EVENT_QUEUED",
+		2: "EXECUTION_EVENT_DISPATCHED",
+		3: "EXECUTION_EVENT_LOG",
+		4: "EXECUTION_EVENT_COMPLETED",
+		5: "EXECUTION_EVENT_TIMED_OUT",
+		6: "EXECUTION_EVENT_CANCELLED",
+	}
+	HandlerExecutionEventType_value = map[string]int32{
+		"EXECUTION_EVENT_NONE":       0,
+		"EXECUTION_EVENT_QUEUED":     1,
+		"EXECUTION_EVENT_DISPATCHED": 2,
+		"EXECUTION_EVENT_LOG":        3,
+		"EXECUTION_EVENT_COMPLETED":  4,
+		"EXECUTION_EVENT_TIMED_OUT":  5,
+		"EXECUTION_EVENT_CANCELLED":  6,
+	}
+)
+
+func (x HandlerExecutionEventType) Enum() *HandlerExecutionEventType {
+	p := new(HandlerExecutionEventType)
+	*p = x
+	return p
+}
+
+func (x HandlerExecutionEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HandlerExecutionEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_cortex_axon_agent_proto_enumTypes[10].Descriptor()
+}
+
+func (HandlerExecutionEventType) Type() protoreflect.EnumType {
+	return &file_cortex_axon_agent_proto_enumTypes[10]
+}
+
+func (x HandlerExecutionEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HandlerExecutionEventType.Descriptor instead.
+func (HandlerExecutionEventType) EnumDescriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{10}
 }
 
 type RegisterHandlerRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	DispatchId    string                 `protobuf:"bytes,1,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
-	HandlerName   string                 `protobuf:"bytes,2,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
-	TimeoutMs     int32                  `protobuf:"varint,3,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
-	Options       []*HandlerOption       `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	DispatchId  string                 `protobuf:"bytes,1,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	HandlerName string                 `protobuf:"bytes,2,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	TimeoutMs   int32                  `protobuf:"varint,3,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	Options     []*HandlerOption       `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty"`
+	// worker_pool joins the client to a pool of clients that share the
+	// handlers registered in it, each invocation going to one of them
+	WorkerPool    *WorkerPool `protobuf:"bytes,5,opt,name=worker_pool,json=workerPool,proto3" json:"worker_pool,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -194,17 +680,76 @@ func (x *RegisterHandlerRequest) GetOptions() []*HandlerOption {
 	return nil
 }
 
-type HandlerInvokeOption struct {
+func (x *RegisterHandlerRequest) GetWorkerPool() *WorkerPool {
+	if x != nil {
+		return x.WorkerPool
+	}
+	return nil
+}
+
+type WorkerPool struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          HandlerInvokeType      `protobuf:"varint,1,opt,name=type,proto3,enum=cortex.axon.HandlerInvokeType" json:"type,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Strategy      WorkerPoolStrategy     `protobuf:"varint,2,opt,name=strategy,proto3,enum=cortex.axon.WorkerPoolStrategy" json:"strategy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerPool) Reset() {
+	*x = WorkerPool{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerPool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerPool) ProtoMessage() {}
+
+func (x *WorkerPool) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerPool.ProtoReflect.Descriptor instead.
+func (*WorkerPool) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WorkerPool) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WorkerPool) GetStrategy() WorkerPoolStrategy {
+	if x != nil {
+		return x.Strategy
+	}
+	return WorkerPoolStrategy_WORKER_POOL_ROUND_ROBIN
+}
+
+type HandlerInvokeOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          HandlerInvokeType      `protobuf:"varint,1,opt,name=type,proto3,enum=cortex.axon.HandlerInvokeType" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HandlerInvokeOption) Reset() {
 	*x = HandlerInvokeOption{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[1]
+	mi := &file_cortex_axon_agent_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -216,7 +761,7 @@ func (x *HandlerInvokeOption) String() string {
 func (*HandlerInvokeOption) ProtoMessage() {}
 
 func (x *HandlerInvokeOption) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[1]
+	mi := &file_cortex_axon_agent_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -229,7 +774,7 @@ func (x *HandlerInvokeOption) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HandlerInvokeOption.ProtoReflect.Descriptor instead.
 func (*HandlerInvokeOption) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{1}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *HandlerInvokeOption) GetType() HandlerInvokeType {
@@ -246,31 +791,123 @@ func (x *HandlerInvokeOption) GetValue() string {
 	return ""
 }
 
-type HandlerOption struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Option:
-	//
-	//	*HandlerOption_Invoke
-	Option        isHandlerOption_Option `protobuf_oneof:"option"`
+// HandlerRetryOption re-dispatches a failed invocation until it succeeds or
+// max_attempts (including the first) have been made. Backoff between attempts
+// grows by backoff_multiplier from initial_backoff_ms up to max_backoff_ms, and
+// each delay is reduced by a random fraction of up to jitter (0-1). If retry_on
+// is set, only errors with one of those codes (e.g. "timeout", "unexpected") are
+// retried.
+type HandlerRetryOption struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	MaxAttempts       int32                  `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	InitialBackoffMs  int32                  `protobuf:"varint,2,opt,name=initial_backoff_ms,json=initialBackoffMs,proto3" json:"initial_backoff_ms,omitempty"`
+	MaxBackoffMs      int32                  `protobuf:"varint,3,opt,name=max_backoff_ms,json=maxBackoffMs,proto3" json:"max_backoff_ms,omitempty"`
+	BackoffMultiplier float64                `protobuf:"fixed64,4,opt,name=backoff_multiplier,json=backoffMultiplier,proto3" json:"backoff_multiplier,omitempty"`
+	Jitter            float64                `protobuf:"fixed64,5,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	RetryOn           []string               `protobuf:"bytes,6,rep,name=retry_on,json=retryOn,proto3" json:"retry_on,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HandlerRetryOption) Reset() {
+	*x = HandlerRetryOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerRetryOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerRetryOption) ProtoMessage() {}
+
+func (x *HandlerRetryOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerRetryOption.ProtoReflect.Descriptor instead.
+func (*HandlerRetryOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HandlerRetryOption) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *HandlerRetryOption) GetInitialBackoffMs() int32 {
+	if x != nil {
+		return x.InitialBackoffMs
+	}
+	return 0
+}
+
+func (x *HandlerRetryOption) GetMaxBackoffMs() int32 {
+	if x != nil {
+		return x.MaxBackoffMs
+	}
+	return 0
+}
+
+func (x *HandlerRetryOption) GetBackoffMultiplier() float64 {
+	if x != nil {
+		return x.BackoffMultiplier
+	}
+	return 0
+}
+
+func (x *HandlerRetryOption) GetJitter() float64 {
+	if x != nil {
+		return x.Jitter
+	}
+	return 0
+}
+
+func (x *HandlerRetryOption) GetRetryOn() []string {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+// HandlerConcurrencyOption limits how many invocations of a handler run at
+// once. A trigger that arrives while max_concurrent invocations are running is
+// skipped, or with OVERFLOW_QUEUE_ONE held until one finishes; only one is held
+// at a time and any further triggers are skipped.
+type HandlerConcurrencyOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxConcurrent int32                  `protobuf:"varint,1,opt,name=max_concurrent,json=maxConcurrent,proto3" json:"max_concurrent,omitempty"`
+	Overflow      HandlerOverflowPolicy  `protobuf:"varint,2,opt,name=overflow,proto3,enum=cortex.axon.HandlerOverflowPolicy" json:"overflow,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HandlerOption) Reset() {
-	*x = HandlerOption{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[2]
+func (x *HandlerConcurrencyOption) Reset() {
+	*x = HandlerConcurrencyOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HandlerOption) String() string {
+func (x *HandlerConcurrencyOption) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HandlerOption) ProtoMessage() {}
+func (*HandlerConcurrencyOption) ProtoMessage() {}
 
-func (x *HandlerOption) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[2]
+func (x *HandlerConcurrencyOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -281,60 +918,1417 @@ func (x *HandlerOption) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HandlerOption.ProtoReflect.Descriptor instead.
-func (*HandlerOption) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use HandlerConcurrencyOption.ProtoReflect.Descriptor instead.
+func (*HandlerConcurrencyOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HandlerConcurrencyOption) GetMaxConcurrent() int32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
+func (x *HandlerConcurrencyOption) GetOverflow() HandlerOverflowPolicy {
+	if x != nil {
+		return x.Overflow
+	}
+	return HandlerOverflowPolicy_OVERFLOW_SKIP
+}
+
+// HandlerScheduleOption adjusts when a CRON_SCHEDULE or RUN_INTERVAL handler
+// fires. timezone is an IANA zone name such as "America/New_York" that cron
+// specs without a CRON_TZ= prefix are evaluated in, and each trigger is
+// delayed by a random amount up to jitter_ms so that many agents on the same
+// schedule don't all fire at once.
+//
+// misfire_policy decides what happens to cron times that passed since the
+// handler's last recorded execution, e.g. while the agent was down: they are
+// skipped, run once, or each run, up to max_catch_up_runs (default 10) of
+// the most recent. Catch-up runs are triggered with reason CATCH_UP.
+type HandlerScheduleOption struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Timezone       string                 `protobuf:"bytes,1,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	JitterMs       int32                  `protobuf:"varint,2,opt,name=jitter_ms,json=jitterMs,proto3" json:"jitter_ms,omitempty"`
+	MisfirePolicy  HandlerMisfirePolicy   `protobuf:"varint,3,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=cortex.axon.HandlerMisfirePolicy" json:"misfire_policy,omitempty"`
+	MaxCatchUpRuns int32                  `protobuf:"varint,4,opt,name=max_catch_up_runs,json=maxCatchUpRuns,proto3" json:"max_catch_up_runs,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *HandlerScheduleOption) Reset() {
+	*x = HandlerScheduleOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerScheduleOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerScheduleOption) ProtoMessage() {}
+
+func (x *HandlerScheduleOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerScheduleOption.ProtoReflect.Descriptor instead.
+func (*HandlerScheduleOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HandlerScheduleOption) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *HandlerScheduleOption) GetJitterMs() int32 {
+	if x != nil {
+		return x.JitterMs
+	}
+	return 0
+}
+
+func (x *HandlerScheduleOption) GetMisfirePolicy() HandlerMisfirePolicy {
+	if x != nil {
+		return x.MisfirePolicy
+	}
+	return HandlerMisfirePolicy_MISFIRE_SKIP
+}
+
+func (x *HandlerScheduleOption) GetMaxCatchUpRuns() int32 {
+	if x != nil {
+		return x.MaxCatchUpRuns
+	}
+	return 0
+}
+
+// WebhookVerification authenticates webhook requests with a shared secret,
+// taken from the environment variable secret_env or the output of the plugin
+// secret_plugin, found in the agent's plugin directories.
+//
+// GITHUB and BITBUCKET check the hex HMAC-SHA256 of the body in
+// X-Hub-Signature-256 and X-Hub-Signature, GITLAB compares X-Gitlab-Token to
+// the secret, and SLACK checks X-Slack-Signature and rejects an
+// X-Slack-Request-Timestamp more than timestamp_tolerance_seconds (default
+// 300) away. HMAC_SHA256 checks the hex HMAC-SHA256 of the body in header
+// (default X-Signature) after removing prefix. header overrides the header
+// read for any type.
+type WebhookVerification struct {
+	state                     protoimpl.MessageState  `protogen:"open.v1"`
+	Type                      WebhookVerificationType `protobuf:"varint,1,opt,name=type,proto3,enum=cortex.axon.WebhookVerificationType" json:"type,omitempty"`
+	SecretEnv                 string                  `protobuf:"bytes,2,opt,name=secret_env,json=secretEnv,proto3" json:"secret_env,omitempty"`
+	SecretPlugin              string                  `protobuf:"bytes,3,opt,name=secret_plugin,json=secretPlugin,proto3" json:"secret_plugin,omitempty"`
+	Header                    string                  `protobuf:"bytes,4,opt,name=header,proto3" json:"header,omitempty"`
+	Prefix                    string                  `protobuf:"bytes,5,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	TimestampToleranceSeconds int32                   `protobuf:"varint,6,opt,name=timestamp_tolerance_seconds,json=timestampToleranceSeconds,proto3" json:"timestamp_tolerance_seconds,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *WebhookVerification) Reset() {
+	*x = WebhookVerification{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookVerification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookVerification) ProtoMessage() {}
+
+func (x *WebhookVerification) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookVerification.ProtoReflect.Descriptor instead.
+func (*WebhookVerification) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WebhookVerification) GetType() WebhookVerificationType {
+	if x != nil {
+		return x.Type
+	}
+	return WebhookVerificationType_WEBHOOK_VERIFICATION_NONE
+}
+
+func (x *WebhookVerification) GetSecretEnv() string {
+	if x != nil {
+		return x.SecretEnv
+	}
+	return ""
+}
+
+func (x *WebhookVerification) GetSecretPlugin() string {
+	if x != nil {
+		return x.SecretPlugin
+	}
+	return ""
+}
+
+func (x *WebhookVerification) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *WebhookVerification) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *WebhookVerification) GetTimestampToleranceSeconds() int32 {
+	if x != nil {
+		return x.TimestampToleranceSeconds
+	}
+	return 0
+}
+
+// WebhookFilter matches a webhook request whose value at key in source is
+// one of values, or with no values, is present at all. For JSON_BODY, key is
+// a dot-separated path into the body such as "pull_request.base.ref", with
+// array elements addressed by index. negate inverts the match.
+type WebhookFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        WebhookFilterSource    `protobuf:"varint,1,opt,name=source,proto3,enum=cortex.axon.WebhookFilterSource" json:"source,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Values        []string               `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	Negate        bool                   `protobuf:"varint,4,opt,name=negate,proto3" json:"negate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookFilter) Reset() {
+	*x = WebhookFilter{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookFilter) ProtoMessage() {}
+
+func (x *WebhookFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookFilter.ProtoReflect.Descriptor instead.
+func (*WebhookFilter) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WebhookFilter) GetSource() WebhookFilterSource {
+	if x != nil {
+		return x.Source
+	}
+	return WebhookFilterSource_WEBHOOK_FILTER_HEADER
+}
+
+func (x *WebhookFilter) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WebhookFilter) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *WebhookFilter) GetNegate() bool {
+	if x != nil {
+		return x.Negate
+	}
+	return false
+}
+
+// WebhookDedup suppresses repeated deliveries of the same request within
+// window_seconds (default 3600). Requests are identified by the value of
+// header, such as X-GitHub-Delivery, or if hash_body is set and the header
+// is missing, by a hash of the body.
+type WebhookDedup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        string                 `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	HashBody      bool                   `protobuf:"varint,2,opt,name=hash_body,json=hashBody,proto3" json:"hash_body,omitempty"`
+	WindowSeconds int32                  `protobuf:"varint,3,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDedup) Reset() {
+	*x = WebhookDedup{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDedup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDedup) ProtoMessage() {}
+
+func (x *WebhookDedup) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDedup.ProtoReflect.Descriptor instead.
+func (*WebhookDedup) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WebhookDedup) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *WebhookDedup) GetHashBody() bool {
+	if x != nil {
+		return x.HashBody
+	}
+	return false
+}
+
+func (x *WebhookDedup) GetWindowSeconds() int32 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+// HandlerWebhookOption configures how requests to a webhook handler are
+// accepted. A handler may carry several, which are merged.
+//
+// A synchronous webhook holds the request open until the handler finishes,
+// up to response_timeout_ms (default the handler timeout), and answers with
+// its result: a WebhookResponse in JSON, or else the result as the body.
+//
+// The values of redact_headers, and of the agent's WEBHOOK_REDACT_HEADERS, are
+// replaced before the request headers are passed to the handler.
+//
+// A request only triggers the handler if it matches all of filters. Several
+// handlers may share a webhook id, each with its own filters, and every
+// handler that matches is triggered. If more than one of them is synchronous
+// the first by name answers the request.
+type HandlerWebhookOption struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Verification      *WebhookVerification   `protobuf:"bytes,1,opt,name=verification,proto3" json:"verification,omitempty"`
+	Synchronous       bool                   `protobuf:"varint,2,opt,name=synchronous,proto3" json:"synchronous,omitempty"`
+	ResponseTimeoutMs int32                  `protobuf:"varint,3,opt,name=response_timeout_ms,json=responseTimeoutMs,proto3" json:"response_timeout_ms,omitempty"`
+	RedactHeaders     []string               `protobuf:"bytes,4,rep,name=redact_headers,json=redactHeaders,proto3" json:"redact_headers,omitempty"`
+	Filters           []*WebhookFilter       `protobuf:"bytes,5,rep,name=filters,proto3" json:"filters,omitempty"`
+	Dedup             *WebhookDedup          `protobuf:"bytes,6,opt,name=dedup,proto3" json:"dedup,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HandlerWebhookOption) Reset() {
+	*x = HandlerWebhookOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerWebhookOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerWebhookOption) ProtoMessage() {}
+
+func (x *HandlerWebhookOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerWebhookOption.ProtoReflect.Descriptor instead.
+func (*HandlerWebhookOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *HandlerWebhookOption) GetVerification() *WebhookVerification {
+	if x != nil {
+		return x.Verification
+	}
+	return nil
+}
+
+func (x *HandlerWebhookOption) GetSynchronous() bool {
+	if x != nil {
+		return x.Synchronous
+	}
+	return false
+}
+
+func (x *HandlerWebhookOption) GetResponseTimeoutMs() int32 {
+	if x != nil {
+		return x.ResponseTimeoutMs
+	}
+	return 0
+}
+
+func (x *HandlerWebhookOption) GetRedactHeaders() []string {
+	if x != nil {
+		return x.RedactHeaders
+	}
+	return nil
+}
+
+func (x *HandlerWebhookOption) GetFilters() []*WebhookFilter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *HandlerWebhookOption) GetDedup() *WebhookDedup {
+	if x != nil {
+		return x.Dedup
+	}
+	return nil
+}
+
+// WebhookResponse is the HTTP response a synchronous webhook handler returns
+// to its caller. status_code defaults to 200.
+type WebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StatusCode    int32                  `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookResponse) Reset() {
+	*x = WebhookResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookResponse) ProtoMessage() {}
+
+func (x *WebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookResponse.ProtoReflect.Descriptor instead.
+func (*WebhookResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WebhookResponse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *WebhookResponse) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *WebhookResponse) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+// HandlerDependencyOption runs the handler, with reason UPSTREAM, each time
+// the handler named handler_name completes successfully. The upstream
+// handler's name and invocation id are passed in the upstream-handler and
+// upstream-invocation-id args, and with pass_result its result is passed in
+// the upstream-result arg. Registering a dependency that would form a cycle
+// fails.
+type HandlerDependencyOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	PassResult    bool                   `protobuf:"varint,2,opt,name=pass_result,json=passResult,proto3" json:"pass_result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandlerDependencyOption) Reset() {
+	*x = HandlerDependencyOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerDependencyOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerDependencyOption) ProtoMessage() {}
+
+func (x *HandlerDependencyOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerDependencyOption.ProtoReflect.Descriptor instead.
+func (*HandlerDependencyOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HandlerDependencyOption) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
+}
+
+func (x *HandlerDependencyOption) GetPassResult() bool {
+	if x != nil {
+		return x.PassResult
+	}
+	return false
+}
+
+// HandlerRetentionOption limits how much of the handler's history the agent
+// keeps: executions older than max_age_ms, beyond the max_count most recent,
+// or beyond the most recent that fit in max_bytes are removed. The min_count
+// most recent are always kept. Unset limits fall back to the agent's, and the
+// agent's HANDLER_HISTORY_RETENTION overrides them.
+type HandlerRetentionOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxAgeMs      int64                  `protobuf:"varint,1,opt,name=max_age_ms,json=maxAgeMs,proto3" json:"max_age_ms,omitempty"`
+	MaxCount      int32                  `protobuf:"varint,2,opt,name=max_count,json=maxCount,proto3" json:"max_count,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MinCount      int32                  `protobuf:"varint,4,opt,name=min_count,json=minCount,proto3" json:"min_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandlerRetentionOption) Reset() {
+	*x = HandlerRetentionOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerRetentionOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerRetentionOption) ProtoMessage() {}
+
+func (x *HandlerRetentionOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerRetentionOption.ProtoReflect.Descriptor instead.
+func (*HandlerRetentionOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *HandlerRetentionOption) GetMaxAgeMs() int64 {
+	if x != nil {
+		return x.MaxAgeMs
+	}
+	return 0
+}
+
+func (x *HandlerRetentionOption) GetMaxCount() int32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *HandlerRetentionOption) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *HandlerRetentionOption) GetMinCount() int32 {
+	if x != nil {
+		return x.MinCount
+	}
+	return 0
+}
+
+type HandlerOption struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Option:
+	//
+	//	*HandlerOption_Invoke
+	//	*HandlerOption_Retry
+	//	*HandlerOption_Concurrency
+	//	*HandlerOption_Webhook
+	//	*HandlerOption_Schedule
+	//	*HandlerOption_Dependency
+	//	*HandlerOption_Retention
+	Option        isHandlerOption_Option `protobuf_oneof:"option"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandlerOption) Reset() {
+	*x = HandlerOption{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerOption) ProtoMessage() {}
+
+func (x *HandlerOption) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerOption.ProtoReflect.Descriptor instead.
+func (*HandlerOption) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *HandlerOption) GetOption() isHandlerOption_Option {
+	if x != nil {
+		return x.Option
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetInvoke() *HandlerInvokeOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Invoke); ok {
+			return x.Invoke
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetRetry() *HandlerRetryOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Retry); ok {
+			return x.Retry
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetConcurrency() *HandlerConcurrencyOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Concurrency); ok {
+			return x.Concurrency
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetWebhook() *HandlerWebhookOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Webhook); ok {
+			return x.Webhook
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetSchedule() *HandlerScheduleOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Schedule); ok {
+			return x.Schedule
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetDependency() *HandlerDependencyOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Dependency); ok {
+			return x.Dependency
+		}
+	}
+	return nil
+}
+
+func (x *HandlerOption) GetRetention() *HandlerRetentionOption {
+	if x != nil {
+		if x, ok := x.Option.(*HandlerOption_Retention); ok {
+			return x.Retention
+		}
+	}
+	return nil
+}
+
+type isHandlerOption_Option interface {
+	isHandlerOption_Option()
+}
+
+type HandlerOption_Invoke struct {
+	Invoke *HandlerInvokeOption `protobuf:"bytes,1,opt,name=invoke,proto3,oneof"`
+}
+
+type HandlerOption_Retry struct {
+	Retry *HandlerRetryOption `protobuf:"bytes,2,opt,name=retry,proto3,oneof"`
+}
+
+type HandlerOption_Concurrency struct {
+	Concurrency *HandlerConcurrencyOption `protobuf:"bytes,3,opt,name=concurrency,proto3,oneof"`
+}
+
+type HandlerOption_Webhook struct {
+	Webhook *HandlerWebhookOption `protobuf:"bytes,4,opt,name=webhook,proto3,oneof"`
+}
+
+type HandlerOption_Schedule struct {
+	Schedule *HandlerScheduleOption `protobuf:"bytes,5,opt,name=schedule,proto3,oneof"`
+}
+
+type HandlerOption_Dependency struct {
+	Dependency *HandlerDependencyOption `protobuf:"bytes,6,opt,name=dependency,proto3,oneof"`
+}
+
+type HandlerOption_Retention struct {
+	Retention *HandlerRetentionOption `protobuf:"bytes,7,opt,name=retention,proto3,oneof"`
+}
+
+func (*HandlerOption_Invoke) isHandlerOption_Option() {}
+
+func (*HandlerOption_Retry) isHandlerOption_Option() {}
+
+func (*HandlerOption_Concurrency) isHandlerOption_Option() {}
+
+func (*HandlerOption_Webhook) isHandlerOption_Option() {}
+
+func (*HandlerOption_Schedule) isHandlerOption_Option() {}
+
+func (*HandlerOption_Dependency) isHandlerOption_Option() {}
+
+func (*HandlerOption_Retention) isHandlerOption_Option() {}
+
+type RegisterHandlerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterHandlerResponse) Reset() {
+	*x = RegisterHandlerResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterHandlerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterHandlerResponse) ProtoMessage() {}
+
+func (x *RegisterHandlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterHandlerResponse.ProtoReflect.Descriptor instead.
+func (*RegisterHandlerResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RegisterHandlerResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *RegisterHandlerResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnregisterHandlerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterHandlerRequest) Reset() {
+	*x = UnregisterHandlerRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterHandlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterHandlerRequest) ProtoMessage() {}
+
+func (x *UnregisterHandlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterHandlerRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterHandlerRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UnregisterHandlerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnregisterHandlerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterHandlerResponse) Reset() {
+	*x = UnregisterHandlerResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterHandlerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterHandlerResponse) ProtoMessage() {}
+
+func (x *UnregisterHandlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterHandlerResponse.ProtoReflect.Descriptor instead.
+func (*UnregisterHandlerResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UnregisterHandlerResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ListHandlersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHandlersRequest) Reset() {
+	*x = ListHandlersRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHandlersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHandlersRequest) ProtoMessage() {}
+
+func (x *ListHandlersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHandlersRequest.ProtoReflect.Descriptor instead.
+func (*ListHandlersRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{17}
+}
+
+// HandlerInfo describes a registered handler. next_run_timestamp is when a
+// scheduled handler next runs, unset while it is paused or stopped, and
+// interval_ms is the interval of a RUN_INTERVAL handler. The last_* fields and
+// consecutive_failures describe the runs recorded in the handler's history,
+// ignoring runs skipped by a concurrency limit. Only the most recent runs are
+// read, so consecutive_failures_at_least is set when every one of them failed
+// and there may be more failures before them.
+type HandlerInfo struct {
+	state                      protoimpl.MessageState `protogen:"open.v1"`
+	Name                       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Options                    []*HandlerOption       `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	DispatchId                 string                 `protobuf:"bytes,20,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	Id                         string                 `protobuf:"bytes,21,opt,name=id,proto3" json:"id,omitempty"`
+	LastInvokedClientTimestamp *timestamppb.Timestamp `protobuf:"bytes,100,opt,name=last_invoked_client_timestamp,json=lastInvokedClientTimestamp,proto3" json:"last_invoked_client_timestamp,omitempty"`
+	IsActive                   bool                   `protobuf:"varint,101,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsPaused                   bool                   `protobuf:"varint,102,opt,name=is_paused,json=isPaused,proto3" json:"is_paused,omitempty"`
+	NextRunTimestamp           *timestamppb.Timestamp `protobuf:"bytes,103,opt,name=next_run_timestamp,json=nextRunTimestamp,proto3" json:"next_run_timestamp,omitempty"`
+	IntervalMs                 int64                  `protobuf:"varint,104,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	LastResult                 HandlerRunResult       `protobuf:"varint,105,opt,name=last_result,json=lastResult,proto3,enum=cortex.axon.HandlerRunResult" json:"last_result,omitempty"`
+	LastDurationMs             int32                  `protobuf:"varint,106,opt,name=last_duration_ms,json=lastDurationMs,proto3" json:"last_duration_ms,omitempty"`
+	ConsecutiveFailures        int32                  `protobuf:"varint,107,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	LastError                  *Error                 `protobuf:"bytes,108,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	ConsecutiveFailuresAtLeast bool                   `protobuf:"varint,109,opt,name=consecutive_failures_at_least,json=consecutiveFailuresAtLeast,proto3" json:"consecutive_failures_at_least,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *HandlerInfo) Reset() {
+	*x = HandlerInfo{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerInfo) ProtoMessage() {}
+
+func (x *HandlerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerInfo.ProtoReflect.Descriptor instead.
+func (*HandlerInfo) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *HandlerInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HandlerInfo) GetOptions() []*HandlerOption {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *HandlerInfo) GetDispatchId() string {
+	if x != nil {
+		return x.DispatchId
+	}
+	return ""
+}
+
+func (x *HandlerInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *HandlerInfo) GetLastInvokedClientTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastInvokedClientTimestamp
+	}
+	return nil
+}
+
+func (x *HandlerInfo) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *HandlerInfo) GetIsPaused() bool {
+	if x != nil {
+		return x.IsPaused
+	}
+	return false
+}
+
+func (x *HandlerInfo) GetNextRunTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRunTimestamp
+	}
+	return nil
+}
+
+func (x *HandlerInfo) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *HandlerInfo) GetLastResult() HandlerRunResult {
+	if x != nil {
+		return x.LastResult
+	}
+	return HandlerRunResult_HANDLER_RUN_RESULT_NONE
+}
+
+func (x *HandlerInfo) GetLastDurationMs() int32 {
+	if x != nil {
+		return x.LastDurationMs
+	}
+	return 0
+}
+
+func (x *HandlerInfo) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *HandlerInfo) GetLastError() *Error {
+	if x != nil {
+		return x.LastError
+	}
+	return nil
+}
+
+func (x *HandlerInfo) GetConsecutiveFailuresAtLeast() bool {
+	if x != nil {
+		return x.ConsecutiveFailuresAtLeast
+	}
+	return false
+}
+
+type ListHandlersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Handlers      []*HandlerInfo         `protobuf:"bytes,2,rep,name=handlers,proto3" json:"handlers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHandlersResponse) Reset() {
+	*x = ListHandlersResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHandlersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHandlersResponse) ProtoMessage() {}
+
+func (x *ListHandlersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHandlersResponse.ProtoReflect.Descriptor instead.
+func (*ListHandlersResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListHandlersResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *ListHandlersResponse) GetHandlers() []*HandlerInfo {
+	if x != nil {
+		return x.Handlers
+	}
+	return nil
+}
+
+// PauseHandlerRequest stops a scheduled handler's RUN_INTERVAL and
+// CRON_SCHEDULE runs until it is resumed, without unregistering it.
+type PauseHandlerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseHandlerRequest) Reset() {
+	*x = PauseHandlerRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseHandlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseHandlerRequest) ProtoMessage() {}
+
+func (x *PauseHandlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseHandlerRequest.ProtoReflect.Descriptor instead.
+func (*PauseHandlerRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PauseHandlerRequest) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
+}
+
+type PauseHandlerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseHandlerResponse) Reset() {
+	*x = PauseHandlerResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseHandlerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseHandlerResponse) ProtoMessage() {}
+
+func (x *PauseHandlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseHandlerResponse.ProtoReflect.Descriptor instead.
+func (*PauseHandlerResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PauseHandlerResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ResumeHandlerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeHandlerRequest) Reset() {
+	*x = ResumeHandlerRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeHandlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeHandlerRequest) ProtoMessage() {}
+
+func (x *ResumeHandlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeHandlerRequest.ProtoReflect.Descriptor instead.
+func (*ResumeHandlerRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ResumeHandlerRequest) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
+}
+
+type ResumeHandlerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeHandlerResponse) Reset() {
+	*x = ResumeHandlerResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeHandlerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeHandlerResponse) ProtoMessage() {}
+
+func (x *ResumeHandlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeHandlerResponse.ProtoReflect.Descriptor instead.
+func (*ResumeHandlerResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ResumeHandlerResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// TriggerHandlerRequest queues a run of a scheduled handler now, with reason
+// RUN_NOW, whether or not it is paused.
+type TriggerHandlerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerHandlerRequest) Reset() {
+	*x = TriggerHandlerRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerHandlerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *HandlerOption) GetOption() isHandlerOption_Option {
-	if x != nil {
-		return x.Option
-	}
-	return nil
-}
+func (*TriggerHandlerRequest) ProtoMessage() {}
 
-func (x *HandlerOption) GetInvoke() *HandlerInvokeOption {
+func (x *TriggerHandlerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[24]
 	if x != nil {
-		if x, ok := x.Option.(*HandlerOption_Invoke); ok {
-			return x.Invoke
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type isHandlerOption_Option interface {
-	isHandlerOption_Option()
+// Deprecated: Use TriggerHandlerRequest.ProtoReflect.Descriptor instead.
+func (*TriggerHandlerRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{24}
 }
 
-type HandlerOption_Invoke struct {
-	Invoke *HandlerInvokeOption `protobuf:"bytes,1,opt,name=invoke,proto3,oneof"`
+func (x *TriggerHandlerRequest) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
 }
 
-func (*HandlerOption_Invoke) isHandlerOption_Option() {}
-
-type RegisterHandlerResponse struct {
+type TriggerHandlerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	InvocationId  string                 `protobuf:"bytes,2,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterHandlerResponse) Reset() {
-	*x = RegisterHandlerResponse{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[3]
+func (x *TriggerHandlerResponse) Reset() {
+	*x = TriggerHandlerResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterHandlerResponse) String() string {
+func (x *TriggerHandlerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterHandlerResponse) ProtoMessage() {}
+func (*TriggerHandlerResponse) ProtoMessage() {}
 
-func (x *RegisterHandlerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[3]
+func (x *TriggerHandlerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -345,47 +2339,50 @@ func (x *RegisterHandlerResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterHandlerResponse.ProtoReflect.Descriptor instead.
-func (*RegisterHandlerResponse) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use TriggerHandlerResponse.ProtoReflect.Descriptor instead.
+func (*TriggerHandlerResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *RegisterHandlerResponse) GetError() *Error {
+func (x *TriggerHandlerResponse) GetError() *Error {
 	if x != nil {
 		return x.Error
 	}
 	return nil
 }
 
-func (x *RegisterHandlerResponse) GetId() string {
+func (x *TriggerHandlerResponse) GetInvocationId() string {
 	if x != nil {
-		return x.Id
+		return x.InvocationId
 	}
 	return ""
 }
 
-type UnregisterHandlerRequest struct {
+// CancelInvocationRequest cancels a queued or running invocation, including
+// any retries of it. A running invocation's client is sent a
+// DISPATCH_MESSAGE_CANCEL.
+type CancelInvocationRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	InvocationId  string                 `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UnregisterHandlerRequest) Reset() {
-	*x = UnregisterHandlerRequest{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[4]
+func (x *CancelInvocationRequest) Reset() {
+	*x = CancelInvocationRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UnregisterHandlerRequest) String() string {
+func (x *CancelInvocationRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnregisterHandlerRequest) ProtoMessage() {}
+func (*CancelInvocationRequest) ProtoMessage() {}
 
-func (x *UnregisterHandlerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[4]
+func (x *CancelInvocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -396,40 +2393,40 @@ func (x *UnregisterHandlerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnregisterHandlerRequest.ProtoReflect.Descriptor instead.
-func (*UnregisterHandlerRequest) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use CancelInvocationRequest.ProtoReflect.Descriptor instead.
+func (*CancelInvocationRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *UnregisterHandlerRequest) GetId() string {
+func (x *CancelInvocationRequest) GetInvocationId() string {
 	if x != nil {
-		return x.Id
+		return x.InvocationId
 	}
 	return ""
 }
 
-type UnregisterHandlerResponse struct {
+type CancelInvocationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UnregisterHandlerResponse) Reset() {
-	*x = UnregisterHandlerResponse{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[5]
+func (x *CancelInvocationResponse) Reset() {
+	*x = CancelInvocationResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UnregisterHandlerResponse) String() string {
+func (x *CancelInvocationResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnregisterHandlerResponse) ProtoMessage() {}
+func (*CancelInvocationResponse) ProtoMessage() {}
 
-func (x *UnregisterHandlerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[5]
+func (x *CancelInvocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -440,39 +2437,43 @@ func (x *UnregisterHandlerResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnregisterHandlerResponse.ProtoReflect.Descriptor instead.
-func (*UnregisterHandlerResponse) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use CancelInvocationResponse.ProtoReflect.Descriptor instead.
+func (*CancelInvocationResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *UnregisterHandlerResponse) GetError() *Error {
+func (x *CancelInvocationResponse) GetError() *Error {
 	if x != nil {
 		return x.Error
 	}
 	return nil
 }
 
-type ListHandlersRequest struct {
+// ListInvocationsRequest lists the invocations waiting to be sent to a client,
+// those sent and not yet reported, and those that recently completed,
+// optionally only those of one handler.
+type ListInvocationsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListHandlersRequest) Reset() {
-	*x = ListHandlersRequest{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[6]
+func (x *ListInvocationsRequest) Reset() {
+	*x = ListInvocationsRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListHandlersRequest) String() string {
+func (x *ListInvocationsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListHandlersRequest) ProtoMessage() {}
+func (*ListInvocationsRequest) ProtoMessage() {}
 
-func (x *ListHandlersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[6]
+func (x *ListInvocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,38 +2484,57 @@ func (x *ListHandlersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListHandlersRequest.ProtoReflect.Descriptor instead.
-func (*ListHandlersRequest) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ListInvocationsRequest.ProtoReflect.Descriptor instead.
+func (*ListInvocationsRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{28}
 }
 
-type HandlerInfo struct {
-	state                      protoimpl.MessageState `protogen:"open.v1"`
-	Name                       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Options                    []*HandlerOption       `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
-	DispatchId                 string                 `protobuf:"bytes,20,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
-	Id                         string                 `protobuf:"bytes,21,opt,name=id,proto3" json:"id,omitempty"`
-	LastInvokedClientTimestamp *timestamppb.Timestamp `protobuf:"bytes,100,opt,name=last_invoked_client_timestamp,json=lastInvokedClientTimestamp,proto3" json:"last_invoked_client_timestamp,omitempty"`
-	IsActive                   bool                   `protobuf:"varint,101,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	unknownFields              protoimpl.UnknownFields
-	sizeCache                  protoimpl.SizeCache
+func (x *ListInvocationsRequest) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
 }
 
-func (x *HandlerInfo) Reset() {
-	*x = HandlerInfo{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[7]
+// InvocationInfo describes an invocation. dispatch_id is the client it was
+// sent to while in flight, and the handler's otherwise. elapsed_ms is how
+// long it has been queued or in flight, or how long it took to complete, and
+// args_size the total length of its arg names and values.
+type InvocationInfo struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	InvocationId         string                 `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
+	OriginalInvocationId string                 `protobuf:"bytes,2,opt,name=original_invocation_id,json=originalInvocationId,proto3" json:"original_invocation_id,omitempty"`
+	HandlerName          string                 `protobuf:"bytes,3,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	HandlerId            string                 `protobuf:"bytes,4,opt,name=handler_id,json=handlerId,proto3" json:"handler_id,omitempty"`
+	DispatchId           string                 `protobuf:"bytes,5,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	Reason               HandlerInvokeType      `protobuf:"varint,6,opt,name=reason,proto3,enum=cortex.axon.HandlerInvokeType" json:"reason,omitempty"`
+	Attempt              int32                  `protobuf:"varint,7,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	State                InvocationState        `protobuf:"varint,8,opt,name=state,proto3,enum=cortex.axon.InvocationState" json:"state,omitempty"`
+	QueuedTimestamp      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=queued_timestamp,json=queuedTimestamp,proto3" json:"queued_timestamp,omitempty"`
+	SentTimestamp        *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=sent_timestamp,json=sentTimestamp,proto3" json:"sent_timestamp,omitempty"`
+	CompletedTimestamp   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=completed_timestamp,json=completedTimestamp,proto3" json:"completed_timestamp,omitempty"`
+	ElapsedMs            int64                  `protobuf:"varint,12,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	ArgsSize             int32                  `protobuf:"varint,13,opt,name=args_size,json=argsSize,proto3" json:"args_size,omitempty"`
+	Error                *Error                 `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *InvocationInfo) Reset() {
+	*x = InvocationInfo{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HandlerInfo) String() string {
+func (x *InvocationInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HandlerInfo) ProtoMessage() {}
+func (*InvocationInfo) ProtoMessage() {}
 
-func (x *HandlerInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[7]
+func (x *InvocationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,76 +2545,132 @@ func (x *HandlerInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HandlerInfo.ProtoReflect.Descriptor instead.
-func (*HandlerInfo) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use InvocationInfo.ProtoReflect.Descriptor instead.
+func (*InvocationInfo) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *HandlerInfo) GetName() string {
+func (x *InvocationInfo) GetInvocationId() string {
 	if x != nil {
-		return x.Name
+		return x.InvocationId
 	}
 	return ""
 }
 
-func (x *HandlerInfo) GetOptions() []*HandlerOption {
+func (x *InvocationInfo) GetOriginalInvocationId() string {
 	if x != nil {
-		return x.Options
+		return x.OriginalInvocationId
 	}
-	return nil
+	return ""
 }
 
-func (x *HandlerInfo) GetDispatchId() string {
+func (x *InvocationInfo) GetHandlerName() string {
 	if x != nil {
-		return x.DispatchId
+		return x.HandlerName
 	}
 	return ""
 }
 
-func (x *HandlerInfo) GetId() string {
+func (x *InvocationInfo) GetHandlerId() string {
 	if x != nil {
-		return x.Id
+		return x.HandlerId
 	}
 	return ""
 }
 
-func (x *HandlerInfo) GetLastInvokedClientTimestamp() *timestamppb.Timestamp {
+func (x *InvocationInfo) GetDispatchId() string {
 	if x != nil {
-		return x.LastInvokedClientTimestamp
+		return x.DispatchId
+	}
+	return ""
+}
+
+func (x *InvocationInfo) GetReason() HandlerInvokeType {
+	if x != nil {
+		return x.Reason
+	}
+	return HandlerInvokeType_INVOKE
+}
+
+func (x *InvocationInfo) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *InvocationInfo) GetState() InvocationState {
+	if x != nil {
+		return x.State
+	}
+	return InvocationState_INVOCATION_STATE_QUEUED
+}
+
+func (x *InvocationInfo) GetQueuedTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.QueuedTimestamp
 	}
 	return nil
 }
 
-func (x *HandlerInfo) GetIsActive() bool {
+func (x *InvocationInfo) GetSentTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.IsActive
+		return x.SentTimestamp
 	}
-	return false
+	return nil
 }
 
-type ListHandlersResponse struct {
+func (x *InvocationInfo) GetCompletedTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedTimestamp
+	}
+	return nil
+}
+
+func (x *InvocationInfo) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+func (x *InvocationInfo) GetArgsSize() int32 {
+	if x != nil {
+		return x.ArgsSize
+	}
+	return 0
+}
+
+func (x *InvocationInfo) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ListInvocationsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
-	Handlers      []*HandlerInfo         `protobuf:"bytes,2,rep,name=handlers,proto3" json:"handlers,omitempty"`
+	Invocations   []*InvocationInfo      `protobuf:"bytes,2,rep,name=invocations,proto3" json:"invocations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListHandlersResponse) Reset() {
-	*x = ListHandlersResponse{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[8]
+func (x *ListInvocationsResponse) Reset() {
+	*x = ListInvocationsResponse{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListHandlersResponse) String() string {
+func (x *ListInvocationsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListHandlersResponse) ProtoMessage() {}
+func (*ListInvocationsResponse) ProtoMessage() {}
 
-func (x *ListHandlersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[8]
+func (x *ListInvocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -605,21 +2681,21 @@ func (x *ListHandlersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListHandlersResponse.ProtoReflect.Descriptor instead.
-func (*ListHandlersResponse) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ListInvocationsResponse.ProtoReflect.Descriptor instead.
+func (*ListInvocationsResponse) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *ListHandlersResponse) GetError() *Error {
+func (x *ListInvocationsResponse) GetError() *Error {
 	if x != nil {
 		return x.Error
 	}
 	return nil
 }
 
-func (x *ListHandlersResponse) GetHandlers() []*HandlerInfo {
+func (x *ListInvocationsResponse) GetInvocations() []*InvocationInfo {
 	if x != nil {
-		return x.Handlers
+		return x.Invocations
 	}
 	return nil
 }
@@ -634,7 +2710,7 @@ type DispatchRequest struct {
 
 func (x *DispatchRequest) Reset() {
 	*x = DispatchRequest{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[9]
+	mi := &file_cortex_axon_agent_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -646,7 +2722,7 @@ func (x *DispatchRequest) String() string {
 func (*DispatchRequest) ProtoMessage() {}
 
 func (x *DispatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[9]
+	mi := &file_cortex_axon_agent_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,7 +2735,7 @@ func (x *DispatchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DispatchRequest.ProtoReflect.Descriptor instead.
 func (*DispatchRequest) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{9}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *DispatchRequest) GetDispatchId() string {
@@ -682,6 +2758,7 @@ type DispatchMessage struct {
 	// Types that are valid to be assigned to Message:
 	//
 	//	*DispatchMessage_Invoke
+	//	*DispatchMessage_Cancel
 	Message       isDispatchMessage_Message `protobuf_oneof:"message"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -689,7 +2766,7 @@ type DispatchMessage struct {
 
 func (x *DispatchMessage) Reset() {
 	*x = DispatchMessage{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[10]
+	mi := &file_cortex_axon_agent_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -701,7 +2778,93 @@ func (x *DispatchMessage) String() string {
 func (*DispatchMessage) ProtoMessage() {}
 
 func (x *DispatchMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[10]
+	mi := &file_cortex_axon_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DispatchMessage.ProtoReflect.Descriptor instead.
+func (*DispatchMessage) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DispatchMessage) GetType() DispatchMessageType {
+	if x != nil {
+		return x.Type
+	}
+	return DispatchMessageType_DISPATCH_MESSAGE_TYPE_NONE
+}
+
+func (x *DispatchMessage) GetMessage() isDispatchMessage_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *DispatchMessage) GetInvoke() *DispatchHandlerInvoke {
+	if x != nil {
+		if x, ok := x.Message.(*DispatchMessage_Invoke); ok {
+			return x.Invoke
+		}
+	}
+	return nil
+}
+
+func (x *DispatchMessage) GetCancel() *DispatchCancel {
+	if x != nil {
+		if x, ok := x.Message.(*DispatchMessage_Cancel); ok {
+			return x.Cancel
+		}
+	}
+	return nil
+}
+
+type isDispatchMessage_Message interface {
+	isDispatchMessage_Message()
+}
+
+type DispatchMessage_Invoke struct {
+	Invoke *DispatchHandlerInvoke `protobuf:"bytes,10,opt,name=invoke,proto3,oneof"`
+}
+
+type DispatchMessage_Cancel struct {
+	Cancel *DispatchCancel `protobuf:"bytes,11,opt,name=cancel,proto3,oneof"`
+}
+
+func (*DispatchMessage_Invoke) isDispatchMessage_Message() {}
+
+func (*DispatchMessage_Cancel) isDispatchMessage_Message() {}
+
+type DispatchCancel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InvocationId  string                 `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
+	Reason        CancelReason           `protobuf:"varint,2,opt,name=reason,proto3,enum=cortex.axon.CancelReason" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DispatchCancel) Reset() {
+	*x = DispatchCancel{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DispatchCancel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DispatchCancel) ProtoMessage() {}
+
+func (x *DispatchCancel) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -712,60 +2875,43 @@ func (x *DispatchMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DispatchMessage.ProtoReflect.Descriptor instead.
-func (*DispatchMessage) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{10}
-}
-
-func (x *DispatchMessage) GetType() DispatchMessageType {
-	if x != nil {
-		return x.Type
-	}
-	return DispatchMessageType_DISPATCH_MESSAGE_TYPE_NONE
+// Deprecated: Use DispatchCancel.ProtoReflect.Descriptor instead.
+func (*DispatchCancel) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *DispatchMessage) GetMessage() isDispatchMessage_Message {
+func (x *DispatchCancel) GetInvocationId() string {
 	if x != nil {
-		return x.Message
+		return x.InvocationId
 	}
-	return nil
+	return ""
 }
 
-func (x *DispatchMessage) GetInvoke() *DispatchHandlerInvoke {
+func (x *DispatchCancel) GetReason() CancelReason {
 	if x != nil {
-		if x, ok := x.Message.(*DispatchMessage_Invoke); ok {
-			return x.Invoke
-		}
+		return x.Reason
 	}
-	return nil
-}
-
-type isDispatchMessage_Message interface {
-	isDispatchMessage_Message()
-}
-
-type DispatchMessage_Invoke struct {
-	Invoke *DispatchHandlerInvoke `protobuf:"bytes,10,opt,name=invoke,proto3,oneof"`
+	return CancelReason_CANCEL_REASON_NONE
 }
 
-func (*DispatchMessage_Invoke) isDispatchMessage_Message() {}
-
 type DispatchHandlerInvoke struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	InvocationId  string                 `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
-	DispatchId    string                 `protobuf:"bytes,2,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
-	HandlerId     string                 `protobuf:"bytes,3,opt,name=handler_id,json=handlerId,proto3" json:"handler_id,omitempty"`
-	HandlerName   string                 `protobuf:"bytes,4,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
-	TimeoutMs     int32                  `protobuf:"varint,10,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
-	Reason        HandlerInvokeType      `protobuf:"varint,11,opt,name=reason,proto3,enum=cortex.axon.HandlerInvokeType" json:"reason,omitempty"`
-	Args          map[string]string      `protobuf:"bytes,20,rep,name=args,proto3" json:"args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	InvocationId         string                 `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
+	DispatchId           string                 `protobuf:"bytes,2,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	HandlerId            string                 `protobuf:"bytes,3,opt,name=handler_id,json=handlerId,proto3" json:"handler_id,omitempty"`
+	HandlerName          string                 `protobuf:"bytes,4,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	TimeoutMs            int32                  `protobuf:"varint,10,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	Reason               HandlerInvokeType      `protobuf:"varint,11,opt,name=reason,proto3,enum=cortex.axon.HandlerInvokeType" json:"reason,omitempty"`
+	Attempt              int32                  `protobuf:"varint,12,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	OriginalInvocationId string                 `protobuf:"bytes,13,opt,name=original_invocation_id,json=originalInvocationId,proto3" json:"original_invocation_id,omitempty"`
+	Args                 map[string]string      `protobuf:"bytes,20,rep,name=args,proto3" json:"args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *DispatchHandlerInvoke) Reset() {
 	*x = DispatchHandlerInvoke{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[11]
+	mi := &file_cortex_axon_agent_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -777,7 +2923,7 @@ func (x *DispatchHandlerInvoke) String() string {
 func (*DispatchHandlerInvoke) ProtoMessage() {}
 
 func (x *DispatchHandlerInvoke) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[11]
+	mi := &file_cortex_axon_agent_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -790,7 +2936,7 @@ func (x *DispatchHandlerInvoke) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DispatchHandlerInvoke.ProtoReflect.Descriptor instead.
 func (*DispatchHandlerInvoke) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{11}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *DispatchHandlerInvoke) GetInvocationId() string {
@@ -835,6 +2981,20 @@ func (x *DispatchHandlerInvoke) GetReason() HandlerInvokeType {
 	return HandlerInvokeType_INVOKE
 }
 
+func (x *DispatchHandlerInvoke) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *DispatchHandlerInvoke) GetOriginalInvocationId() string {
+	if x != nil {
+		return x.OriginalInvocationId
+	}
+	return ""
+}
+
 func (x *DispatchHandlerInvoke) GetArgs() map[string]string {
 	if x != nil {
 		return x.Args
@@ -853,7 +3013,7 @@ type Log struct {
 
 func (x *Log) Reset() {
 	*x = Log{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[12]
+	mi := &file_cortex_axon_agent_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -865,7 +3025,7 @@ func (x *Log) String() string {
 func (*Log) ProtoMessage() {}
 
 func (x *Log) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[12]
+	mi := &file_cortex_axon_agent_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -878,7 +3038,7 @@ func (x *Log) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Log.ProtoReflect.Descriptor instead.
 func (*Log) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{12}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *Log) GetLevel() string {
@@ -919,7 +3079,7 @@ type ReportInvocationRequest struct {
 
 func (x *ReportInvocationRequest) Reset() {
 	*x = ReportInvocationRequest{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[13]
+	mi := &file_cortex_axon_agent_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -931,7 +3091,7 @@ func (x *ReportInvocationRequest) String() string {
 func (*ReportInvocationRequest) ProtoMessage() {}
 
 func (x *ReportInvocationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[13]
+	mi := &file_cortex_axon_agent_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -944,7 +3104,7 @@ func (x *ReportInvocationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportInvocationRequest.ProtoReflect.Descriptor instead.
 func (*ReportInvocationRequest) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{13}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *ReportInvocationRequest) GetHandlerInvoke() *DispatchHandlerInvoke {
@@ -1025,7 +3185,7 @@ type ReportInvocationResponse struct {
 
 func (x *ReportInvocationResponse) Reset() {
 	*x = ReportInvocationResponse{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[14]
+	mi := &file_cortex_axon_agent_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1037,7 +3197,7 @@ func (x *ReportInvocationResponse) String() string {
 func (*ReportInvocationResponse) ProtoMessage() {}
 
 func (x *ReportInvocationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[14]
+	mi := &file_cortex_axon_agent_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1050,7 +3210,7 @@ func (x *ReportInvocationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportInvocationResponse.ProtoReflect.Descriptor instead.
 func (*ReportInvocationResponse) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{14}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *ReportInvocationResponse) GetError() *Error {
@@ -1069,7 +3229,7 @@ type InvokeResult struct {
 
 func (x *InvokeResult) Reset() {
 	*x = InvokeResult{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[15]
+	mi := &file_cortex_axon_agent_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1081,7 +3241,7 @@ func (x *InvokeResult) String() string {
 func (*InvokeResult) ProtoMessage() {}
 
 func (x *InvokeResult) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[15]
+	mi := &file_cortex_axon_agent_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1094,7 +3254,7 @@ func (x *InvokeResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InvokeResult.ProtoReflect.Descriptor instead.
 func (*InvokeResult) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{15}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *InvokeResult) GetValue() string {
@@ -1104,6 +3264,11 @@ func (x *InvokeResult) GetValue() string {
 	return ""
 }
 
+// GetHandlerHistoryRequest selects executions that started at or after
+// start_time and before end_time. With tail, the most recent tail executions
+// are returned, and next_cursor in the response is passed as cursor to get the
+// tail executions before those. result, dispatch_id and invocation_id, when
+// set, return only the executions that match them.
 type GetHandlerHistoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
@@ -1111,13 +3276,17 @@ type GetHandlerHistoryRequest struct {
 	EndTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
 	IncludeLogs   bool                   `protobuf:"varint,4,opt,name=include_logs,json=includeLogs,proto3" json:"include_logs,omitempty"`
 	Tail          int32                  `protobuf:"varint,5,opt,name=tail,proto3" json:"tail,omitempty"`
+	Cursor        string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Result        HandlerRunResult       `protobuf:"varint,7,opt,name=result,proto3,enum=cortex.axon.HandlerRunResult" json:"result,omitempty"`
+	DispatchId    string                 `protobuf:"bytes,8,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	InvocationId  string                 `protobuf:"bytes,9,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetHandlerHistoryRequest) Reset() {
 	*x = GetHandlerHistoryRequest{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[16]
+	mi := &file_cortex_axon_agent_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1129,7 +3298,7 @@ func (x *GetHandlerHistoryRequest) String() string {
 func (*GetHandlerHistoryRequest) ProtoMessage() {}
 
 func (x *GetHandlerHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[16]
+	mi := &file_cortex_axon_agent_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1142,7 +3311,7 @@ func (x *GetHandlerHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetHandlerHistoryRequest.ProtoReflect.Descriptor instead.
 func (*GetHandlerHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{16}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *GetHandlerHistoryRequest) GetHandlerName() string {
@@ -1180,17 +3349,54 @@ func (x *GetHandlerHistoryRequest) GetTail() int32 {
 	return 0
 }
 
+func (x *GetHandlerHistoryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetHandlerHistoryRequest) GetResult() HandlerRunResult {
+	if x != nil {
+		return x.Result
+	}
+	return HandlerRunResult_HANDLER_RUN_RESULT_NONE
+}
+
+func (x *GetHandlerHistoryRequest) GetDispatchId() string {
+	if x != nil {
+		return x.DispatchId
+	}
+	return ""
+}
+
+func (x *GetHandlerHistoryRequest) GetInvocationId() string {
+	if x != nil {
+		return x.InvocationId
+	}
+	return ""
+}
+
+// HandlerExecution is a handler run recorded in history. The result and args
+// values are cut short, ending in a truncation marker, when they are over the
+// agent's limit, and result_truncated is then set.
 type HandlerExecution struct {
 	state                  protoimpl.MessageState `protogen:"open.v1"`
 	HandlerName            string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
 	HandlerId              string                 `protobuf:"bytes,2,opt,name=handler_id,json=handlerId,proto3" json:"handler_id,omitempty"`
 	InvocationId           string                 `protobuf:"bytes,3,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
 	DispatchId             string                 `protobuf:"bytes,4,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	Attempt                int32                  `protobuf:"varint,5,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	OriginalInvocationId   string                 `protobuf:"bytes,6,opt,name=original_invocation_id,json=originalInvocationId,proto3" json:"original_invocation_id,omitempty"`
+	Reason                 HandlerInvokeType      `protobuf:"varint,7,opt,name=reason,proto3,enum=cortex.axon.HandlerInvokeType" json:"reason,omitempty"`
+	Args                   map[string]string      `protobuf:"bytes,8,rep,name=args,proto3" json:"args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	PublishServerTimestamp *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=publish_server_timestamp,json=publishServerTimestamp,proto3" json:"publish_server_timestamp,omitempty"`
 	ReceiveServerTimestamp *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=receive_server_timestamp,json=receiveServerTimestamp,proto3" json:"receive_server_timestamp,omitempty"`
 	StartClientTimestamp   *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=start_client_timestamp,json=startClientTimestamp,proto3" json:"start_client_timestamp,omitempty"`
 	DurationMs             int32                  `protobuf:"varint,13,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	Error                  *Error                 `protobuf:"bytes,20,opt,name=error,proto3" json:"error,omitempty"`
+	Result                 string                 `protobuf:"bytes,21,opt,name=result,proto3" json:"result,omitempty"`
+	ResultTruncated        bool                   `protobuf:"varint,22,opt,name=result_truncated,json=resultTruncated,proto3" json:"result_truncated,omitempty"`
 	Logs                   []*Log                 `protobuf:"bytes,30,rep,name=logs,proto3" json:"logs,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
@@ -1198,7 +3404,7 @@ type HandlerExecution struct {
 
 func (x *HandlerExecution) Reset() {
 	*x = HandlerExecution{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[17]
+	mi := &file_cortex_axon_agent_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1210,7 +3416,7 @@ func (x *HandlerExecution) String() string {
 func (*HandlerExecution) ProtoMessage() {}
 
 func (x *HandlerExecution) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[17]
+	mi := &file_cortex_axon_agent_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1223,7 +3429,7 @@ func (x *HandlerExecution) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HandlerExecution.ProtoReflect.Descriptor instead.
 func (*HandlerExecution) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{17}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *HandlerExecution) GetHandlerName() string {
@@ -1254,6 +3460,34 @@ func (x *HandlerExecution) GetDispatchId() string {
 	return ""
 }
 
+func (x *HandlerExecution) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *HandlerExecution) GetOriginalInvocationId() string {
+	if x != nil {
+		return x.OriginalInvocationId
+	}
+	return ""
+}
+
+func (x *HandlerExecution) GetReason() HandlerInvokeType {
+	if x != nil {
+		return x.Reason
+	}
+	return HandlerInvokeType_INVOKE
+}
+
+func (x *HandlerExecution) GetArgs() map[string]string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
 func (x *HandlerExecution) GetPublishServerTimestamp() *timestamppb.Timestamp {
 	if x != nil {
 		return x.PublishServerTimestamp
@@ -1289,6 +3523,20 @@ func (x *HandlerExecution) GetError() *Error {
 	return nil
 }
 
+func (x *HandlerExecution) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *HandlerExecution) GetResultTruncated() bool {
+	if x != nil {
+		return x.ResultTruncated
+	}
+	return false
+}
+
 func (x *HandlerExecution) GetLogs() []*Log {
 	if x != nil {
 		return x.Logs
@@ -1297,16 +3545,18 @@ func (x *HandlerExecution) GetLogs() []*Log {
 }
 
 type GetHandlerHistoryResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
-	History       []*HandlerExecution    `protobuf:"bytes,2,rep,name=history,proto3" json:"history,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Error   *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	History []*HandlerExecution    `protobuf:"bytes,2,rep,name=history,proto3" json:"history,omitempty"`
+	// next_cursor is empty when there are no earlier executions
+	NextCursor    string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetHandlerHistoryResponse) Reset() {
 	*x = GetHandlerHistoryResponse{}
-	mi := &file_cortex_axon_agent_proto_msgTypes[18]
+	mi := &file_cortex_axon_agent_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1318,7 +3568,7 @@ func (x *GetHandlerHistoryResponse) String() string {
 func (*GetHandlerHistoryResponse) ProtoMessage() {}
 
 func (x *GetHandlerHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cortex_axon_agent_proto_msgTypes[18]
+	mi := &file_cortex_axon_agent_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1331,7 +3581,7 @@ func (x *GetHandlerHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetHandlerHistoryResponse.ProtoReflect.Descriptor instead.
 func (*GetHandlerHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{18}
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *GetHandlerHistoryResponse) GetError() *Error {
@@ -1348,23 +3598,274 @@ func (x *GetHandlerHistoryResponse) GetHistory() []*HandlerExecution {
 	return nil
 }
 
+func (x *GetHandlerHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// WatchHandlerExecutionsRequest watches the executions of the handler named
+// handler_name, or of every handler if it is empty. Without include_logs,
+// LOG events aren't sent and completed executions have no logs.
+type WatchHandlerExecutionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandlerName   string                 `protobuf:"bytes,1,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	IncludeLogs   bool                   `protobuf:"varint,2,opt,name=include_logs,json=includeLogs,proto3" json:"include_logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchHandlerExecutionsRequest) Reset() {
+	*x = WatchHandlerExecutionsRequest{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchHandlerExecutionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchHandlerExecutionsRequest) ProtoMessage() {}
+
+func (x *WatchHandlerExecutionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchHandlerExecutionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchHandlerExecutionsRequest) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *WatchHandlerExecutionsRequest) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
+}
+
+func (x *WatchHandlerExecutionsRequest) GetIncludeLogs() bool {
+	if x != nil {
+		return x.IncludeLogs
+	}
+	return false
+}
+
+// HandlerExecutionEvent is a step in the life of an invocation. execution is
+// set on completion events and log on LOG events.
+type HandlerExecutionEvent struct {
+	state                protoimpl.MessageState    `protogen:"open.v1"`
+	Type                 HandlerExecutionEventType `protobuf:"varint,1,opt,name=type,proto3,enum=cortex.axon.HandlerExecutionEventType" json:"type,omitempty"`
+	Timestamp            *timestamppb.Timestamp    `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	HandlerName          string                    `protobuf:"bytes,3,opt,name=handler_name,json=handlerName,proto3" json:"handler_name,omitempty"`
+	InvocationId         string                    `protobuf:"bytes,4,opt,name=invocation_id,json=invocationId,proto3" json:"invocation_id,omitempty"`
+	OriginalInvocationId string                    `protobuf:"bytes,5,opt,name=original_invocation_id,json=originalInvocationId,proto3" json:"original_invocation_id,omitempty"`
+	DispatchId           string                    `protobuf:"bytes,6,opt,name=dispatch_id,json=dispatchId,proto3" json:"dispatch_id,omitempty"`
+	Attempt              int32                     `protobuf:"varint,7,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Reason               HandlerInvokeType         `protobuf:"varint,8,opt,name=reason,proto3,enum=cortex.axon.HandlerInvokeType" json:"reason,omitempty"`
+	Execution            *HandlerExecution         `protobuf:"bytes,10,opt,name=execution,proto3" json:"execution,omitempty"`
+	Log                  *Log                      `protobuf:"bytes,11,opt,name=log,proto3" json:"log,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *HandlerExecutionEvent) Reset() {
+	*x = HandlerExecutionEvent{}
+	mi := &file_cortex_axon_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandlerExecutionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandlerExecutionEvent) ProtoMessage() {}
+
+func (x *HandlerExecutionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_cortex_axon_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandlerExecutionEvent.ProtoReflect.Descriptor instead.
+func (*HandlerExecutionEvent) Descriptor() ([]byte, []int) {
+	return file_cortex_axon_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *HandlerExecutionEvent) GetType() HandlerExecutionEventType {
+	if x != nil {
+		return x.Type
+	}
+	return HandlerExecutionEventType_EXECUTION_EVENT_NONE
+}
+
+func (x *HandlerExecutionEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *HandlerExecutionEvent) GetHandlerName() string {
+	if x != nil {
+		return x.HandlerName
+	}
+	return ""
+}
+
+func (x *HandlerExecutionEvent) GetInvocationId() string {
+	if x != nil {
+		return x.InvocationId
+	}
+	return ""
+}
+
+func (x *HandlerExecutionEvent) GetOriginalInvocationId() string {
+	if x != nil {
+		return x.OriginalInvocationId
+	}
+	return ""
+}
+
+func (x *HandlerExecutionEvent) GetDispatchId() string {
+	if x != nil {
+		return x.DispatchId
+	}
+	return ""
+}
+
+func (x *HandlerExecutionEvent) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *HandlerExecutionEvent) GetReason() HandlerInvokeType {
+	if x != nil {
+		return x.Reason
+	}
+	return HandlerInvokeType_INVOKE
+}
+
+func (x *HandlerExecutionEvent) GetExecution() *HandlerExecution {
+	if x != nil {
+		return x.Execution
+	}
+	return nil
+}
+
+func (x *HandlerExecutionEvent) GetLog() *Log {
+	if x != nil {
+		return x.Log
+	}
+	return nil
+}
+
 var File_cortex_axon_agent_proto protoreflect.FileDescriptor
 
 const file_cortex_axon_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x17cortex-axon-agent.proto\x12\vcortex.axon\x1a\fcommon.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb1\x01\n" +
+	"\x17cortex-axon-agent.proto\x12\vcortex.axon\x1a\fcommon.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xeb\x01\n" +
 	"\x16RegisterHandlerRequest\x12\x1f\n" +
 	"\vdispatch_id\x18\x01 \x01(\tR\n" +
 	"dispatchId\x12!\n" +
 	"\fhandler_name\x18\x02 \x01(\tR\vhandlerName\x12\x1d\n" +
 	"\n" +
 	"timeout_ms\x18\x03 \x01(\x05R\ttimeoutMs\x124\n" +
-	"\aoptions\x18\x04 \x03(\v2\x1a.cortex.axon.HandlerOptionR\aoptions\"_\n" +
+	"\aoptions\x18\x04 \x03(\v2\x1a.cortex.axon.HandlerOptionR\aoptions\x128\n" +
+	"\vworker_pool\x18\x05 \x01(\v2\x17.cortex.axon.WorkerPoolR\n" +
+	"workerPool\"]\n" +
+	"\n" +
+	"WorkerPool\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12;\n" +
+	"\bstrategy\x18\x02 \x01(\x0e2\x1f.cortex.axon.WorkerPoolStrategyR\bstrategy\"_\n" +
 	"\x13HandlerInvokeOption\x122\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x04type\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\"U\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xed\x01\n" +
+	"\x12HandlerRetryOption\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x12,\n" +
+	"\x12initial_backoff_ms\x18\x02 \x01(\x05R\x10initialBackoffMs\x12$\n" +
+	"\x0emax_backoff_ms\x18\x03 \x01(\x05R\fmaxBackoffMs\x12-\n" +
+	"\x12backoff_multiplier\x18\x04 \x01(\x01R\x11backoffMultiplier\x12\x16\n" +
+	"\x06jitter\x18\x05 \x01(\x01R\x06jitter\x12\x19\n" +
+	"\bretry_on\x18\x06 \x03(\tR\aretryOn\"\x81\x01\n" +
+	"\x18HandlerConcurrencyOption\x12%\n" +
+	"\x0emax_concurrent\x18\x01 \x01(\x05R\rmaxConcurrent\x12>\n" +
+	"\boverflow\x18\x02 \x01(\x0e2\".cortex.axon.HandlerOverflowPolicyR\boverflow\"\xc5\x01\n" +
+	"\x15HandlerScheduleOption\x12\x1a\n" +
+	"\btimezone\x18\x01 \x01(\tR\btimezone\x12\x1b\n" +
+	"\tjitter_ms\x18\x02 \x01(\x05R\bjitterMs\x12H\n" +
+	"\x0emisfire_policy\x18\x03 \x01(\x0e2!.cortex.axon.HandlerMisfirePolicyR\rmisfirePolicy\x12)\n" +
+	"\x11max_catch_up_runs\x18\x04 \x01(\x05R\x0emaxCatchUpRuns\"\x83\x02\n" +
+	"\x13WebhookVerification\x128\n" +
+	"\x04type\x18\x01 \x01(\x0e2$.cortex.axon.WebhookVerificationTypeR\x04type\x12\x1d\n" +
+	"\n" +
+	"secret_env\x18\x02 \x01(\tR\tsecretEnv\x12#\n" +
+	"\rsecret_plugin\x18\x03 \x01(\tR\fsecretPlugin\x12\x16\n" +
+	"\x06header\x18\x04 \x01(\tR\x06header\x12\x16\n" +
+	"\x06prefix\x18\x05 \x01(\tR\x06prefix\x12>\n" +
+	"\x1btimestamp_tolerance_seconds\x18\x06 \x01(\x05R\x19timestampToleranceSeconds\"\x8b\x01\n" +
+	"\rWebhookFilter\x128\n" +
+	"\x06source\x18\x01 \x01(\x0e2 .cortex.axon.WebhookFilterSourceR\x06source\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\tR\x06values\x12\x16\n" +
+	"\x06negate\x18\x04 \x01(\bR\x06negate\"j\n" +
+	"\fWebhookDedup\x12\x16\n" +
+	"\x06header\x18\x01 \x01(\tR\x06header\x12\x1b\n" +
+	"\thash_body\x18\x02 \x01(\bR\bhashBody\x12%\n" +
+	"\x0ewindow_seconds\x18\x03 \x01(\x05R\rwindowSeconds\"\xbc\x02\n" +
+	"\x14HandlerWebhookOption\x12D\n" +
+	"\fverification\x18\x01 \x01(\v2 .cortex.axon.WebhookVerificationR\fverification\x12 \n" +
+	"\vsynchronous\x18\x02 \x01(\bR\vsynchronous\x12.\n" +
+	"\x13response_timeout_ms\x18\x03 \x01(\x05R\x11responseTimeoutMs\x12%\n" +
+	"\x0eredact_headers\x18\x04 \x03(\tR\rredactHeaders\x124\n" +
+	"\afilters\x18\x05 \x03(\v2\x1a.cortex.axon.WebhookFilterR\afilters\x12/\n" +
+	"\x05dedup\x18\x06 \x01(\v2\x19.cortex.axon.WebhookDedupR\x05dedup\"\xc7\x01\n" +
+	"\x0fWebhookResponse\x12\x1f\n" +
+	"\vstatus_code\x18\x01 \x01(\x05R\n" +
+	"statusCode\x12C\n" +
+	"\aheaders\x18\x02 \x03(\v2).cortex.axon.WebhookResponse.HeadersEntryR\aheaders\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"]\n" +
+	"\x17HandlerDependencyOption\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\x12\x1f\n" +
+	"\vpass_result\x18\x02 \x01(\bR\n" +
+	"passResult\"\x8d\x01\n" +
+	"\x16HandlerRetentionOption\x12\x1c\n" +
+	"\n" +
+	"max_age_ms\x18\x01 \x01(\x03R\bmaxAgeMs\x12\x1b\n" +
+	"\tmax_count\x18\x02 \x01(\x05R\bmaxCount\x12\x1b\n" +
+	"\tmax_bytes\x18\x03 \x01(\x03R\bmaxBytes\x12\x1b\n" +
+	"\tmin_count\x18\x04 \x01(\x05R\bminCount\"\xe7\x03\n" +
 	"\rHandlerOption\x12:\n" +
-	"\x06invoke\x18\x01 \x01(\v2 .cortex.axon.HandlerInvokeOptionH\x00R\x06invokeB\b\n" +
+	"\x06invoke\x18\x01 \x01(\v2 .cortex.axon.HandlerInvokeOptionH\x00R\x06invoke\x127\n" +
+	"\x05retry\x18\x02 \x01(\v2\x1f.cortex.axon.HandlerRetryOptionH\x00R\x05retry\x12I\n" +
+	"\vconcurrency\x18\x03 \x01(\v2%.cortex.axon.HandlerConcurrencyOptionH\x00R\vconcurrency\x12=\n" +
+	"\awebhook\x18\x04 \x01(\v2!.cortex.axon.HandlerWebhookOptionH\x00R\awebhook\x12@\n" +
+	"\bschedule\x18\x05 \x01(\v2\".cortex.axon.HandlerScheduleOptionH\x00R\bschedule\x12F\n" +
+	"\n" +
+	"dependency\x18\x06 \x01(\v2$.cortex.axon.HandlerDependencyOptionH\x00R\n" +
+	"dependency\x12C\n" +
+	"\tretention\x18\a \x01(\v2#.cortex.axon.HandlerRetentionOptionH\x00R\tretentionB\b\n" +
 	"\x06option\"S\n" +
 	"\x17RegisterHandlerResponse\x12(\n" +
 	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x12\x0e\n" +
@@ -1373,7 +3874,7 @@ const file_cortex_axon_agent_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"E\n" +
 	"\x19UnregisterHandlerResponse\x12(\n" +
 	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\"\x15\n" +
-	"\x13ListHandlersRequest\"\x84\x02\n" +
+	"\x13ListHandlersRequest\"\x9f\x05\n" +
 	"\vHandlerInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x124\n" +
 	"\aoptions\x18\x02 \x03(\v2\x1a.cortex.axon.HandlerOptionR\aoptions\x12\x1f\n" +
@@ -1381,19 +3882,75 @@ const file_cortex_axon_agent_proto_rawDesc = "" +
 	"dispatchId\x12\x0e\n" +
 	"\x02id\x18\x15 \x01(\tR\x02id\x12]\n" +
 	"\x1dlast_invoked_client_timestamp\x18d \x01(\v2\x1a.google.protobuf.TimestampR\x1alastInvokedClientTimestamp\x12\x1b\n" +
-	"\tis_active\x18e \x01(\bR\bisActive\"v\n" +
+	"\tis_active\x18e \x01(\bR\bisActive\x12\x1b\n" +
+	"\tis_paused\x18f \x01(\bR\bisPaused\x12H\n" +
+	"\x12next_run_timestamp\x18g \x01(\v2\x1a.google.protobuf.TimestampR\x10nextRunTimestamp\x12\x1f\n" +
+	"\vinterval_ms\x18h \x01(\x03R\n" +
+	"intervalMs\x12>\n" +
+	"\vlast_result\x18i \x01(\x0e2\x1d.cortex.axon.HandlerRunResultR\n" +
+	"lastResult\x12(\n" +
+	"\x10last_duration_ms\x18j \x01(\x05R\x0elastDurationMs\x121\n" +
+	"\x14consecutive_failures\x18k \x01(\x05R\x13consecutiveFailures\x121\n" +
+	"\n" +
+	"last_error\x18l \x01(\v2\x12.cortex.axon.ErrorR\tlastError\x12A\n" +
+	"\x1dconsecutive_failures_at_least\x18m \x01(\bR\x1aconsecutiveFailuresAtLeast\"v\n" +
 	"\x14ListHandlersResponse\x12(\n" +
 	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x124\n" +
-	"\bhandlers\x18\x02 \x03(\v2\x18.cortex.axon.HandlerInfoR\bhandlers\"Y\n" +
+	"\bhandlers\x18\x02 \x03(\v2\x18.cortex.axon.HandlerInfoR\bhandlers\"8\n" +
+	"\x13PauseHandlerRequest\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\"@\n" +
+	"\x14PauseHandlerResponse\x12(\n" +
+	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\"9\n" +
+	"\x14ResumeHandlerRequest\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\"A\n" +
+	"\x15ResumeHandlerResponse\x12(\n" +
+	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\":\n" +
+	"\x15TriggerHandlerRequest\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\"g\n" +
+	"\x16TriggerHandlerResponse\x12(\n" +
+	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x12#\n" +
+	"\rinvocation_id\x18\x02 \x01(\tR\finvocationId\">\n" +
+	"\x17CancelInvocationRequest\x12#\n" +
+	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\"D\n" +
+	"\x18CancelInvocationResponse\x12(\n" +
+	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\";\n" +
+	"\x16ListInvocationsRequest\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\"\x91\x05\n" +
+	"\x0eInvocationInfo\x12#\n" +
+	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\x124\n" +
+	"\x16original_invocation_id\x18\x02 \x01(\tR\x14originalInvocationId\x12!\n" +
+	"\fhandler_name\x18\x03 \x01(\tR\vhandlerName\x12\x1d\n" +
+	"\n" +
+	"handler_id\x18\x04 \x01(\tR\thandlerId\x12\x1f\n" +
+	"\vdispatch_id\x18\x05 \x01(\tR\n" +
+	"dispatchId\x126\n" +
+	"\x06reason\x18\x06 \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x06reason\x12\x18\n" +
+	"\aattempt\x18\a \x01(\x05R\aattempt\x122\n" +
+	"\x05state\x18\b \x01(\x0e2\x1c.cortex.axon.InvocationStateR\x05state\x12E\n" +
+	"\x10queued_timestamp\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0fqueuedTimestamp\x12A\n" +
+	"\x0esent_timestamp\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rsentTimestamp\x12K\n" +
+	"\x13completed_timestamp\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\x12completedTimestamp\x12\x1d\n" +
+	"\n" +
+	"elapsed_ms\x18\f \x01(\x03R\telapsedMs\x12\x1b\n" +
+	"\targs_size\x18\r \x01(\x05R\bargsSize\x12(\n" +
+	"\x05error\x18\x0e \x01(\v2\x12.cortex.axon.ErrorR\x05error\"\x82\x01\n" +
+	"\x17ListInvocationsResponse\x12(\n" +
+	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x12=\n" +
+	"\vinvocations\x18\x02 \x03(\v2\x1b.cortex.axon.InvocationInfoR\vinvocations\"Y\n" +
 	"\x0fDispatchRequest\x12\x1f\n" +
 	"\vdispatch_id\x18\x01 \x01(\tR\n" +
 	"dispatchId\x12%\n" +
-	"\x0eclient_version\x18\x02 \x01(\tR\rclientVersion\"\x90\x01\n" +
+	"\x0eclient_version\x18\x02 \x01(\tR\rclientVersion\"\xc7\x01\n" +
 	"\x0fDispatchMessage\x124\n" +
 	"\x04type\x18\x01 \x01(\x0e2 .cortex.axon.DispatchMessageTypeR\x04type\x12<\n" +
 	"\x06invoke\x18\n" +
-	" \x01(\v2\".cortex.axon.DispatchHandlerInvokeH\x00R\x06invokeB\t\n" +
-	"\amessage\"\xf1\x02\n" +
+	" \x01(\v2\".cortex.axon.DispatchHandlerInvokeH\x00R\x06invoke\x125\n" +
+	"\x06cancel\x18\v \x01(\v2\x1b.cortex.axon.DispatchCancelH\x00R\x06cancelB\t\n" +
+	"\amessage\"h\n" +
+	"\x0eDispatchCancel\x12#\n" +
+	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\x121\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\x19.cortex.axon.CancelReasonR\x06reason\"\xc1\x03\n" +
 	"\x15DispatchHandlerInvoke\x12#\n" +
 	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\x12\x1f\n" +
 	"\vdispatch_id\x18\x02 \x01(\tR\n" +
@@ -1404,7 +3961,9 @@ const file_cortex_axon_agent_proto_rawDesc = "" +
 	"\n" +
 	"timeout_ms\x18\n" +
 	" \x01(\x05R\ttimeoutMs\x126\n" +
-	"\x06reason\x18\v \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x06reason\x12@\n" +
+	"\x06reason\x18\v \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x06reason\x12\x18\n" +
+	"\aattempt\x18\f \x01(\x05R\aattempt\x124\n" +
+	"\x16original_invocation_id\x18\r \x01(\tR\x14originalInvocationId\x12@\n" +
 	"\x04args\x18\x14 \x03(\v2,.cortex.axon.DispatchHandlerInvoke.ArgsEntryR\x04args\x1a7\n" +
 	"\tArgsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
@@ -1426,50 +3985,133 @@ const file_cortex_axon_agent_proto_rawDesc = "" +
 	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\"$\n" +
 	"\fInvokeResult\x12\x14\n" +
 	"\x05value\x18\n" +
-	" \x01(\tR\x05value\"\xe6\x01\n" +
+	" \x01(\tR\x05value\"\xfb\x02\n" +
 	"\x18GetHandlerHistoryRequest\x12!\n" +
 	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\x129\n" +
 	"\n" +
 	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
 	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12!\n" +
 	"\finclude_logs\x18\x04 \x01(\bR\vincludeLogs\x12\x12\n" +
-	"\x04tail\x18\x05 \x01(\x05R\x04tail\"\x89\x04\n" +
+	"\x04tail\x18\x05 \x01(\x05R\x04tail\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\x125\n" +
+	"\x06result\x18\a \x01(\x0e2\x1d.cortex.axon.HandlerRunResultR\x06result\x12\x1f\n" +
+	"\vdispatch_id\x18\b \x01(\tR\n" +
+	"dispatchId\x12#\n" +
+	"\rinvocation_id\x18\t \x01(\tR\finvocationId\"\xca\x06\n" +
 	"\x10HandlerExecution\x12!\n" +
 	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\x12\x1d\n" +
 	"\n" +
 	"handler_id\x18\x02 \x01(\tR\thandlerId\x12#\n" +
 	"\rinvocation_id\x18\x03 \x01(\tR\finvocationId\x12\x1f\n" +
 	"\vdispatch_id\x18\x04 \x01(\tR\n" +
-	"dispatchId\x12T\n" +
+	"dispatchId\x12\x18\n" +
+	"\aattempt\x18\x05 \x01(\x05R\aattempt\x124\n" +
+	"\x16original_invocation_id\x18\x06 \x01(\tR\x14originalInvocationId\x126\n" +
+	"\x06reason\x18\a \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x06reason\x12;\n" +
+	"\x04args\x18\b \x03(\v2'.cortex.axon.HandlerExecution.ArgsEntryR\x04args\x12T\n" +
 	"\x18publish_server_timestamp\x18\n" +
 	" \x01(\v2\x1a.google.protobuf.TimestampR\x16publishServerTimestamp\x12T\n" +
 	"\x18receive_server_timestamp\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\x16receiveServerTimestamp\x12P\n" +
 	"\x16start_client_timestamp\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\x14startClientTimestamp\x12\x1f\n" +
 	"\vduration_ms\x18\r \x01(\x05R\n" +
 	"durationMs\x12(\n" +
-	"\x05error\x18\x14 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x12$\n" +
-	"\x04logs\x18\x1e \x03(\v2\x10.cortex.axon.LogR\x04logs\"~\n" +
+	"\x05error\x18\x14 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x12\x16\n" +
+	"\x06result\x18\x15 \x01(\tR\x06result\x12)\n" +
+	"\x10result_truncated\x18\x16 \x01(\bR\x0fresultTruncated\x12$\n" +
+	"\x04logs\x18\x1e \x03(\v2\x10.cortex.axon.LogR\x04logs\x1a7\n" +
+	"\tArgsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9f\x01\n" +
 	"\x19GetHandlerHistoryResponse\x12(\n" +
 	"\x05error\x18\x01 \x01(\v2\x12.cortex.axon.ErrorR\x05error\x127\n" +
-	"\ahistory\x18\x02 \x03(\v2\x1d.cortex.axon.HandlerExecutionR\ahistory*^\n" +
+	"\ahistory\x18\x02 \x03(\v2\x1d.cortex.axon.HandlerExecutionR\ahistory\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"e\n" +
+	"\x1dWatchHandlerExecutionsRequest\x12!\n" +
+	"\fhandler_name\x18\x01 \x01(\tR\vhandlerName\x12!\n" +
+	"\finclude_logs\x18\x02 \x01(\bR\vincludeLogs\"\xdf\x03\n" +
+	"\x15HandlerExecutionEvent\x12:\n" +
+	"\x04type\x18\x01 \x01(\x0e2&.cortex.axon.HandlerExecutionEventTypeR\x04type\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12!\n" +
+	"\fhandler_name\x18\x03 \x01(\tR\vhandlerName\x12#\n" +
+	"\rinvocation_id\x18\x04 \x01(\tR\finvocationId\x124\n" +
+	"\x16original_invocation_id\x18\x05 \x01(\tR\x14originalInvocationId\x12\x1f\n" +
+	"\vdispatch_id\x18\x06 \x01(\tR\n" +
+	"dispatchId\x12\x18\n" +
+	"\aattempt\x18\a \x01(\x05R\aattempt\x126\n" +
+	"\x06reason\x18\b \x01(\x0e2\x1e.cortex.axon.HandlerInvokeTypeR\x06reason\x12;\n" +
+	"\texecution\x18\n" +
+	" \x01(\v2\x1d.cortex.axon.HandlerExecutionR\texecution\x12\"\n" +
+	"\x03log\x18\v \x01(\v2\x10.cortex.axon.LogR\x03log*T\n" +
+	"\x12WorkerPoolStrategy\x12\x1b\n" +
+	"\x17WORKER_POOL_ROUND_ROBIN\x10\x00\x12!\n" +
+	"\x1dWORKER_POOL_LEAST_OUTSTANDING\x10\x01*z\n" +
 	"\x11HandlerInvokeType\x12\n" +
 	"\n" +
 	"\x06INVOKE\x10\x00\x12\v\n" +
 	"\aRUN_NOW\x10\x01\x12\x11\n" +
 	"\rCRON_SCHEDULE\x10\x02\x12\x10\n" +
 	"\fRUN_INTERVAL\x10\x03\x12\v\n" +
-	"\aWEBHOOK\x10\x04*w\n" +
+	"\aWEBHOOK\x10\x04\x12\f\n" +
+	"\bCATCH_UP\x10\x05\x12\f\n" +
+	"\bUPSTREAM\x10\x06*B\n" +
+	"\x15HandlerOverflowPolicy\x12\x11\n" +
+	"\rOVERFLOW_SKIP\x10\x00\x12\x16\n" +
+	"\x12OVERFLOW_QUEUE_ONE\x10\x01*S\n" +
+	"\x14HandlerMisfirePolicy\x12\x10\n" +
+	"\fMISFIRE_SKIP\x10\x00\x12\x14\n" +
+	"\x10MISFIRE_RUN_ONCE\x10\x01\x12\x13\n" +
+	"\x0fMISFIRE_RUN_ALL\x10\x02*\xe4\x01\n" +
+	"\x17WebhookVerificationType\x12\x1d\n" +
+	"\x19WEBHOOK_VERIFICATION_NONE\x10\x00\x12\x1f\n" +
+	"\x1bWEBHOOK_VERIFICATION_GITHUB\x10\x01\x12\x1f\n" +
+	"\x1bWEBHOOK_VERIFICATION_GITLAB\x10\x02\x12\"\n" +
+	"\x1eWEBHOOK_VERIFICATION_BITBUCKET\x10\x03\x12\x1e\n" +
+	"\x1aWEBHOOK_VERIFICATION_SLACK\x10\x04\x12$\n" +
+	" WEBHOOK_VERIFICATION_HMAC_SHA256\x10\x05*i\n" +
+	"\x13WebhookFilterSource\x12\x19\n" +
+	"\x15WEBHOOK_FILTER_HEADER\x10\x00\x12\x1c\n" +
+	"\x18WEBHOOK_FILTER_JSON_BODY\x10\x01\x12\x19\n" +
+	"\x15WEBHOOK_FILTER_METHOD\x10\x02*m\n" +
+	"\x10HandlerRunResult\x12\x1b\n" +
+	"\x17HANDLER_RUN_RESULT_NONE\x10\x00\x12\x1e\n" +
+	"\x1aHANDLER_RUN_RESULT_SUCCESS\x10\x01\x12\x1c\n" +
+	"\x18HANDLER_RUN_RESULT_ERROR\x10\x02*n\n" +
+	"\x0fInvocationState\x12\x1b\n" +
+	"\x17INVOCATION_STATE_QUEUED\x10\x00\x12\x1e\n" +
+	"\x1aINVOCATION_STATE_IN_FLIGHT\x10\x01\x12\x1e\n" +
+	"\x1aINVOCATION_STATE_COMPLETED\x10\x02*\x94\x01\n" +
 	"\x13DispatchMessageType\x12\x1e\n" +
 	"\x1aDISPATCH_MESSAGE_TYPE_NONE\x10\x00\x12\x1b\n" +
 	"\x17DISPATCH_MESSAGE_INVOKE\x10\x01\x12#\n" +
-	"\x1fDISPATCH_MESSAGE_WORK_COMPLETED\x10\x022\xb3\x04\n" +
+	"\x1fDISPATCH_MESSAGE_WORK_COMPLETED\x10\x02\x12\x1b\n" +
+	"\x17DISPATCH_MESSAGE_CANCEL\x10\x03*z\n" +
+	"\fCancelReason\x12\x16\n" +
+	"\x12CANCEL_REASON_NONE\x10\x00\x12\x19\n" +
+	"\x15CANCEL_REASON_TIMEOUT\x10\x01\x12\x1b\n" +
+	"\x17CANCEL_REASON_REQUESTED\x10\x02\x12\x1a\n" +
+	"\x16CANCEL_REASON_SHUTDOWN\x10\x03*\xe7\x01\n" +
+	"\x19HandlerExecutionEventType\x12\x18\n" +
+	"\x14EXECUTION_EVENT_NONE\x10\x00\x12\x1a\n" +
+	"\x16EXECUTION_EVENT_QUEUED\x10\x01\x12\x1e\n" +
+	"\x1aEXECUTION_EVENT_DISPATCHED\x10\x02\x12\x17\n" +
+	"\x13EXECUTION_EVENT_LOG\x10\x03\x12\x1d\n" +
+	"\x19EXECUTION_EVENT_COMPLETED\x10\x04\x12\x1d\n" +
+	"\x19EXECUTION_EVENT_TIMED_OUT\x10\x05\x12\x1d\n" +
+	"\x19EXECUTION_EVENT_CANCELLED\x10\x062\xe6\b\n" +
 	"\tAxonAgent\x12\\\n" +
 	"\x0fRegisterHandler\x12#.cortex.axon.RegisterHandlerRequest\x1a$.cortex.axon.RegisterHandlerResponse\x12b\n" +
 	"\x11UnregisterHandler\x12%.cortex.axon.UnregisterHandlerRequest\x1a&.cortex.axon.UnregisterHandlerResponse\x12S\n" +
 	"\fListHandlers\x12 .cortex.axon.ListHandlersRequest\x1a!.cortex.axon.ListHandlersResponse\x12b\n" +
 	"\x11GetHandlerHistory\x12%.cortex.axon.GetHandlerHistoryRequest\x1a&.cortex.axon.GetHandlerHistoryResponse\x12J\n" +
 	"\bDispatch\x12\x1c.cortex.axon.DispatchRequest\x1a\x1c.cortex.axon.DispatchMessage(\x010\x01\x12_\n" +
-	"\x10ReportInvocation\x12$.cortex.axon.ReportInvocationRequest\x1a%.cortex.axon.ReportInvocationResponseB\x1cZ\x1agithub.com/cortexapps/axonb\x06proto3"
+	"\x10ReportInvocation\x12$.cortex.axon.ReportInvocationRequest\x1a%.cortex.axon.ReportInvocationResponse\x12S\n" +
+	"\fPauseHandler\x12 .cortex.axon.PauseHandlerRequest\x1a!.cortex.axon.PauseHandlerResponse\x12V\n" +
+	"\rResumeHandler\x12!.cortex.axon.ResumeHandlerRequest\x1a\".cortex.axon.ResumeHandlerResponse\x12Y\n" +
+	"\x0eTriggerHandler\x12\".cortex.axon.TriggerHandlerRequest\x1a#.cortex.axon.TriggerHandlerResponse\x12_\n" +
+	"\x10CancelInvocation\x12$.cortex.axon.CancelInvocationRequest\x1a%.cortex.axon.CancelInvocationResponse\x12\\\n" +
+	"\x0fListInvocations\x12#.cortex.axon.ListInvocationsRequest\x1a$.cortex.axon.ListInvocationsResponse\x12j\n" +
+	"\x16WatchHandlerExecutions\x12*.cortex.axon.WatchHandlerExecutionsRequest\x1a\".cortex.axon.HandlerExecutionEvent0\x01B\x1cZ\x1agithub.com/cortexapps/axonb\x06proto3"
 
 var (
 	file_cortex_axon_agent_proto_rawDescOnce sync.Once
@@ -1483,81 +4125,170 @@ func file_cortex_axon_agent_proto_rawDescGZIP() []byte {
 	return file_cortex_axon_agent_proto_rawDescData
 }
 
-var file_cortex_axon_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_cortex_axon_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_cortex_axon_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 11)
+var file_cortex_axon_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_cortex_axon_agent_proto_goTypes = []any{
-	(HandlerInvokeType)(0),            // 0: cortex.axon.HandlerInvokeType
-	(DispatchMessageType)(0),          // 1: cortex.axon.DispatchMessageType
-	(*RegisterHandlerRequest)(nil),    // 2: cortex.axon.RegisterHandlerRequest
-	(*HandlerInvokeOption)(nil),       // 3: cortex.axon.HandlerInvokeOption
-	(*HandlerOption)(nil),             // 4: cortex.axon.HandlerOption
-	(*RegisterHandlerResponse)(nil),   // 5: cortex.axon.RegisterHandlerResponse
-	(*UnregisterHandlerRequest)(nil),  // 6: cortex.axon.UnregisterHandlerRequest
-	(*UnregisterHandlerResponse)(nil), // 7: cortex.axon.UnregisterHandlerResponse
-	(*ListHandlersRequest)(nil),       // 8: cortex.axon.ListHandlersRequest
-	(*HandlerInfo)(nil),               // 9: cortex.axon.HandlerInfo
-	(*ListHandlersResponse)(nil),      // 10: cortex.axon.ListHandlersResponse
-	(*DispatchRequest)(nil),           // 11: cortex.axon.DispatchRequest
-	(*DispatchMessage)(nil),           // 12: cortex.axon.DispatchMessage
-	(*DispatchHandlerInvoke)(nil),     // 13: cortex.axon.DispatchHandlerInvoke
-	(*Log)(nil),                       // 14: cortex.axon.Log
-	(*ReportInvocationRequest)(nil),   // 15: cortex.axon.ReportInvocationRequest
-	(*ReportInvocationResponse)(nil),  // 16: cortex.axon.ReportInvocationResponse
-	(*InvokeResult)(nil),              // 17: cortex.axon.InvokeResult
-	(*GetHandlerHistoryRequest)(nil),  // 18: cortex.axon.GetHandlerHistoryRequest
-	(*HandlerExecution)(nil),          // 19: cortex.axon.HandlerExecution
-	(*GetHandlerHistoryResponse)(nil), // 20: cortex.axon.GetHandlerHistoryResponse
-	nil,                               // 21: cortex.axon.DispatchHandlerInvoke.ArgsEntry
-	(*Error)(nil),                     // 22: cortex.axon.Error
-	(*timestamppb.Timestamp)(nil),     // 23: google.protobuf.Timestamp
+	(WorkerPoolStrategy)(0),               // 0: cortex.axon.WorkerPoolStrategy
+	(HandlerInvokeType)(0),                // 1: cortex.axon.HandlerInvokeType
+	(HandlerOverflowPolicy)(0),            // 2: cortex.axon.HandlerOverflowPolicy
+	(HandlerMisfirePolicy)(0),             // 3: cortex.axon.HandlerMisfirePolicy
+	(WebhookVerificationType)(0),          // 4: cortex.axon.WebhookVerificationType
+	(WebhookFilterSource)(0),              // 5: cortex.axon.WebhookFilterSource
+	(HandlerRunResult)(0),                 // 6: cortex.axon.HandlerRunResult
+	(InvocationState)(0),                  // 7: cortex.axon.InvocationState
+	(DispatchMessageType)(0),              // 8: cortex.axon.DispatchMessageType
+	(CancelReason)(0),                     // 9: cortex.axon.CancelReason
+	(HandlerExecutionEventType)(0),        // 10: cortex.axon.HandlerExecutionEventType
+	(*RegisterHandlerRequest)(nil),        // 11: cortex.axon.RegisterHandlerRequest
+	(*WorkerPool)(nil),                    // 12: cortex.axon.WorkerPool
+	(*HandlerInvokeOption)(nil),           // 13: cortex.axon.HandlerInvokeOption
+	(*HandlerRetryOption)(nil),            // 14: cortex.axon.HandlerRetryOption
+	(*HandlerConcurrencyOption)(nil),      // 15: cortex.axon.HandlerConcurrencyOption
+	(*HandlerScheduleOption)(nil),         // 16: cortex.axon.HandlerScheduleOption
+	(*WebhookVerification)(nil),           // 17: cortex.axon.WebhookVerification
+	(*WebhookFilter)(nil),                 // 18: cortex.axon.WebhookFilter
+	(*WebhookDedup)(nil),                  // 19: cortex.axon.WebhookDedup
+	(*HandlerWebhookOption)(nil),          // 20: cortex.axon.HandlerWebhookOption
+	(*WebhookResponse)(nil),               // 21: cortex.axon.WebhookResponse
+	(*HandlerDependencyOption)(nil),       // 22: cortex.axon.HandlerDependencyOption
+	(*HandlerRetentionOption)(nil),        // 23: cortex.axon.HandlerRetentionOption
+	(*HandlerOption)(nil),                 // 24: cortex.axon.HandlerOption
+	(*RegisterHandlerResponse)(nil),       // 25: cortex.axon.RegisterHandlerResponse
+	(*UnregisterHandlerRequest)(nil),      // 26: cortex.axon.UnregisterHandlerRequest
+	(*UnregisterHandlerResponse)(nil),     // 27: cortex.axon.UnregisterHandlerResponse
+	(*ListHandlersRequest)(nil),           // 28: cortex.axon.ListHandlersRequest
+	(*HandlerInfo)(nil),                   // 29: cortex.axon.HandlerInfo
+	(*ListHandlersResponse)(nil),          // 30: cortex.axon.ListHandlersResponse
+	(*PauseHandlerRequest)(nil),           // 31: cortex.axon.PauseHandlerRequest
+	(*PauseHandlerResponse)(nil),          // 32: cortex.axon.PauseHandlerResponse
+	(*ResumeHandlerRequest)(nil),          // 33: cortex.axon.ResumeHandlerRequest
+	(*ResumeHandlerResponse)(nil),         // 34: cortex.axon.ResumeHandlerResponse
+	(*TriggerHandlerRequest)(nil),         // 35: cortex.axon.TriggerHandlerRequest
+	(*TriggerHandlerResponse)(nil),        // 36: cortex.axon.TriggerHandlerResponse
+	(*CancelInvocationRequest)(nil),       // 37: cortex.axon.CancelInvocationRequest
+	(*CancelInvocationResponse)(nil),      // 38: cortex.axon.CancelInvocationResponse
+	(*ListInvocationsRequest)(nil),        // 39: cortex.axon.ListInvocationsRequest
+	(*InvocationInfo)(nil),                // 40: cortex.axon.InvocationInfo
+	(*ListInvocationsResponse)(nil),       // 41: cortex.axon.ListInvocationsResponse
+	(*DispatchRequest)(nil),               // 42: cortex.axon.DispatchRequest
+	(*DispatchMessage)(nil),               // 43: cortex.axon.DispatchMessage
+	(*DispatchCancel)(nil),                // 44: cortex.axon.DispatchCancel
+	(*DispatchHandlerInvoke)(nil),         // 45: cortex.axon.DispatchHandlerInvoke
+	(*Log)(nil),                           // 46: cortex.axon.Log
+	(*ReportInvocationRequest)(nil),       // 47: cortex.axon.ReportInvocationRequest
+	(*ReportInvocationResponse)(nil),      // 48: cortex.axon.ReportInvocationResponse
+	(*InvokeResult)(nil),                  // 49: cortex.axon.InvokeResult
+	(*GetHandlerHistoryRequest)(nil),      // 50: cortex.axon.GetHandlerHistoryRequest
+	(*HandlerExecution)(nil),              // 51: cortex.axon.HandlerExecution
+	(*GetHandlerHistoryResponse)(nil),     // 52: cortex.axon.GetHandlerHistoryResponse
+	(*WatchHandlerExecutionsRequest)(nil), // 53: cortex.axon.WatchHandlerExecutionsRequest
+	(*HandlerExecutionEvent)(nil),         // 54: cortex.axon.HandlerExecutionEvent
+	nil,                                   // 55: cortex.axon.WebhookResponse.HeadersEntry
+	nil,                                   // 56: cortex.axon.DispatchHandlerInvoke.ArgsEntry
+	nil,                                   // 57: cortex.axon.HandlerExecution.ArgsEntry
+	(*Error)(nil),                         // 58: cortex.axon.Error
+	(*timestamppb.Timestamp)(nil),         // 59: google.protobuf.Timestamp
 }
 var file_cortex_axon_agent_proto_depIdxs = []int32{
-	4,  // 0: cortex.axon.RegisterHandlerRequest.options:type_name -> cortex.axon.HandlerOption
-	0,  // 1: cortex.axon.HandlerInvokeOption.type:type_name -> cortex.axon.HandlerInvokeType
-	3,  // 2: cortex.axon.HandlerOption.invoke:type_name -> cortex.axon.HandlerInvokeOption
-	22, // 3: cortex.axon.RegisterHandlerResponse.error:type_name -> cortex.axon.Error
-	22, // 4: cortex.axon.UnregisterHandlerResponse.error:type_name -> cortex.axon.Error
-	4,  // 5: cortex.axon.HandlerInfo.options:type_name -> cortex.axon.HandlerOption
-	23, // 6: cortex.axon.HandlerInfo.last_invoked_client_timestamp:type_name -> google.protobuf.Timestamp
-	22, // 7: cortex.axon.ListHandlersResponse.error:type_name -> cortex.axon.Error
-	9,  // 8: cortex.axon.ListHandlersResponse.handlers:type_name -> cortex.axon.HandlerInfo
-	1,  // 9: cortex.axon.DispatchMessage.type:type_name -> cortex.axon.DispatchMessageType
-	13, // 10: cortex.axon.DispatchMessage.invoke:type_name -> cortex.axon.DispatchHandlerInvoke
-	0,  // 11: cortex.axon.DispatchHandlerInvoke.reason:type_name -> cortex.axon.HandlerInvokeType
-	21, // 12: cortex.axon.DispatchHandlerInvoke.args:type_name -> cortex.axon.DispatchHandlerInvoke.ArgsEntry
-	23, // 13: cortex.axon.Log.timestamp:type_name -> google.protobuf.Timestamp
-	13, // 14: cortex.axon.ReportInvocationRequest.handler_invoke:type_name -> cortex.axon.DispatchHandlerInvoke
-	23, // 15: cortex.axon.ReportInvocationRequest.start_client_timestamp:type_name -> google.protobuf.Timestamp
-	17, // 16: cortex.axon.ReportInvocationRequest.result:type_name -> cortex.axon.InvokeResult
-	22, // 17: cortex.axon.ReportInvocationRequest.error:type_name -> cortex.axon.Error
-	14, // 18: cortex.axon.ReportInvocationRequest.logs:type_name -> cortex.axon.Log
-	22, // 19: cortex.axon.ReportInvocationResponse.error:type_name -> cortex.axon.Error
-	23, // 20: cortex.axon.GetHandlerHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
-	23, // 21: cortex.axon.GetHandlerHistoryRequest.end_time:type_name -> google.protobuf.Timestamp
-	23, // 22: cortex.axon.HandlerExecution.publish_server_timestamp:type_name -> google.protobuf.Timestamp
-	23, // 23: cortex.axon.HandlerExecution.receive_server_timestamp:type_name -> google.protobuf.Timestamp
-	23, // 24: cortex.axon.HandlerExecution.start_client_timestamp:type_name -> google.protobuf.Timestamp
-	22, // 25: cortex.axon.HandlerExecution.error:type_name -> cortex.axon.Error
-	14, // 26: cortex.axon.HandlerExecution.logs:type_name -> cortex.axon.Log
-	22, // 27: cortex.axon.GetHandlerHistoryResponse.error:type_name -> cortex.axon.Error
-	19, // 28: cortex.axon.GetHandlerHistoryResponse.history:type_name -> cortex.axon.HandlerExecution
-	2,  // 29: cortex.axon.AxonAgent.RegisterHandler:input_type -> cortex.axon.RegisterHandlerRequest
-	6,  // 30: cortex.axon.AxonAgent.UnregisterHandler:input_type -> cortex.axon.UnregisterHandlerRequest
-	8,  // 31: cortex.axon.AxonAgent.ListHandlers:input_type -> cortex.axon.ListHandlersRequest
-	18, // 32: cortex.axon.AxonAgent.GetHandlerHistory:input_type -> cortex.axon.GetHandlerHistoryRequest
-	11, // 33: cortex.axon.AxonAgent.Dispatch:input_type -> cortex.axon.DispatchRequest
-	15, // 34: cortex.axon.AxonAgent.ReportInvocation:input_type -> cortex.axon.ReportInvocationRequest
-	5,  // 35: cortex.axon.AxonAgent.RegisterHandler:output_type -> cortex.axon.RegisterHandlerResponse
-	7,  // 36: cortex.axon.AxonAgent.UnregisterHandler:output_type -> cortex.axon.UnregisterHandlerResponse
-	10, // 37: cortex.axon.AxonAgent.ListHandlers:output_type -> cortex.axon.ListHandlersResponse
-	20, // 38: cortex.axon.AxonAgent.GetHandlerHistory:output_type -> cortex.axon.GetHandlerHistoryResponse
-	12, // 39: cortex.axon.AxonAgent.Dispatch:output_type -> cortex.axon.DispatchMessage
-	16, // 40: cortex.axon.AxonAgent.ReportInvocation:output_type -> cortex.axon.ReportInvocationResponse
-	35, // [35:41] is the sub-list for method output_type
-	29, // [29:35] is the sub-list for method input_type
-	29, // [29:29] is the sub-list for extension type_name
-	29, // [29:29] is the sub-list for extension extendee
-	0,  // [0:29] is the sub-list for field type_name
+	24, // 0: cortex.axon.RegisterHandlerRequest.options:type_name -> cortex.axon.HandlerOption
+	12, // 1: cortex.axon.RegisterHandlerRequest.worker_pool:type_name -> cortex.axon.WorkerPool
+	0,  // 2: cortex.axon.WorkerPool.strategy:type_name -> cortex.axon.WorkerPoolStrategy
+	1,  // 3: cortex.axon.HandlerInvokeOption.type:type_name -> cortex.axon.HandlerInvokeType
+	2,  // 4: cortex.axon.HandlerConcurrencyOption.overflow:type_name -> cortex.axon.HandlerOverflowPolicy
+	3,  // 5: cortex.axon.HandlerScheduleOption.misfire_policy:type_name -> cortex.axon.HandlerMisfirePolicy
+	4,  // 6: cortex.axon.WebhookVerification.type:type_name -> cortex.axon.WebhookVerificationType
+	5,  // 7: cortex.axon.WebhookFilter.source:type_name -> cortex.axon.WebhookFilterSource
+	17, // 8: cortex.axon.HandlerWebhookOption.verification:type_name -> cortex.axon.WebhookVerification
+	18, // 9: cortex.axon.HandlerWebhookOption.filters:type_name -> cortex.axon.WebhookFilter
+	19, // 10: cortex.axon.HandlerWebhookOption.dedup:type_name -> cortex.axon.WebhookDedup
+	55, // 11: cortex.axon.WebhookResponse.headers:type_name -> cortex.axon.WebhookResponse.HeadersEntry
+	13, // 12: cortex.axon.HandlerOption.invoke:type_name -> cortex.axon.HandlerInvokeOption
+	14, // 13: cortex.axon.HandlerOption.retry:type_name -> cortex.axon.HandlerRetryOption
+	15, // 14: cortex.axon.HandlerOption.concurrency:type_name -> cortex.axon.HandlerConcurrencyOption
+	20, // 15: cortex.axon.HandlerOption.webhook:type_name -> cortex.axon.HandlerWebhookOption
+	16, // 16: cortex.axon.HandlerOption.schedule:type_name -> cortex.axon.HandlerScheduleOption
+	22, // 17: cortex.axon.HandlerOption.dependency:type_name -> cortex.axon.HandlerDependencyOption
+	23, // 18: cortex.axon.HandlerOption.retention:type_name -> cortex.axon.HandlerRetentionOption
+	58, // 19: cortex.axon.RegisterHandlerResponse.error:type_name -> cortex.axon.Error
+	58, // 20: cortex.axon.UnregisterHandlerResponse.error:type_name -> cortex.axon.Error
+	24, // 21: cortex.axon.HandlerInfo.options:type_name -> cortex.axon.HandlerOption
+	59, // 22: cortex.axon.HandlerInfo.last_invoked_client_timestamp:type_name -> google.protobuf.Timestamp
+	59, // 23: cortex.axon.HandlerInfo.next_run_timestamp:type_name -> google.protobuf.Timestamp
+	6,  // 24: cortex.axon.HandlerInfo.last_result:type_name -> cortex.axon.HandlerRunResult
+	58, // 25: cortex.axon.HandlerInfo.last_error:type_name -> cortex.axon.Error
+	58, // 26: cortex.axon.ListHandlersResponse.error:type_name -> cortex.axon.Error
+	29, // 27: cortex.axon.ListHandlersResponse.handlers:type_name -> cortex.axon.HandlerInfo
+	58, // 28: cortex.axon.PauseHandlerResponse.error:type_name -> cortex.axon.Error
+	58, // 29: cortex.axon.ResumeHandlerResponse.error:type_name -> cortex.axon.Error
+	58, // 30: cortex.axon.TriggerHandlerResponse.error:type_name -> cortex.axon.Error
+	58, // 31: cortex.axon.CancelInvocationResponse.error:type_name -> cortex.axon.Error
+	1,  // 32: cortex.axon.InvocationInfo.reason:type_name -> cortex.axon.HandlerInvokeType
+	7,  // 33: cortex.axon.InvocationInfo.state:type_name -> cortex.axon.InvocationState
+	59, // 34: cortex.axon.InvocationInfo.queued_timestamp:type_name -> google.protobuf.Timestamp
+	59, // 35: cortex.axon.InvocationInfo.sent_timestamp:type_name -> google.protobuf.Timestamp
+	59, // 36: cortex.axon.InvocationInfo.completed_timestamp:type_name -> google.protobuf.Timestamp
+	58, // 37: cortex.axon.InvocationInfo.error:type_name -> cortex.axon.Error
+	58, // 38: cortex.axon.ListInvocationsResponse.error:type_name -> cortex.axon.Error
+	40, // 39: cortex.axon.ListInvocationsResponse.invocations:type_name -> cortex.axon.InvocationInfo
+	8,  // 40: cortex.axon.DispatchMessage.type:type_name -> cortex.axon.DispatchMessageType
+	45, // 41: cortex.axon.DispatchMessage.invoke:type_name -> cortex.axon.DispatchHandlerInvoke
+	44, // 42: cortex.axon.DispatchMessage.cancel:type_name -> cortex.axon.DispatchCancel
+	9,  // 43: cortex.axon.DispatchCancel.reason:type_name -> cortex.axon.CancelReason
+	1,  // 44: cortex.axon.DispatchHandlerInvoke.reason:type_name -> cortex.axon.HandlerInvokeType
+	56, // 45: cortex.axon.DispatchHandlerInvoke.args:type_name -> cortex.axon.DispatchHandlerInvoke.ArgsEntry
+	59, // 46: cortex.axon.Log.timestamp:type_name -> google.protobuf.Timestamp
+	45, // 47: cortex.axon.ReportInvocationRequest.handler_invoke:type_name -> cortex.axon.DispatchHandlerInvoke
+	59, // 48: cortex.axon.ReportInvocationRequest.start_client_timestamp:type_name -> google.protobuf.Timestamp
+	49, // 49: cortex.axon.ReportInvocationRequest.result:type_name -> cortex.axon.InvokeResult
+	58, // 50: cortex.axon.ReportInvocationRequest.error:type_name -> cortex.axon.Error
+	46, // 51: cortex.axon.ReportInvocationRequest.logs:type_name -> cortex.axon.Log
+	58, // 52: cortex.axon.ReportInvocationResponse.error:type_name -> cortex.axon.Error
+	59, // 53: cortex.axon.GetHandlerHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
+	59, // 54: cortex.axon.GetHandlerHistoryRequest.end_time:type_name -> google.protobuf.Timestamp
+	6,  // 55: cortex.axon.GetHandlerHistoryRequest.result:type_name -> cortex.axon.HandlerRunResult
+	1,  // 56: cortex.axon.HandlerExecution.reason:type_name -> cortex.axon.HandlerInvokeType
+	57, // 57: cortex.axon.HandlerExecution.args:type_name -> cortex.axon.HandlerExecution.ArgsEntry
+	59, // 58: cortex.axon.HandlerExecution.publish_server_timestamp:type_name -> google.protobuf.Timestamp
+	59, // 59: cortex.axon.HandlerExecution.receive_server_timestamp:type_name -> google.protobuf.Timestamp
+	59, // 60: cortex.axon.HandlerExecution.start_client_timestamp:type_name -> google.protobuf.Timestamp
+	58, // 61: cortex.axon.HandlerExecution.error:type_name -> cortex.axon.Error
+	46, // 62: cortex.axon.HandlerExecution.logs:type_name -> cortex.axon.Log
+	58, // 63: cortex.axon.GetHandlerHistoryResponse.error:type_name -> cortex.axon.Error
+	51, // 64: cortex.axon.GetHandlerHistoryResponse.history:type_name -> cortex.axon.HandlerExecution
+	10, // 65: cortex.axon.HandlerExecutionEvent.type:type_name -> cortex.axon.HandlerExecutionEventType
+	59, // 66: cortex.axon.HandlerExecutionEvent.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 67: cortex.axon.HandlerExecutionEvent.reason:type_name -> cortex.axon.HandlerInvokeType
+	51, // 68: cortex.axon.HandlerExecutionEvent.execution:type_name -> cortex.axon.HandlerExecution
+	46, // 69: cortex.axon.HandlerExecutionEvent.log:type_name -> cortex.axon.Log
+	11, // 70: cortex.axon.AxonAgent.RegisterHandler:input_type -> cortex.axon.RegisterHandlerRequest
+	26, // 71: cortex.axon.AxonAgent.UnregisterHandler:input_type -> cortex.axon.UnregisterHandlerRequest
+	28, // 72: cortex.axon.AxonAgent.ListHandlers:input_type -> cortex.axon.ListHandlersRequest
+	50, // 73: cortex.axon.AxonAgent.GetHandlerHistory:input_type -> cortex.axon.GetHandlerHistoryRequest
+	42, // 74: cortex.axon.AxonAgent.Dispatch:input_type -> cortex.axon.DispatchRequest
+	47, // 75: cortex.axon.AxonAgent.ReportInvocation:input_type -> cortex.axon.ReportInvocationRequest
+	31, // 76: cortex.axon.AxonAgent.PauseHandler:input_type -> cortex.axon.PauseHandlerRequest
+	33, // 77: cortex.axon.AxonAgent.ResumeHandler:input_type -> cortex.axon.ResumeHandlerRequest
+	35, // 78: cortex.axon.AxonAgent.TriggerHandler:input_type -> cortex.axon.TriggerHandlerRequest
+	37, // 79: cortex.axon.AxonAgent.CancelInvocation:input_type -> cortex.axon.CancelInvocationRequest
+	39, // 80: cortex.axon.AxonAgent.ListInvocations:input_type -> cortex.axon.ListInvocationsRequest
+	53, // 81: cortex.axon.AxonAgent.WatchHandlerExecutions:input_type -> cortex.axon.WatchHandlerExecutionsRequest
+	25, // 82: cortex.axon.AxonAgent.RegisterHandler:output_type -> cortex.axon.RegisterHandlerResponse
+	27, // 83: cortex.axon.AxonAgent.UnregisterHandler:output_type -> cortex.axon.UnregisterHandlerResponse
+	30, // 84: cortex.axon.AxonAgent.ListHandlers:output_type -> cortex.axon.ListHandlersResponse
+	52, // 85: cortex.axon.AxonAgent.GetHandlerHistory:output_type -> cortex.axon.GetHandlerHistoryResponse
+	43, // 86: cortex.axon.AxonAgent.Dispatch:output_type -> cortex.axon.DispatchMessage
+	48, // 87: cortex.axon.AxonAgent.ReportInvocation:output_type -> cortex.axon.ReportInvocationResponse
+	32, // 88: cortex.axon.AxonAgent.PauseHandler:output_type -> cortex.axon.PauseHandlerResponse
+	34, // 89: cortex.axon.AxonAgent.ResumeHandler:output_type -> cortex.axon.ResumeHandlerResponse
+	36, // 90: cortex.axon.AxonAgent.TriggerHandler:output_type -> cortex.axon.TriggerHandlerResponse
+	38, // 91: cortex.axon.AxonAgent.CancelInvocation:output_type -> cortex.axon.CancelInvocationResponse
+	41, // 92: cortex.axon.AxonAgent.ListInvocations:output_type -> cortex.axon.ListInvocationsResponse
+	54, // 93: cortex.axon.AxonAgent.WatchHandlerExecutions:output_type -> cortex.axon.HandlerExecutionEvent
+	82, // [82:94] is the sub-list for method output_type
+	70, // [70:82] is the sub-list for method input_type
+	70, // [70:70] is the sub-list for extension type_name
+	70, // [70:70] is the sub-list for extension extendee
+	0,  // [0:70] is the sub-list for field type_name
 }
 
 func init() { file_cortex_axon_agent_proto_init() }
@@ -1566,13 +4297,20 @@ func file_cortex_axon_agent_proto_init() {
 		return
 	}
 	file_common_proto_init()
-	file_cortex_axon_agent_proto_msgTypes[2].OneofWrappers = []any{
+	file_cortex_axon_agent_proto_msgTypes[13].OneofWrappers = []any{
 		(*HandlerOption_Invoke)(nil),
+		(*HandlerOption_Retry)(nil),
+		(*HandlerOption_Concurrency)(nil),
+		(*HandlerOption_Webhook)(nil),
+		(*HandlerOption_Schedule)(nil),
+		(*HandlerOption_Dependency)(nil),
+		(*HandlerOption_Retention)(nil),
 	}
-	file_cortex_axon_agent_proto_msgTypes[10].OneofWrappers = []any{
+	file_cortex_axon_agent_proto_msgTypes[32].OneofWrappers = []any{
 		(*DispatchMessage_Invoke)(nil),
+		(*DispatchMessage_Cancel)(nil),
 	}
-	file_cortex_axon_agent_proto_msgTypes[13].OneofWrappers = []any{
+	file_cortex_axon_agent_proto_msgTypes[36].OneofWrappers = []any{
 		(*ReportInvocationRequest_Result)(nil),
 		(*ReportInvocationRequest_Error)(nil),
 	}
@@ -1581,8 +4319,8 @@ func file_cortex_axon_agent_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cortex_axon_agent_proto_rawDesc), len(file_cortex_axon_agent_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   20,
+			NumEnums:      11,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
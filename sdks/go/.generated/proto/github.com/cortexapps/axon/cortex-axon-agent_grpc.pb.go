@@ -19,12 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AxonAgent_RegisterHandler_FullMethodName   = "/cortex.axon.AxonAgent/RegisterHandler"
-	AxonAgent_UnregisterHandler_FullMethodName = "/cortex.axon.AxonAgent/UnregisterHandler"
-	AxonAgent_ListHandlers_FullMethodName      = "/cortex.axon.AxonAgent/ListHandlers"
-	AxonAgent_GetHandlerHistory_FullMethodName = "/cortex.axon.AxonAgent/GetHandlerHistory"
-	AxonAgent_Dispatch_FullMethodName          = "/cortex.axon.AxonAgent/Dispatch"
-	AxonAgent_ReportInvocation_FullMethodName  = "/cortex.axon.AxonAgent/ReportInvocation"
+	AxonAgent_RegisterHandler_FullMethodName        = "/cortex.axon.AxonAgent/RegisterHandler"
+	AxonAgent_UnregisterHandler_FullMethodName      = "/cortex.axon.AxonAgent/UnregisterHandler"
+	AxonAgent_ListHandlers_FullMethodName           = "/cortex.axon.AxonAgent/ListHandlers"
+	AxonAgent_GetHandlerHistory_FullMethodName      = "/cortex.axon.AxonAgent/GetHandlerHistory"
+	AxonAgent_Dispatch_FullMethodName               = "/cortex.axon.AxonAgent/Dispatch"
+	AxonAgent_ReportInvocation_FullMethodName       = "/cortex.axon.AxonAgent/ReportInvocation"
+	AxonAgent_PauseHandler_FullMethodName           = "/cortex.axon.AxonAgent/PauseHandler"
+	AxonAgent_ResumeHandler_FullMethodName          = "/cortex.axon.AxonAgent/ResumeHandler"
+	AxonAgent_TriggerHandler_FullMethodName         = "/cortex.axon.AxonAgent/TriggerHandler"
+	AxonAgent_CancelInvocation_FullMethodName       = "/cortex.axon.AxonAgent/CancelInvocation"
+	AxonAgent_ListInvocations_FullMethodName        = "/cortex.axon.AxonAgent/ListInvocations"
+	AxonAgent_WatchHandlerExecutions_FullMethodName = "/cortex.axon.AxonAgent/WatchHandlerExecutions"
 )
 
 // AxonAgentClient is the client API for AxonAgent service.
@@ -37,6 +43,12 @@ type AxonAgentClient interface {
 	GetHandlerHistory(ctx context.Context, in *GetHandlerHistoryRequest, opts ...grpc.CallOption) (*GetHandlerHistoryResponse, error)
 	Dispatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DispatchRequest, DispatchMessage], error)
 	ReportInvocation(ctx context.Context, in *ReportInvocationRequest, opts ...grpc.CallOption) (*ReportInvocationResponse, error)
+	PauseHandler(ctx context.Context, in *PauseHandlerRequest, opts ...grpc.CallOption) (*PauseHandlerResponse, error)
+	ResumeHandler(ctx context.Context, in *ResumeHandlerRequest, opts ...grpc.CallOption) (*ResumeHandlerResponse, error)
+	TriggerHandler(ctx context.Context, in *TriggerHandlerRequest, opts ...grpc.CallOption) (*TriggerHandlerResponse, error)
+	CancelInvocation(ctx context.Context, in *CancelInvocationRequest, opts ...grpc.CallOption) (*CancelInvocationResponse, error)
+	ListInvocations(ctx context.Context, in *ListInvocationsRequest, opts ...grpc.CallOption) (*ListInvocationsResponse, error)
+	WatchHandlerExecutions(ctx context.Context, in *WatchHandlerExecutionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HandlerExecutionEvent], error)
 }
 
 type axonAgentClient struct {
@@ -110,6 +122,75 @@ func (c *axonAgentClient) ReportInvocation(ctx context.Context, in *ReportInvoca
 	return out, nil
 }
 
+func (c *axonAgentClient) PauseHandler(ctx context.Context, in *PauseHandlerRequest, opts ...grpc.CallOption) (*PauseHandlerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseHandlerResponse)
+	err := c.cc.Invoke(ctx, AxonAgent_PauseHandler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *axonAgentClient) ResumeHandler(ctx context.Context, in *ResumeHandlerRequest, opts ...grpc.CallOption) (*ResumeHandlerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeHandlerResponse)
+	err := c.cc.Invoke(ctx, AxonAgent_ResumeHandler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *axonAgentClient) TriggerHandler(ctx context.Context, in *TriggerHandlerRequest, opts ...grpc.CallOption) (*TriggerHandlerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerHandlerResponse)
+	err := c.cc.Invoke(ctx, AxonAgent_TriggerHandler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *axonAgentClient) CancelInvocation(ctx context.Context, in *CancelInvocationRequest, opts ...grpc.CallOption) (*CancelInvocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelInvocationResponse)
+	err := c.cc.Invoke(ctx, AxonAgent_CancelInvocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *axonAgentClient) ListInvocations(ctx context.Context, in *ListInvocationsRequest, opts ...grpc.CallOption) (*ListInvocationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInvocationsResponse)
+	err := c.cc.Invoke(ctx, AxonAgent_ListInvocations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *axonAgentClient) WatchHandlerExecutions(ctx context.Context, in *WatchHandlerExecutionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HandlerExecutionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AxonAgent_ServiceDesc.Streams[1], AxonAgent_WatchHandlerExecutions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchHandlerExecutionsRequest, HandlerExecutionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AxonAgent_WatchHandlerExecutionsClient = grpc.ServerStreamingClient[HandlerExecutionEvent]
+
 // AxonAgentServer is the server API for AxonAgent service.
 // All implementations must embed UnimplementedAxonAgentServer
 // for forward compatibility.
@@ -120,6 +201,12 @@ type AxonAgentServer interface {
 	GetHandlerHistory(context.Context, *GetHandlerHistoryRequest) (*GetHandlerHistoryResponse, error)
 	Dispatch(grpc.BidiStreamingServer[DispatchRequest, DispatchMessage]) error
 	ReportInvocation(context.Context, *ReportInvocationRequest) (*ReportInvocationResponse, error)
+	PauseHandler(context.Context, *PauseHandlerRequest) (*PauseHandlerResponse, error)
+	ResumeHandler(context.Context, *ResumeHandlerRequest) (*ResumeHandlerResponse, error)
+	TriggerHandler(context.Context, *TriggerHandlerRequest) (*TriggerHandlerResponse, error)
+	CancelInvocation(context.Context, *CancelInvocationRequest) (*CancelInvocationResponse, error)
+	ListInvocations(context.Context, *ListInvocationsRequest) (*ListInvocationsResponse, error)
+	WatchHandlerExecutions(*WatchHandlerExecutionsRequest, grpc.ServerStreamingServer[HandlerExecutionEvent]) error
 	mustEmbedUnimplementedAxonAgentServer()
 }
 
@@ -148,6 +235,24 @@ func (UnimplementedAxonAgentServer) Dispatch(grpc.BidiStreamingServer[DispatchRe
 func (UnimplementedAxonAgentServer) ReportInvocation(context.Context, *ReportInvocationRequest) (*ReportInvocationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReportInvocation not implemented")
 }
+func (UnimplementedAxonAgentServer) PauseHandler(context.Context, *PauseHandlerRequest) (*PauseHandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseHandler not implemented")
+}
+func (UnimplementedAxonAgentServer) ResumeHandler(context.Context, *ResumeHandlerRequest) (*ResumeHandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeHandler not implemented")
+}
+func (UnimplementedAxonAgentServer) TriggerHandler(context.Context, *TriggerHandlerRequest) (*TriggerHandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerHandler not implemented")
+}
+func (UnimplementedAxonAgentServer) CancelInvocation(context.Context, *CancelInvocationRequest) (*CancelInvocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelInvocation not implemented")
+}
+func (UnimplementedAxonAgentServer) ListInvocations(context.Context, *ListInvocationsRequest) (*ListInvocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInvocations not implemented")
+}
+func (UnimplementedAxonAgentServer) WatchHandlerExecutions(*WatchHandlerExecutionsRequest, grpc.ServerStreamingServer[HandlerExecutionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchHandlerExecutions not implemented")
+}
 func (UnimplementedAxonAgentServer) mustEmbedUnimplementedAxonAgentServer() {}
 func (UnimplementedAxonAgentServer) testEmbeddedByValue()                   {}
 
@@ -266,6 +371,107 @@ func _AxonAgent_ReportInvocation_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AxonAgent_PauseHandler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseHandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AxonAgentServer).PauseHandler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AxonAgent_PauseHandler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AxonAgentServer).PauseHandler(ctx, req.(*PauseHandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AxonAgent_ResumeHandler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeHandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AxonAgentServer).ResumeHandler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AxonAgent_ResumeHandler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AxonAgentServer).ResumeHandler(ctx, req.(*ResumeHandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AxonAgent_TriggerHandler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerHandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AxonAgentServer).TriggerHandler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AxonAgent_TriggerHandler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AxonAgentServer).TriggerHandler(ctx, req.(*TriggerHandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AxonAgent_CancelInvocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelInvocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AxonAgentServer).CancelInvocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AxonAgent_CancelInvocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AxonAgentServer).CancelInvocation(ctx, req.(*CancelInvocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AxonAgent_ListInvocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInvocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AxonAgentServer).ListInvocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AxonAgent_ListInvocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AxonAgentServer).ListInvocations(ctx, req.(*ListInvocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AxonAgent_WatchHandlerExecutions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchHandlerExecutionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AxonAgentServer).WatchHandlerExecutions(m, &grpc.GenericServerStream[WatchHandlerExecutionsRequest, HandlerExecutionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AxonAgent_WatchHandlerExecutionsServer = grpc.ServerStreamingServer[HandlerExecutionEvent]
+
 // AxonAgent_ServiceDesc is the grpc.ServiceDesc for AxonAgent service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -293,6 +499,26 @@ var AxonAgent_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportInvocation",
 			Handler:    _AxonAgent_ReportInvocation_Handler,
 		},
+		{
+			MethodName: "PauseHandler",
+			Handler:    _AxonAgent_PauseHandler_Handler,
+		},
+		{
+			MethodName: "ResumeHandler",
+			Handler:    _AxonAgent_ResumeHandler_Handler,
+		},
+		{
+			MethodName: "TriggerHandler",
+			Handler:    _AxonAgent_TriggerHandler_Handler,
+		},
+		{
+			MethodName: "CancelInvocation",
+			Handler:    _AxonAgent_CancelInvocation_Handler,
+		},
+		{
+			MethodName: "ListInvocations",
+			Handler:    _AxonAgent_ListInvocations_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -301,6 +527,11 @@ var AxonAgent_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchHandlerExecutions",
+			Handler:       _AxonAgent_WatchHandlerExecutions_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "cortex-axon-agent.proto",
 }
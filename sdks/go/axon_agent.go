@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +22,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -35,7 +38,11 @@ type Agent struct {
 
 	logger       *zap.Logger
 	sleepOnError time.Duration
+	workerPool   *pb.WorkerPool
 	done         chan struct{}
+
+	runningLock sync.Mutex
+	running     map[string]context.CancelCauseFunc
 }
 
 // NewAxonAgent creates a new AxonAgent with the specified options.  You
@@ -57,12 +64,14 @@ func NewAxonAgent(options ...Option) *Agent {
 		DispatchId:   uuid.New().String(),
 		logger:       logger,
 		sleepOnError: ao.sleepOnError,
+		workerPool:   ao.workerPool,
 		done:         make(chan struct{}),
 	}
 
 	a.logger = logger
 	a.client = newGrpcClient(ao.host, ao.port, logger)
 	a.registeredHandlers = make(map[string]*handlerInfo)
+	a.running = make(map[string]context.CancelCauseFunc)
 	return a
 }
 
@@ -100,9 +109,213 @@ func WithInvokeOption(invokeType pb.HandlerInvokeType, value string) RegisterHan
 	}
 }
 
+// WithRetryPolicy asks the agent to re-dispatch a failed invocation up to maxAttempts
+// times in total, waiting initialBackoff before the first retry and doubling it after
+// each one.  If retryOn is given only failures with one of those error codes are retried.
+func WithRetryPolicy(maxAttempts int, initialBackoff time.Duration, retryOn ...string) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Retry{
+					Retry: &pb.HandlerRetryOption{
+						MaxAttempts:      int32(maxAttempts),
+						InitialBackoffMs: int32(initialBackoff.Milliseconds()),
+						RetryOn:          retryOn,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithMaxConcurrency limits how many invocations of the handler run at once.  A
+// trigger that arrives at the limit is skipped, or with OVERFLOW_QUEUE_ONE run once
+// a running invocation finishes, holding at most one such trigger.
+func WithMaxConcurrency(maxConcurrent int, overflow pb.HandlerOverflowPolicy) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Concurrency{
+					Concurrency: &pb.HandlerConcurrencyOption{
+						MaxConcurrent: int32(maxConcurrent),
+						Overflow:      overflow,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithTimezone evaluates the handler's cron schedule in the given IANA time zone, such
+// as "America/New_York", instead of the agent's local time.  A CRON_TZ= prefix in the
+// schedule itself takes precedence.
+func WithTimezone(timezone string) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Schedule{
+					Schedule: &pb.HandlerScheduleOption{
+						Timezone: timezone,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithJitter delays each scheduled run of the handler by a random amount up to jitter,
+// so that many agents on the same schedule don't all run at the same moment.
+func WithJitter(jitter time.Duration) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Schedule{
+					Schedule: &pb.HandlerScheduleOption{
+						JitterMs: int32(jitter.Milliseconds()),
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithMisfirePolicy decides what happens to cron runs the handler missed while the
+// agent was down, based on its last run in the agent's history: they are skipped
+// (the default), run once, or each run, up to maxRuns of the most recent.  Catch-up
+// runs have the reason CATCH_UP and the missed time in the "scheduled-time" arg.
+func WithMisfirePolicy(policy pb.HandlerMisfirePolicy, maxRuns int) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Schedule{
+					Schedule: &pb.HandlerScheduleOption{
+						MisfirePolicy:  policy,
+						MaxCatchUpRuns: int32(maxRuns),
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithDependency runs the handler each time the handler named handlerName
+// completes successfully, with the reason UPSTREAM and the upstream handler and
+// invocation id in the "upstream-handler" and "upstream-invocation-id" args.  If
+// passResult is set, the upstream result is passed in the "upstream-result" arg.
+func WithDependency(handlerName string, passResult bool) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Dependency{
+					Dependency: &pb.HandlerDependencyOption{
+						HandlerName: handlerName,
+						PassResult:  passResult,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithHistoryRetention limits how much of the handler's history the agent
+// keeps, instead of the agent's limits for all handlers: executions older than
+// maxAge, beyond the maxCount most recent, or beyond the most recent that fit
+// in maxBytes are removed, while the minCount most recent are always kept.
+// Zero leaves a limit to the agent.
+func WithHistoryRetention(maxAge time.Duration, maxCount int, maxBytes int64, minCount int) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Retention{
+					Retention: &pb.HandlerRetentionOption{
+						MaxAgeMs: maxAge.Milliseconds(),
+						MaxCount: int32(maxCount),
+						MaxBytes: maxBytes,
+						MinCount: int32(minCount),
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithWebhookVerification has the agent reject webhook requests that aren't signed
+// with a shared secret, read from the environment variable or plugin named in
+// verification.  Rejected requests get a 401 and never reach the handler.
+func WithWebhookVerification(verification *pb.WebhookVerification) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Webhook{
+					Webhook: &pb.HandlerWebhookOption{
+						Verification: verification,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithRedactHeaders hides the values of the given request headers from the webhook
+// handler, in addition to those the agent redacts for every webhook.
+func WithRedactHeaders(headers ...string) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Webhook{
+					Webhook: &pb.HandlerWebhookOption{
+						RedactHeaders: headers,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithWebhookFilters has the agent trigger the webhook handler only for requests that
+// match all of filters.  Other requests are acknowledged without invoking it.
+func WithWebhookFilters(filters ...*pb.WebhookFilter) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Webhook{
+					Webhook: &pb.HandlerWebhookOption{
+						Filters: filters,
+					},
+				},
+			},
+		)
+	}
+}
+
+// WithWebhookDedup has the agent drop repeated deliveries of a webhook request seen
+// within window, identifying requests by the value of header, or if header is empty
+// or missing, by a hash of the body.  Duplicates are acknowledged with a 200 without
+// invoking the handler.
+func WithWebhookDedup(header string, window time.Duration) RegisterHandlerOption {
+	return func(o *registerHandlerOptions) {
+		o.handlerOptions = append(o.handlerOptions,
+			&pb.HandlerOption{
+				Option: &pb.HandlerOption_Webhook{
+					Webhook: &pb.HandlerWebhookOption{
+						Dedup: &pb.WebhookDedup{
+							Header:        header,
+							HashBody:      true,
+							WindowSeconds: int32(window.Seconds()),
+						},
+					},
+				},
+			},
+		)
+	}
+}
+
 type Handler = func(HandlerContext) error
 type InvocableHandler = func(HandlerContext) (any, error)
 
+// WebhookHandler answers a synchronous webhook with the HTTP response for its caller.
+type WebhookHandler = func(HandlerContext) (*pb.WebhookResponse, error)
+
 type handlerInfo struct {
 	dispatchId string
 	name       string
@@ -155,7 +368,51 @@ func (a *Agent) RegisterInvocableHandler(handler InvocableHandler, invokeOptions
 	for _, opt := range invokeOptions {
 		opt(opts)
 	}
-	opts.handlerOptions = nil
+	// invocable handlers are only triggered by explicit invocation
+	opts.handlerOptions = slices.DeleteFunc(opts.handlerOptions, func(o *pb.HandlerOption) bool {
+		return o.GetInvoke() != nil
+	})
+
+	info := &handlerInfo{
+		dispatchId: a.DispatchId,
+		name:       name,
+		options:    opts.handlerOptions,
+		handler:    handler,
+		timeout:    opts.timeout,
+	}
+	a.handlers = append(a.handlers, info)
+	return a.registerHandler(info)
+}
+
+// RegisterWebhookHandler registers handler for requests to the agent's /webhook/{webhookId}
+// endpoint.  The request is held open until the handler returns, so its response can carry
+// a computed status, headers and body, as needed for handshakes like Slack URL verification.
+// The caller gets a 504 if the handler takes longer than responseTimeout, or the handler
+// timeout if responseTimeout is zero.
+func (a *Agent) RegisterWebhookHandler(handler WebhookHandler, webhookId string, responseTimeout time.Duration, invokeOptions ...RegisterHandlerOption) (string, error) {
+
+	name := a.getHandlerName(handler)
+
+	for _, h := range a.handlers {
+		if h.name == name {
+			return "", fmt.Errorf("handler %s already registered", name)
+		}
+	}
+
+	opts := &registerHandlerOptions{}
+
+	invokeOptions = append(invokeOptions, WithInvokeOption(pb.HandlerInvokeType_WEBHOOK, webhookId))
+	for _, opt := range invokeOptions {
+		opt(opts)
+	}
+	opts.handlerOptions = append(opts.handlerOptions, &pb.HandlerOption{
+		Option: &pb.HandlerOption_Webhook{
+			Webhook: &pb.HandlerWebhookOption{
+				Synchronous:       true,
+				ResponseTimeoutMs: int32(responseTimeout.Milliseconds()),
+			},
+		},
+	})
 
 	info := &handlerInfo{
 		dispatchId: a.DispatchId,
@@ -180,6 +437,7 @@ func (a *Agent) registerHandler(info *handlerInfo) (string, error) {
 		HandlerName: info.name,
 		TimeoutMs:   int32(info.timeout.Milliseconds()),
 		Options:     info.options,
+		WorkerPool:  a.workerPool,
 	})
 
 	if err != nil {
@@ -208,7 +466,7 @@ func (a *Agent) UnregisterHandler(id string) error {
 	return err
 }
 
-func (a Agent) reregisterHandlers() error {
+func (a *Agent) reregisterHandlers() error {
 	a.logger.Warn("reregistering handlers")
 	for _, handler := range a.handlers {
 
@@ -343,6 +601,23 @@ const dispatchSleep = 100 * time.Millisecond
 
 var errWorkCompleted = errors.New("work completed")
 
+// ErrInvocationCancelled is the cause of a HandlerContext the agent cancelled,
+// because the invocation timed out, was cancelled or the agent is shutting
+// down.  Handlers can check for it with context.Cause.
+var ErrInvocationCancelled = errors.New("invocation cancelled")
+
+type invocationCancelledError struct {
+	reason pb.CancelReason
+}
+
+func (e *invocationCancelledError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvocationCancelled, e.reason)
+}
+
+func (e *invocationCancelledError) Is(target error) bool {
+	return target == ErrInvocationCancelled
+}
+
 func (a *Agent) processDispatchStream(ctx context.Context, stream grpc.BidiStreamingClient[pb.DispatchRequest, pb.DispatchMessage], runningHandlers *sync.WaitGroup) error {
 	defer stream.CloseSend()
 	for {
@@ -377,12 +652,23 @@ func (a *Agent) processDispatchStream(ctx context.Context, stream grpc.BidiStrea
 				timeoutCtx, cancel = context.WithTimeout(context.Background(), time.Millisecond*time.Duration(invoke.TimeoutMs))
 			}
 
+			invokeCtx, cancelInvoke := context.WithCancelCause(timeoutCtx)
+			a.setRunning(invoke.InvocationId, cancelInvoke)
+
 			runningHandlers.Add(1)
 			go func() {
 				defer runningHandlers.Done()
 				defer cancel()
-				a.invokeHandler(timeoutCtx, invoke)
+				defer a.setRunning(invoke.InvocationId, nil)
+				a.invokeHandler(invokeCtx, invoke)
 			}()
+		case pb.DispatchMessageType_DISPATCH_MESSAGE_CANCEL:
+			cancelMsg := req.GetCancel()
+			if cancelMsg == nil {
+				a.logger.Error("cancel message is nil")
+				continue
+			}
+			a.cancelRunning(cancelMsg)
 		case pb.DispatchMessageType_DISPATCH_MESSAGE_WORK_COMPLETED:
 			a.logger.Info("work completed, shutting down")
 			return errWorkCompleted
@@ -392,6 +678,35 @@ func (a *Agent) processDispatchStream(ctx context.Context, stream grpc.BidiStrea
 	}
 }
 
+func (a *Agent) setRunning(invocationId string, cancel context.CancelCauseFunc) {
+	a.runningLock.Lock()
+	defer a.runningLock.Unlock()
+	if cancel == nil {
+		if current, ok := a.running[invocationId]; ok {
+			current(nil)
+			delete(a.running, invocationId)
+		}
+		return
+	}
+	a.running[invocationId] = cancel
+}
+
+// cancelRunning cancels the HandlerContext of an invocation the agent has
+// told us to stop.
+func (a *Agent) cancelRunning(msg *pb.DispatchCancel) {
+	a.runningLock.Lock()
+	cancel, ok := a.running[msg.InvocationId]
+	a.runningLock.Unlock()
+	if !ok {
+		return
+	}
+	a.logger.Info("cancelling invocation",
+		zap.String("invocation-id", msg.InvocationId),
+		zap.String("reason", msg.Reason.String()),
+	)
+	cancel(&invocationCancelledError{reason: msg.Reason})
+}
+
 func (a *Agent) invokeHandler(ctx context.Context, invoke *pb.DispatchHandlerInvoke) {
 	handlerInfo, ok := a.registeredHandlers[invoke.HandlerId]
 	if !ok {
@@ -436,7 +751,12 @@ func (a *Agent) invokeHandler(ctx context.Context, invoke *pb.DispatchHandlerInv
 	select {
 	case <-done:
 	case <-ctx.Done():
-		a.setReportError(report, "timeout", nil)
+		code := "timeout"
+		var cancelled *invocationCancelledError
+		if errors.As(context.Cause(ctx), &cancelled) && cancelled.reason != pb.CancelReason_CANCEL_REASON_TIMEOUT {
+			code = "cancelled"
+		}
+		a.setReportError(report, code, nil)
 	}
 
 	if report.GetError() == nil {
@@ -478,6 +798,19 @@ func (a *Agent) executeHandlerWithRecover(handler *handlerInfo, ctx HandlerConte
 	case InvocableHandler:
 		h := handler.handler.(InvocableHandler)
 		result, err = h(ctx)
+	case WebhookHandler:
+		h := handler.handler.(WebhookHandler)
+		var response *pb.WebhookResponse
+		response, err = h(ctx)
+		if err == nil && response != nil {
+			if response.StatusCode == 0 {
+				// the agent only reads a result with a status code as a response
+				response.StatusCode = http.StatusOK
+			}
+			var body []byte
+			body, err = protojson.Marshal(response)
+			result = string(body)
+		}
 	default:
 		err = fmt.Errorf("unknown handler type: %T", handler.handler)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,7 +12,9 @@ import (
 	"github.com/cortexapps/axon-go/mock_axon"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func TestRegisterUnregisterHandler(t *testing.T) {
@@ -121,6 +124,66 @@ func TestInvokeHandlerWithTimeoutTriggered(t *testing.T) {
 
 }
 
+func TestInvokeHandlerCancelled(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	agent, mock := createAgent(controller)
+
+	cause := make(chan error, 1)
+	theHandler := func(ctx HandlerContext) error {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return nil
+	}
+
+	id := "12345"
+	mock.agentStub.EXPECT().RegisterHandler(gomock.Any(), gomock.Any()).Return(&pb.RegisterHandlerResponse{Id: id}, nil)
+	_, err := agent.RegisterHandler(theHandler, WithInvokeOption(pb.HandlerInvokeType_INVOKE, ""))
+	require.NoError(t, err)
+
+	reported := make(chan *pb.ReportInvocationRequest, 1)
+	mock.agentStub.EXPECT().ReportInvocation(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *pb.ReportInvocationRequest, opts ...grpc.CallOption) (*pb.ReportInvocationResponse, error) {
+			reported <- req
+			return &pb.ReportInvocationResponse{}, nil
+		})
+
+	stream := newBidiClient(
+		&pb.DispatchMessage{
+			Type: pb.DispatchMessageType_DISPATCH_MESSAGE_INVOKE,
+			Message: &pb.DispatchMessage_Invoke{
+				Invoke: &pb.DispatchHandlerInvoke{
+					InvocationId: "invocation1",
+					HandlerId:    id,
+					HandlerName:  "func1",
+					Reason:       pb.HandlerInvokeType_INVOKE,
+				},
+			},
+		},
+		&pb.DispatchMessage{
+			Type: pb.DispatchMessageType_DISPATCH_MESSAGE_CANCEL,
+			Message: &pb.DispatchMessage_Cancel{
+				Cancel: &pb.DispatchCancel{
+					InvocationId: "invocation1",
+					Reason:       pb.CancelReason_CANCEL_REASON_REQUESTED,
+				},
+			},
+		},
+		&pb.DispatchMessage{
+			Type: pb.DispatchMessageType_DISPATCH_MESSAGE_WORK_COMPLETED,
+		},
+	)
+
+	running := sync.WaitGroup{}
+	err = agent.processDispatchStream(context.Background(), stream, &running)
+	require.ErrorIs(t, err, errWorkCompleted)
+	running.Wait()
+
+	require.Equal(t, "cancelled", (<-reported).GetError().GetCode())
+	require.ErrorIs(t, <-cause, ErrInvocationCancelled)
+	require.Empty(t, agent.running)
+}
+
 func TestInvokeHandlerWithPanic(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -221,6 +284,74 @@ func TestInvokeHandlerApiCallWithError(t *testing.T) {
 
 }
 
+func TestInvokeWebhookHandler(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	theHandler := func(ctx HandlerContext) (*pb.WebhookResponse, error) {
+		return &pb.WebhookResponse{
+			StatusCode: 201,
+			Headers:    map[string]string{"X-Challenge": "abc"},
+			Body:       "abc",
+		}, nil
+	}
+
+	executeHandlerHelper(t, controller, theHandler, 1000, func(req *pb.ReportInvocationRequest) {
+		require.Nil(t, req.GetError())
+		response := &pb.WebhookResponse{}
+		require.NoError(t, protojson.Unmarshal([]byte(req.GetResult().Value), response))
+		require.Equal(t, int32(201), response.StatusCode)
+		require.Equal(t, "abc", response.Headers["X-Challenge"])
+		require.Equal(t, "abc", response.Body)
+	})
+}
+
+func TestInvokeWebhookHandler_DefaultStatus(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	theHandler := func(ctx HandlerContext) (*pb.WebhookResponse, error) {
+		return &pb.WebhookResponse{Body: "abc"}, nil
+	}
+
+	executeHandlerHelper(t, controller, theHandler, 1000, func(req *pb.ReportInvocationRequest) {
+		response := &pb.WebhookResponse{}
+		require.NoError(t, protojson.Unmarshal([]byte(req.GetResult().Value), response))
+		require.Equal(t, int32(200), response.StatusCode)
+		require.Equal(t, "abc", response.Body)
+	})
+}
+
+func TestWebhookRequestFrom(t *testing.T) {
+	invoke := &pb.DispatchHandlerInvoke{
+		HandlerName: "func1",
+		Reason:      pb.HandlerInvokeType_WEBHOOK,
+		Args: map[string]string{
+			"body":         "payload",
+			"content-type": "application/json",
+			"url":          "/webhook/my-webhook/repos?ref=main",
+			"method":       "POST",
+			"path":         "repos",
+			"remote-addr":  "10.0.0.1:1234",
+			"headers":      `{"X-Github-Event":["push"]}`,
+			"query":        `{"ref":["main"]}`,
+		},
+	}
+	ctx := NewHandlerContext(invoke, context.Background(), nil, zap.NewNop())
+
+	request := WebhookRequestFrom(ctx)
+	require.NotNil(t, request)
+	require.Equal(t, "POST", request.Method)
+	require.Equal(t, "repos", request.Path)
+	require.Equal(t, "10.0.0.1:1234", request.RemoteAddr)
+	require.Equal(t, "push", request.Headers.Get("X-GitHub-Event"))
+	require.Equal(t, "main", request.Query.Get("ref"))
+	require.Equal(t, "payload", request.Body)
+
+	invoke.Reason = pb.HandlerInvokeType_RUN_NOW
+	require.Nil(t, WebhookRequestFrom(NewHandlerContext(invoke, context.Background(), nil, zap.NewNop())))
+}
+
 //
 // Helpers
 //
@@ -286,6 +417,8 @@ func executeHandlerHelper(t *testing.T, controller *gomock.Controller, handler a
 		h, err = agent.RegisterHandler(hFunc, options...)
 	} else if ihFunc, ok := handler.(InvocableHandler); ok {
 		h, err = agent.RegisterInvocableHandler(ihFunc, options...)
+	} else if whFunc, ok := handler.(WebhookHandler); ok {
+		h, err = agent.RegisterWebhookHandler(whFunc, "my-webhook", 0, options...)
 	} else {
 		panic("unexpected handler type: " + fmt.Sprintf("%T", handler))
 	}
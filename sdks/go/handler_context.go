@@ -2,6 +2,9 @@ package axon
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
 
 	pb "github.com/cortexapps/axon-go/.generated/proto/github.com/cortexapps/axon"
 	"go.uber.org/zap"
@@ -11,6 +14,7 @@ type handlerContextKey string
 
 const apiKey handlerContextKey = "api"
 const logKey handlerContextKey = "log"
+const reasonKey handlerContextKey = "reason"
 
 type HandlerContext interface {
 	context.Context
@@ -20,6 +24,20 @@ type HandlerContext interface {
 	Logger() *zap.Logger
 }
 
+// WebhookRequest is the HTTP request that triggered a webhook handler.  Headers the
+// agent is configured to redact have the value "[REDACTED]".
+type WebhookRequest struct {
+	Method string
+	URL    string
+	// Path is the part of the request path after /webhook/{id}/
+	Path        string
+	RemoteAddr  string
+	ContentType string
+	Headers     http.Header
+	Query       url.Values
+	Body        string
+}
+
 type handlerContext struct {
 	context.Context
 	args map[string]string
@@ -49,6 +67,36 @@ func (h *handlerContext) Logger() *zap.Logger {
 	return h.Value(logKey).(*zap.Logger)
 }
 
+// WebhookRequestFrom returns the request that triggered a webhook invocation, or
+// nil for any other invocation.
+func WebhookRequestFrom(ctx HandlerContext) *WebhookRequest {
+	if reason, _ := ctx.Value(reasonKey).(pb.HandlerInvokeType); reason != pb.HandlerInvokeType_WEBHOOK {
+		return nil
+	}
+	args := ctx.Args()
+	request := &WebhookRequest{
+		Method:      args["method"],
+		URL:         args["url"],
+		Path:        args["path"],
+		RemoteAddr:  args["remote-addr"],
+		ContentType: args["content-type"],
+		Headers:     http.Header{},
+		Query:       url.Values{},
+		Body:        args["body"],
+	}
+	if headers := args["headers"]; headers != "" {
+		if err := json.Unmarshal([]byte(headers), &request.Headers); err != nil {
+			ctx.Logger().Warn("failed to parse webhook headers", zap.Error(err))
+		}
+	}
+	if query := args["query"]; query != "" {
+		if err := json.Unmarshal([]byte(query), &request.Query); err != nil {
+			ctx.Logger().Warn("failed to parse webhook query", zap.Error(err))
+		}
+	}
+	return request
+}
+
 func NewHandlerContext(invoke *pb.DispatchHandlerInvoke, ctx context.Context, api pb.CortexApiClient, logger *zap.Logger) HandlerContext {
 
 	name := invoke.HandlerName
@@ -56,6 +104,7 @@ func NewHandlerContext(invoke *pb.DispatchHandlerInvoke, ctx context.Context, ap
 
 	ctx = context.WithValue(ctx, logKey, logger)
 	ctx = context.WithValue(ctx, apiKey, api)
+	ctx = context.WithValue(ctx, reasonKey, invoke.Reason)
 
 	return &handlerContext{
 		Context: ctx,
@@ -86,6 +86,26 @@ func (m *MockAxonAgentClient) EXPECT() *MockAxonAgentClientMockRecorder {
 	return m.recorder
 }
 
+// CancelInvocation mocks base method.
+func (m *MockAxonAgentClient) CancelInvocation(ctx context.Context, in *axon.CancelInvocationRequest, opts ...grpc.CallOption) (*axon.CancelInvocationResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelInvocation", varargs...)
+	ret0, _ := ret[0].(*axon.CancelInvocationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelInvocation indicates an expected call of CancelInvocation.
+func (mr *MockAxonAgentClientMockRecorder) CancelInvocation(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelInvocation", reflect.TypeOf((*MockAxonAgentClient)(nil).CancelInvocation), varargs...)
+}
+
 // Dispatch mocks base method.
 func (m *MockAxonAgentClient) Dispatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[axon.DispatchRequest, axon.DispatchMessage], error) {
 	m.ctrl.T.Helper()
@@ -146,6 +166,46 @@ func (mr *MockAxonAgentClientMockRecorder) ListHandlers(ctx, in any, opts ...any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHandlers", reflect.TypeOf((*MockAxonAgentClient)(nil).ListHandlers), varargs...)
 }
 
+// ListInvocations mocks base method.
+func (m *MockAxonAgentClient) ListInvocations(ctx context.Context, in *axon.ListInvocationsRequest, opts ...grpc.CallOption) (*axon.ListInvocationsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListInvocations", varargs...)
+	ret0, _ := ret[0].(*axon.ListInvocationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvocations indicates an expected call of ListInvocations.
+func (mr *MockAxonAgentClientMockRecorder) ListInvocations(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvocations", reflect.TypeOf((*MockAxonAgentClient)(nil).ListInvocations), varargs...)
+}
+
+// PauseHandler mocks base method.
+func (m *MockAxonAgentClient) PauseHandler(ctx context.Context, in *axon.PauseHandlerRequest, opts ...grpc.CallOption) (*axon.PauseHandlerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PauseHandler", varargs...)
+	ret0, _ := ret[0].(*axon.PauseHandlerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseHandler indicates an expected call of PauseHandler.
+func (mr *MockAxonAgentClientMockRecorder) PauseHandler(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseHandler", reflect.TypeOf((*MockAxonAgentClient)(nil).PauseHandler), varargs...)
+}
+
 // RegisterHandler mocks base method.
 func (m *MockAxonAgentClient) RegisterHandler(ctx context.Context, in *axon.RegisterHandlerRequest, opts ...grpc.CallOption) (*axon.RegisterHandlerResponse, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +246,46 @@ func (mr *MockAxonAgentClientMockRecorder) ReportInvocation(ctx, in any, opts ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportInvocation", reflect.TypeOf((*MockAxonAgentClient)(nil).ReportInvocation), varargs...)
 }
 
+// ResumeHandler mocks base method.
+func (m *MockAxonAgentClient) ResumeHandler(ctx context.Context, in *axon.ResumeHandlerRequest, opts ...grpc.CallOption) (*axon.ResumeHandlerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResumeHandler", varargs...)
+	ret0, _ := ret[0].(*axon.ResumeHandlerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeHandler indicates an expected call of ResumeHandler.
+func (mr *MockAxonAgentClientMockRecorder) ResumeHandler(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeHandler", reflect.TypeOf((*MockAxonAgentClient)(nil).ResumeHandler), varargs...)
+}
+
+// TriggerHandler mocks base method.
+func (m *MockAxonAgentClient) TriggerHandler(ctx context.Context, in *axon.TriggerHandlerRequest, opts ...grpc.CallOption) (*axon.TriggerHandlerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TriggerHandler", varargs...)
+	ret0, _ := ret[0].(*axon.TriggerHandlerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TriggerHandler indicates an expected call of TriggerHandler.
+func (mr *MockAxonAgentClientMockRecorder) TriggerHandler(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TriggerHandler", reflect.TypeOf((*MockAxonAgentClient)(nil).TriggerHandler), varargs...)
+}
+
 // UnregisterHandler mocks base method.
 func (m *MockAxonAgentClient) UnregisterHandler(ctx context.Context, in *axon.UnregisterHandlerRequest, opts ...grpc.CallOption) (*axon.UnregisterHandlerResponse, error) {
 	m.ctrl.T.Helper()
@@ -205,3 +305,23 @@ func (mr *MockAxonAgentClientMockRecorder) UnregisterHandler(ctx, in any, opts .
 	varargs := append([]any{ctx, in}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnregisterHandler", reflect.TypeOf((*MockAxonAgentClient)(nil).UnregisterHandler), varargs...)
 }
+
+// WatchHandlerExecutions mocks base method.
+func (m *MockAxonAgentClient) WatchHandlerExecutions(ctx context.Context, in *axon.WatchHandlerExecutionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[axon.HandlerExecutionEvent], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WatchHandlerExecutions", varargs...)
+	ret0, _ := ret[0].(grpc.ServerStreamingClient[axon.HandlerExecutionEvent])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchHandlerExecutions indicates an expected call of WatchHandlerExecutions.
+func (mr *MockAxonAgentClientMockRecorder) WatchHandlerExecutions(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchHandlerExecutions", reflect.TypeOf((*MockAxonAgentClient)(nil).WatchHandlerExecutions), varargs...)
+}
@@ -3,6 +3,7 @@ package axon
 import (
 	"time"
 
+	pb "github.com/cortexapps/axon-go/.generated/proto/github.com/cortexapps/axon"
 	"github.com/cortexapps/axon-go/version"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -17,6 +18,7 @@ type agentOptions struct {
 	loggerConfig zap.Config
 	sleepOnError time.Duration
 	version      string
+	workerPool   *pb.WorkerPool
 }
 
 func defaultAgentOptions() *agentOptions {
@@ -54,3 +56,16 @@ func WithSleepOnError(duration time.Duration) Option {
 		a.sleepOnError = duration
 	}
 }
+
+// WithWorkerPool joins the agent to the named pool of clients registering the
+// same handlers, so that each invocation goes to one of them, chosen by
+// strategy.  Invocations sent to a client that disconnects before reporting
+// them are sent to another.
+func WithWorkerPool(name string, strategy pb.WorkerPoolStrategy) Option {
+	return func(a *agentOptions) {
+		a.workerPool = &pb.WorkerPool{
+			Name:     name,
+			Strategy: strategy,
+		}
+	}
+}